@@ -6,12 +6,16 @@ package main
 
 import (
 	"flag"
-	"github.com/omec-project/upf-epc/pfcpiface"
+	"strings"
+
+	"github.com/ardzoht/omec-upf/pfcpiface"
 	log "github.com/sirupsen/logrus"
 )
 
 var (
 	configPath = flag.String("config", "upf.json", "path to upf config")
+	datapath   = flag.String("datapath", "",
+		"datapath to use, overrides the config ("+strings.Join(pfcpiface.RegisteredDatapaths(), ", ")+")")
 )
 
 func init() {
@@ -32,11 +36,22 @@ func main() {
 		log.Fatalln("Error reading conf file:", err)
 	}
 
-	log.SetLevel(conf.LogLevel)
+	if level, err := log.ParseLevel(conf.LogLevel.String()); err == nil {
+		log.SetLevel(level)
+	}
+
+	if *datapath != "" {
+		conf.Datapath = *datapath
+	}
 
 	log.Infof("%+v", conf)
 
-	pfcpi := pfcpiface.NewPFCPIface(conf)
+	dp, err := pfcpiface.NewDatapath(conf.Datapath, &conf)
+	if err != nil {
+		log.Fatalln("Error creating datapath:", err)
+	}
+
+	pfcpi := pfcpiface.NewPFCPIface(conf, dp)
 
 	// blocking
 	pfcpi.Run()
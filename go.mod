@@ -4,20 +4,22 @@ go 1.16
 
 require (
 	github.com/antoninbas/p4runtime-go-client v0.0.0-20220204221603-49eba9f248c1
+	github.com/ardzoht/omec-upf/pfcpiface v0.0.0
 	github.com/docker/docker v20.10.12+incompatible
 	github.com/docker/go-connections v0.4.0
 	github.com/ettle/strcase v0.1.1
 	github.com/golang/protobuf v1.5.2
-	github.com/omec-project/pfcpsim v0.0.0-20220309084214-9414eb21802a
+	github.com/omec-project/pfcpsim v0.0.0-20220328122841-64474e93876e
 	github.com/omec-project/upf-epc v0.3.0
 	github.com/p4lang/p4runtime v1.3.0
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.2
-	github.com/wmnsk/go-pfcp v0.0.14
-	google.golang.org/grpc v1.44.0
+	github.com/wmnsk/go-pfcp v0.0.15
+	google.golang.org/grpc v1.47.0
 )
 
+replace github.com/ardzoht/omec-upf/pfcpiface => ./pfcpiface
+
 retract (
     [v0.5.0, v0.6.0] // These versions are deprecated
 )
-
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"sync"
+	"time"
+)
+
+// associations tracks the PFCP associations of a PFCPNode by node ID, so that the previous
+// association of a peer is released once the peer associates again from another address.
+type associations struct {
+	mu     sync.Mutex
+	byNode map[string]*PFCPConn
+}
+
+func newAssociations() *associations {
+	return &associations{
+		byNode: make(map[string]*PFCPConn),
+	}
+}
+
+// associate records the association of pConn with nodeID. It returns the PFCPConn of the
+// previous association with nodeID, nil if there is none or it is pConn.
+func (a *associations) associate(nodeID string, pConn *PFCPConn) *PFCPConn {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	previous := a.byNode[nodeID]
+	a.byNode[nodeID] = pConn
+
+	if previous == pConn {
+		return nil
+	}
+
+	return previous
+}
+
+// release forgets the association of pConn with nodeID, unless it was superseded.
+func (a *associations) release(nodeID string, pConn *PFCPConn) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.byNode[nodeID] == pConn {
+		delete(a.byNode, nodeID)
+	}
+}
+
+// handleAssociation cleans up the state left by the previous incarnation of the peer nodeID,
+// once it set up an association with recovery timestamp ts.
+func (pConn *PFCPConn) handleAssociation(nodeID string, ts time.Time) {
+	// Sessions of a restarted peer are stale
+	pConn.handlePeerRecovery(ts)
+
+	previous := pConn.associations.associate(nodeID, pConn)
+	if previous == nil {
+		return
+	}
+
+	grace := pConn.upf.staleSessionGrace
+
+	log.Warnf("Node %v associated from %v, releasing its association with %v in %v",
+		nodeID, pConn.RemoteAddr(), previous.RemoteAddr(), grace)
+
+	// The sessions of the previous association are deleted when it shuts down
+	time.AfterFunc(grace, previous.Shutdown)
+}
+
+// handleStaleSessions deletes the sessions of the previous incarnation of the peer. If a grace
+// period is configured, they are kept until it expires, and the sessions modified by the peer
+// in the meantime are kept for good.
+func (pConn *PFCPConn) handleStaleSessions(sessions []PFCPSession) {
+	grace := pConn.upf.staleSessionGrace
+	if grace == 0 {
+		for _, session := range sessions {
+			pConn.purgeSession(session)
+		}
+
+		return
+	}
+
+	pConn.staleMu.Lock()
+	defer pConn.staleMu.Unlock()
+
+	if pConn.stale == nil {
+		pConn.stale = make(map[uint64]struct{}, len(sessions))
+	}
+
+	for _, session := range sessions {
+		pConn.stale[session.localSEID] = struct{}{}
+	}
+
+	time.AfterFunc(grace, pConn.purgeStaleSessions)
+}
+
+// purgeStaleSessions deletes the stale sessions that the peer did not reconcile.
+func (pConn *PFCPConn) purgeStaleSessions() {
+	pConn.staleMu.Lock()
+	stale := pConn.stale
+	pConn.stale = nil
+	pConn.staleMu.Unlock()
+
+	if len(stale) != 0 {
+		log.Infof("Deleting %v stale sessions of %v", len(stale), pConn.RemoteAddr())
+	}
+
+	for seid := range stale {
+		if session, ok := pConn.store.GetSession(seid); ok {
+			pConn.purgeSession(session)
+		}
+	}
+}
+
+// reconcileSession keeps the session seid, as the peer modified it after it restarted.
+func (pConn *PFCPConn) reconcileSession(seid uint64) {
+	pConn.staleMu.Lock()
+	defer pConn.staleMu.Unlock()
+
+	delete(pConn.stale, seid)
+}
@@ -17,10 +17,12 @@ import (
 
 const (
 	// Default values
-	maxReqRetriesDefault = 5
-	respTimeoutDefault   = 2 * time.Second
-	hbIntervalDefault    = 5 * time.Second
-	readTimeoutDefault   = 15 * time.Second
+	maxReqRetriesDefault  = 5
+	respTimeoutDefault    = 2 * time.Second
+	hbIntervalDefault     = 5 * time.Second
+	ddnIntervalDefault    = 5 * time.Second
+	readTimeoutDefault    = 15 * time.Second
+	sessionWorkersDefault = 8
 )
 
 // Conf : Json conf struct.
@@ -46,6 +48,30 @@ type Conf struct {
 	RespTimeout       string           `json:"resp_timeout"`
 	EnableHBTimer     bool             `json:"enable_hbTimer"`
 	HeartBeatInterval string           `json:"heart_beat_interval"`
+	DDNInterval       string           `json:"ddn_notification_interval"`
+	StaleSessionGrace string           `json:"stale_session_grace_period"`
+	SessionWorkers    uint32           `json:"pfcp_session_workers"`
+	Datapath          string           `json:"datapath"`
+	DataplaneIface    DataplaneInfo    `json:"dataplane"`
+	SessionStore      SessionStoreInfo `json:"session_store"`
+	// NetworkInstances are named network instances, in addition to the one of the access and
+	// core interfaces
+	NetworkInstances []NetworkInstanceInfo `json:"network_instances"`
+}
+
+// NetworkInstanceInfo : network instance settings. Addresses that are not set are read from
+// the interfaces.
+type NetworkInstanceInfo struct {
+	Name        string    `json:"name"`
+	AccessIface IfaceType `json:"access"`
+	CoreIface   IfaceType `json:"core"`
+	AccessIP    string    `json:"access_ip"`
+	AccessIP6   string    `json:"access_ipv6"`
+	CoreIP      string    `json:"core_ip"`
+	CoreIP6     string    `json:"core_ipv6"`
+	// Dnns are the DNNs served by the instance, which the Network Instance IE may hold instead of
+	// its name
+	Dnns []string `json:"dnns"`
 }
 
 // QciQosConfig : Qos configured attributes.
@@ -86,6 +112,53 @@ type CPIfaceInfo struct {
 	Dnn             string   `json:"dnn"`
 	EnableUeIPAlloc bool     `json:"enable_ue_ip_alloc"`
 	UEIPPool        string   `json:"ue_ip_pool"`
+	// UEIPPools are named pools, in addition to the default one of UEIPPool
+	UEIPPools []UEIPPoolInfo `json:"ue_ip_pools"`
+	// UEIPReservations are the UE addresses reserved for subscribers
+	UEIPReservations []UEIPReservationInfo `json:"ue_ip_reservations"`
+	// TEIDPrefix and TEIDPrefixLen restrict the allocated TEIDs to those whose TEIDPrefixLen most
+	// significant bits are TEIDPrefix, to split the TEID space between UPF instances
+	TEIDPrefix    uint32 `json:"teid_prefix"`
+	TEIDPrefixLen uint8  `json:"teid_prefix_len"`
+	// TEIDPools are the TEID pools of the interfaces, e.g. N9, in addition to the ones of the
+	// access and core interfaces
+	TEIDPools []TEIDPoolInfo `json:"teid_pools"`
+}
+
+// TEIDPoolInfo : TEID pool settings.
+type TEIDPoolInfo struct {
+	Name string `json:"name"`
+	// Interface and NetworkInstance are the Source Interface (access or core) and the Network
+	// Instance of the PDRs whose F-TEIDs are allocated from the pool, any Network Instance if empty
+	Interface       string `json:"interface"`
+	NetworkInstance string `json:"network_instance"`
+	// IPv4 and IPv6 are the local addresses of the F-TEIDs
+	IPv4          string `json:"ipv4"`
+	IPv6          string `json:"ipv6"`
+	TEIDPrefix    uint32 `json:"teid_prefix"`
+	TEIDPrefixLen uint8  `json:"teid_prefix_len"`
+}
+
+// UEIPPoolInfo : UE address pool settings.
+type UEIPPoolInfo struct {
+	Name string `json:"name"`
+	// Dnn is the DNN whose UEs are allocated addresses from the pool, empty for the default pool
+	Dnn        string `json:"dnn"`
+	IPv4Subnet string `json:"ipv4_subnet"`
+	// IPv6Subnet is the prefix out of which /64 prefixes are delegated to UEs
+	IPv6Subnet string `json:"ipv6_subnet"`
+}
+
+// UEIPReservationInfo : UE address reservation settings.
+type UEIPReservationInfo struct {
+	// Key identifies the UE, as its SUPI or GPSI in the User ID IE sent by the SMF, e.g.
+	// "imsi-001010000000001" or "msisdn-15551234567", or as a pre-provisioned key
+	Key string `json:"key"`
+	// Pool is the name of the pool of the addresses, empty for the default pool
+	Pool string `json:"pool"`
+	IPv4 string `json:"ipv4"`
+	// IPv6 is a /64 prefix
+	IPv6 string `json:"ipv6"`
 }
 
 // IfaceType : Gateway interface struct.
@@ -93,6 +166,18 @@ type IfaceType struct {
 	IfName string `json:"ifname"`
 }
 
+// DataplaneInfo : DataplaneControl gRPC service settings.
+type DataplaneInfo struct {
+	Address             string `json:"address"`
+	HealthCheckInterval string `json:"health_check_interval"`
+}
+
+// SessionStoreInfo : PFCP session store settings.
+type SessionStoreInfo struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
 // P4rtcInfo : P4 runtime interface settings.
 type P4rtcInfo struct {
 	SliceID             uint8           `json:"slice_id"`
@@ -134,9 +219,29 @@ func validateConf(conf Conf) error {
 	}
 
 	if conf.CPIface.EnableUeIPAlloc {
-		_, _, err := net.ParseCIDR(conf.CPIface.UEIPPool)
-		if err != nil {
-			return ErrInvalidArgumentWithReason("conf.UEIPPool", conf.CPIface.UEIPPool, err.Error())
+		if conf.CPIface.UEIPPool != "" || len(conf.CPIface.UEIPPools) == 0 {
+			_, _, err := net.ParseCIDR(conf.CPIface.UEIPPool)
+			if err != nil {
+				return ErrInvalidArgumentWithReason("conf.UEIPPool", conf.CPIface.UEIPPool, err.Error())
+			}
+		}
+
+		if _, err := newUEIPPools(conf.CPIface); err != nil {
+			return ErrInvalidArgumentWithReason("conf.UEIPPools", conf.CPIface.UEIPPools, err.Error())
+		}
+	}
+
+	if _, _, err := prefixRange(conf.CPIface.TEIDPrefix, conf.CPIface.TEIDPrefixLen); err != nil {
+		return ErrInvalidArgumentWithReason("conf.CPIface.TEIDPrefix", conf.CPIface.TEIDPrefix, err.Error())
+	}
+
+	if _, err := newNetworkInstances(conf.NetworkInstances, false); err != nil {
+		return ErrInvalidArgumentWithReason("conf.NetworkInstances", conf.NetworkInstances, err.Error())
+	}
+
+	for _, poolConf := range conf.CPIface.TEIDPools {
+		if _, err := newTEIDPool(poolConf); err != nil {
+			return ErrInvalidArgumentWithReason("conf.CPIface.TEIDPools", poolConf, err.Error())
 		}
 	}
 
@@ -165,6 +270,33 @@ func validateConf(conf Conf) error {
 		}
 	}
 
+	if d, err := time.ParseDuration(conf.DDNInterval); err != nil || d < 0 {
+		return ErrInvalidArgumentWithReason("conf.DDNInterval", conf.DDNInterval,
+			"invalid duration")
+	}
+
+	if conf.StaleSessionGrace != "" {
+		if d, err := time.ParseDuration(conf.StaleSessionGrace); err != nil || d < 0 {
+			return ErrInvalidArgumentWithReason("conf.StaleSessionGrace", conf.StaleSessionGrace,
+				"invalid duration")
+		}
+	}
+
+	if !isDatapathRegistered(conf.Datapath) {
+		return ErrInvalidArgumentWithReason("conf.Datapath", conf.Datapath, "unknown datapath")
+	}
+
+	switch conf.SessionStore.Type {
+	case "", sessionStoreMemory:
+	case sessionStoreFile:
+		if conf.SessionStore.Path == "" {
+			return ErrInvalidArgumentWithReason("conf.SessionStore.Path", conf.SessionStore.Path,
+				"path is required by the file session store")
+		}
+	default:
+		return ErrInvalidArgumentWithReason("conf.SessionStore.Type", conf.SessionStore.Type, "unknown session store")
+	}
+
 	return nil
 }
 
@@ -211,6 +343,22 @@ func LoadConfigFile(filepath string) (Conf, error) {
 		}
 	}
 
+	if conf.SessionWorkers == 0 {
+		conf.SessionWorkers = sessionWorkersDefault
+	}
+
+	if conf.DDNInterval == "" {
+		conf.DDNInterval = ddnIntervalDefault.String()
+	}
+
+	if conf.Datapath == "" {
+		conf.Datapath = datapathDefault
+	}
+
+	if conf.SessionStore.Type == "" {
+		conf.SessionStore.Type = sessionStoreMemory
+	}
+
 	// Perform basic validation.
 	err = validateConf(conf)
 	if err != nil {
@@ -80,6 +80,203 @@ func TestLoadConfigFile(t *testing.T) {
 		require.Equal(t, conf.LogLevel, zap.InfoLevel)
 	})
 
+	t.Run("empty config uses default datapath", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk"
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		conf, err := LoadConfigFile(confPath)
+		require.NoError(t, err)
+		require.Equal(t, "ebpf", conf.Datapath)
+	})
+
+	t.Run("unknown datapath is rejected", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"datapath": "unknown"
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		_, err := LoadConfigFile(confPath)
+		require.Error(t, err)
+	})
+
+	t.Run("empty config uses default DDN interval", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk"
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		conf, err := LoadConfigFile(confPath)
+		require.NoError(t, err)
+		require.Equal(t, "5s", conf.DDNInterval)
+	})
+
+	t.Run("invalid DDN interval is rejected", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"ddn_notification_interval": "-1s"
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		_, err := LoadConfigFile(confPath)
+		require.Error(t, err)
+	})
+
+	t.Run("named UE IP pools", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"cpiface": {
+				"enable_ue_ip_alloc": true,
+				"ue_ip_pools": [
+					{"name": "internet", "ipv4_subnet": "10.250.0.0/16", "ipv6_subnet": "2001:db8:1::/48"},
+					{"name": "ims", "dnn": "ims", "ipv4_subnet": "10.251.0.0/16"}
+				]
+			}
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		conf, err := LoadConfigFile(confPath)
+		require.NoError(t, err)
+		require.Len(t, conf.CPIface.UEIPPools, 2)
+		require.Equal(t, "ims", conf.CPIface.UEIPPools[1].Dnn)
+	})
+
+	t.Run("UE IP pools serving the same DNN are rejected", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"cpiface": {
+				"enable_ue_ip_alloc": true,
+				"ue_ip_pools": [
+					{"name": "ims", "dnn": "ims", "ipv4_subnet": "10.251.0.0/16"},
+					{"name": "ims2", "dnn": "ims", "ipv4_subnet": "10.252.0.0/16"}
+				]
+			}
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		_, err := LoadConfigFile(confPath)
+		require.Error(t, err)
+	})
+
+	t.Run("TEID prefix longer than its length is rejected", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"cpiface": {
+				"teid_prefix": 16,
+				"teid_prefix_len": 4
+			}
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		_, err := LoadConfigFile(confPath)
+		require.Error(t, err)
+	})
+
+	t.Run("TEID pool without address is rejected", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"cpiface": {
+				"teid_pools": [{"name": "n9", "interface": "core", "network_instance": "n9"}]
+			}
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		_, err := LoadConfigFile(confPath)
+		require.Error(t, err)
+	})
+
+	t.Run("network instances", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"network_instances": [
+				{"name": "enterprise", "core_ip": "192.168.11.1", "dnns": ["corp"]}
+			]
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		conf, err := LoadConfigFile(confPath)
+		require.NoError(t, err)
+		require.Equal(t, []string{"corp"}, conf.NetworkInstances[0].Dnns)
+	})
+
+	t.Run("network instances sharing a DNN are rejected", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"network_instances": [
+				{"name": "enterprise", "core_ip": "192.168.11.1", "dnns": ["corp"]},
+				{"name": "enterprise2", "core_ip": "192.168.12.1", "dnns": ["corp"]}
+			]
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		_, err := LoadConfigFile(confPath)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid stale session grace period is rejected", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"stale_session_grace_period": "soon"
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		_, err := LoadConfigFile(confPath)
+		require.Error(t, err)
+	})
+
+	t.Run("empty config uses in-memory session store", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk"
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		conf, err := LoadConfigFile(confPath)
+		require.NoError(t, err)
+		require.Equal(t, "memory", conf.SessionStore.Type)
+	})
+
+	t.Run("file session store requires a path", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"session_store": {
+				"type": "file"
+			}
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		_, err := LoadConfigFile(confPath)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown session store is rejected", func(t *testing.T) {
+		s := `{
+			"mode": "dpdk",
+			"session_store": {
+				"type": "redis"
+			}
+		}`
+		confPath := t.TempDir() + "/conf.json"
+		mustWriteStringToDisk(s, confPath)
+
+		_, err := LoadConfigFile(confPath)
+		require.Error(t, err)
+	})
+
 	t.Run("all sample configs must be valid", func(t *testing.T) {
 		paths := []string{
 			"../conf/upf.json",
@@ -15,7 +15,7 @@ import (
 	"github.com/wmnsk/go-pfcp/ie"
 	"go.uber.org/zap"
 
-	"github.com/omec-project/upf-epc/pfcpiface/metrics"
+	"github.com/ardzoht/omec-upf/pfcpiface/metrics"
 )
 
 const (
@@ -50,10 +50,30 @@ type PFCPConn struct {
 	ts         recoveryTS
 	seqNum     sequenceNumber
 	rng        *rand.Rand
+	rngMu      sync.Mutex
 	maxRetries int
 	appPFDs    map[string]appPFD
 
+	// store is the partition of the sessions of the PFCPNode established by the peer
 	store SessionsStore
+	// seids is the range of the local SEIDs allocated to the sessions of the peer
+	seids seidRange
+	// sessionOwners, associations and seidRanges are shared by all PFCPConns of the PFCPNode
+	sessionOwners *sessionOwners
+	associations  *associations
+	seidRanges    *seidRanges
+	// stale are the local SEIDs of the sessions of the previous incarnation of the peer, kept
+	// for a grace period
+	stale   map[uint64]struct{}
+	staleMu sync.Mutex
+	// usage tracks the measurements of the URRs of the sessions
+	usage *usageTracker
+	// responses answers the requests retransmitted by the peer
+	responses *responseCache
+	// outbound queues the messages to the peer for the sender goroutine
+	outbound *outbound
+	// dispatcher shards the messages of the peer to workers, nil to handle them inline
+	dispatcher *dispatcher
 
 	nodeID nodeID
 	upf    *Upf
@@ -61,6 +81,8 @@ type PFCPConn struct {
 	done     chan<- string
 	shutdown chan struct{}
 
+	shutdownOnce sync.Once
+
 	metrics.InstrumentPFCP
 
 	hbReset     chan struct{}
@@ -120,18 +142,46 @@ func (node *PFCPNode) NewPFCPConn(lAddr, rAddr string, buf []byte) *PFCPConn {
 		local: time.Now(),
 	}
 
-	// TODO: Get SEID range from PFCPNode for this PFCPConn
 	log.Info("Created PFCPConn from: ", conn.LocalAddr(), "to: ", conn.RemoteAddr())
 
 	rng := rand.New(rand.NewSource(time.Now().UnixNano())) // #nosec G404
 
+	seids, err := node.seidRanges.allocate()
+	if err != nil {
+		log.Errorf("No SEID range left for %v: %v", rAddr, err)
+
+		if conn != nil {
+			_ = conn.Close()
+		}
+
+		return nil
+	}
+
+	store, err := node.upf.newSessionsStore(node.sessions, peerFromAddr(rAddr))
+	if err != nil {
+		log.Errorf("Failed to open session store for %v: %v", rAddr, err)
+		node.seidRanges.release(seids)
+
+		if conn != nil {
+			_ = conn.Close()
+		}
+
+		return nil
+	}
+
 	var p = &PFCPConn{
 		ctx:            node.ctx,
 		Conn:           conn,
 		ts:             ts,
 		rng:            rng,
 		maxRetries:     100,
-		store:          NewInMemoryStore(),
+		store:          store,
+		seids:          seids,
+		sessionOwners:  node.sessionOwners,
+		associations:   node.associations,
+		seidRanges:     node.seidRanges,
+		responses:      newResponseCache(node.upf.responseCacheTTL()),
+		outbound:       newOutbound(),
 		upf:            node.upf,
 		done:           node.pConnDone,
 		shutdown:       make(chan struct{}),
@@ -140,7 +190,17 @@ func (node *PFCPNode) NewPFCPConn(lAddr, rAddr string, buf []byte) *PFCPConn {
 		hbCtxCancel:    nil,
 	}
 
+	p.usage = newUsageTracker(p.handleUsageTimer)
+
+	go p.sendLoop()
+
+	if node.upf.sessionWorkers > 0 {
+		p.dispatcher = newDispatcher(node.upf.sessionWorkers)
+		p.startWorkers()
+	}
+
 	p.setLocalNodeID(node.upf.NodeID)
+	p.claimSessions()
 
 	if buf != nil {
 		// TODO: Check if the first msg is Association Setup Request
@@ -211,12 +271,10 @@ func (pConn *PFCPConn) Serve() {
 			}
 
 			buf := append([]byte{}, recvBuf[:n]...)
-			pConn.HandlePFCPMsg(buf)
+			pConn.dispatch(buf)
 		}
 	}(connTimeout)
 
-	// TODO: Sender goroutine
-
 	for {
 		select {
 		case <-connTimeout:
@@ -232,24 +290,53 @@ func (pConn *PFCPConn) Serve() {
 	}
 }
 
-// Shutdown stops connection backing PFCPConn.
+// Shutdown stops connection backing PFCPConn, releasing its association and its sessions. It is
+// safe to call it more than once.
 func (pConn *PFCPConn) Shutdown() {
+	pConn.shutdownOnce.Do(pConn.stop)
+}
+
+func (pConn *PFCPConn) stop() {
 	close(pConn.shutdown)
+	pConn.associations.release(pConn.nodeID.remote, pConn)
+
+	// Sessions are purged once the workers are done with the messages they are handling, so
+	// that none is established after the purge.
+	if pConn.dispatcher != nil {
+		pConn.dispatcher.stop()
+	}
 
 	if pConn.hbCtxCancel != nil {
 		pConn.hbCtxCancel()
 		pConn.hbCtxCancel = nil
 	}
 
-	// Cleanup all sessions in this conn
-	for _, sess := range pConn.store.GetAllSessions() {
-		pConn.upf.SendMsgToUPF(UpfMsgTypeDel, sess, PacketForwardingRules{})
-		pConn.RemoveSession(sess)
+	// Cleanup all sessions in this conn, unless the agent is stopping and they are persisted:
+	// they are then recovered once it restarts.
+	if pConn.ctx.Err() != nil && pConn.upf.persistentSessions() {
+		log.Info("Keeping persisted sessions of ", pConn.RemoteAddr().String())
+
+		for _, sess := range pConn.store.GetAllSessions() {
+			pConn.sessionOwners.release(sess.localSEID, pConn)
+		}
+	} else {
+		for _, sess := range pConn.store.GetAllSessions() {
+			pConn.purgeSession(sess)
+		}
 	}
 
+	pConn.usage.stop()
+	closeSessionsStore(pConn.store)
+	pConn.seidRanges.release(pConn.seids)
+
 	rAddr := pConn.RemoteAddr().String()
 	pConn.done <- rAddr
 
+	// The queued messages are flushed before the socket is closed
+	if pConn.outbound != nil {
+		<-pConn.outbound.done
+	}
+
 	err := pConn.Close()
 	if err != nil {
 		log.Error("Failed to close PFCP connection..")
@@ -4,6 +4,7 @@
 package pfcpiface
 
 import (
+	"context"
 	"net"
 )
 
@@ -13,7 +14,6 @@ const (
 	UpfMsgTypeAdd UpfMsgType = iota
 	UpfMsgTypeMod
 	UpfMsgTypeDel
-	UpfMsgTypeClear
 )
 
 func (u UpfMsgType) String() string {
@@ -23,27 +23,58 @@ func (u UpfMsgType) String() string {
 		return "modify"
 	} else if u == UpfMsgTypeDel {
 		return "delete" //nolint
-	} else if u == UpfMsgTypeClear {
-		return "clear"
 	} else {
 		return "unknown"
 	}
 }
 
+// DatapathState is the liveness of the datapath as seen by the PFCP agent.
+type DatapathState int
+
+const (
+	DatapathStateUnknown DatapathState = iota
+	DatapathStateUp
+	DatapathStateDown
+)
+
+func (s DatapathState) String() string {
+	switch s {
+	case DatapathStateUp:
+		return "up"
+	case DatapathStateDown:
+		return "down"
+	default:
+		return "unknown"
+	}
+}
+
 type Datapath interface {
 	/* Close any pending sessions */
 	Exit()
 	/* setup internal parameters and channel with datapath */
+	// Datapaths that buffer downlink packets report them with u.NotifyDownlinkData, and the
+	// counters of URRs with u.ReportUsage.
 	SetUpfInfo(u *Upf, conf *Conf)
 	/* set up slice info */
 	AddSliceInfo(sliceInfo *SliceInfo) error
 	/* write endMarker to datapath */
 	SendEndMarkers(endMarkerList *[]EndMarker) error
-	/* write pdr/far/qer to datapath */
-	// "master" function to send create/update/delete messages to UPF.
-	// "new" PacketForwardingRules are only used for update messages to UPF.
-	// TODO: we should have better CRUD API, with a single function per message type.
-	SendMsgToUPF(method UpfMsgType, session PFCPSession, new PacketForwardingRules) uint8
+	/* write pdr/far/qer/urr to datapath */
+	// Each function applies a single rule. Rules of a PFCP message should be applied through a
+	// Transaction, which orders them and rolls back on failure.
+	// Errors should be a *DatapathError carrying the PFCP cause, if known.
+	CreatePDR(ctx context.Context, pdr Pdr) error
+	ModifyPDR(ctx context.Context, pdr Pdr) error
+	DeletePDR(ctx context.Context, pdr Pdr) error
+	CreateFAR(ctx context.Context, far Far) error
+	ModifyFAR(ctx context.Context, far Far) error
+	DeleteFAR(ctx context.Context, far Far) error
+	CreateQER(ctx context.Context, qer Qer) error
+	ModifyQER(ctx context.Context, qer Qer) error
+	DeleteQER(ctx context.Context, qer Qer) error
+	CreateURR(ctx context.Context, urr Urr) error
+	ModifyURR(ctx context.Context, urr Urr) error
+	DeleteURR(ctx context.Context, urr Urr) error
 	/* check of communication channel to datapath is setup */
 	IsConnected(accessIP *net.IP) bool
 }
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"sort"
+	"sync"
+)
+
+// datapathDefault is the datapath used when none is configured.
+const datapathDefault = "ebpf"
+
+// DatapathFactory creates a Datapath from the configuration. A factory is expected to validate
+// the section of Conf it depends on and return an error, instead of failing later in SetUpfInfo.
+type DatapathFactory func(conf *Conf) (Datapath, error)
+
+var datapathRegistry = struct {
+	sync.RWMutex
+	factories map[string]DatapathFactory
+}{
+	factories: make(map[string]DatapathFactory),
+}
+
+// RegisterDatapath makes a Datapath available by name. Built-in datapaths register themselves
+// from init functions; tests and out-of-tree dataplanes can register their own.
+func RegisterDatapath(name string, factory DatapathFactory) error {
+	if name == "" {
+		return ErrInvalidArgumentWithReason("name", name, "datapath name must not be empty")
+	}
+
+	if factory == nil {
+		return ErrInvalidArgumentWithReason("factory", factory, "datapath factory must not be nil")
+	}
+
+	datapathRegistry.Lock()
+	defer datapathRegistry.Unlock()
+
+	if _, ok := datapathRegistry.factories[name]; ok {
+		return ErrOperationFailedWithParam("RegisterDatapath", "name", name)
+	}
+
+	datapathRegistry.factories[name] = factory
+
+	return nil
+}
+
+// mustRegisterDatapath is RegisterDatapath for built-in datapaths, panicking on errors.
+func mustRegisterDatapath(name string, factory DatapathFactory) {
+	if err := RegisterDatapath(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// NewDatapath creates the datapath registered under name. An empty name selects the default.
+func NewDatapath(name string, conf *Conf) (Datapath, error) {
+	if name == "" {
+		name = datapathDefault
+	}
+
+	datapathRegistry.RLock()
+	factory, ok := datapathRegistry.factories[name]
+	datapathRegistry.RUnlock()
+
+	if !ok {
+		return nil, ErrNotFoundWithParam("datapath", "name", name)
+	}
+
+	return factory(conf)
+}
+
+// RegisteredDatapaths returns the names of all registered datapaths, sorted.
+func RegisteredDatapaths() []string {
+	datapathRegistry.RLock()
+	defer datapathRegistry.RUnlock()
+
+	names := make([]string, 0, len(datapathRegistry.factories))
+	for name := range datapathRegistry.factories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func isDatapathRegistered(name string) bool {
+	datapathRegistry.RLock()
+	defer datapathRegistry.RUnlock()
+
+	_, ok := datapathRegistry.factories[name]
+
+	return ok
+}
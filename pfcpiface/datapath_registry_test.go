@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+)
+
+func TestRegisterDatapath(t *testing.T) {
+	fake := NewRecordingDatapath()
+	factory := func(conf *Conf) (Datapath, error) { return fake, nil }
+
+	require.NoError(t, RegisterDatapath("test-register", factory))
+	require.Error(t, RegisterDatapath("test-register", factory), "duplicate name must be rejected")
+	require.Error(t, RegisterDatapath("", factory))
+	require.Error(t, RegisterDatapath("test-nil-factory", nil))
+
+	require.Contains(t, RegisteredDatapaths(), "test-register")
+
+	dp, err := NewDatapath("test-register", &Conf{})
+	require.NoError(t, err)
+	require.Same(t, fake, dp)
+}
+
+func TestNewDatapath(t *testing.T) {
+	tests := []struct {
+		name     string
+		datapath string
+		conf     Conf
+		wantType Datapath
+		wantErr  bool
+	}{
+		{name: "default is ebpf", datapath: "", wantType: &Ebpf{}},
+		{name: "ebpf", datapath: "ebpf",
+			conf:     Conf{DataplaneIface: DataplaneInfo{Address: "localhost:50051", HealthCheckInterval: "1s"}},
+			wantType: &Ebpf{}},
+		{name: "ebpf invalid address", datapath: "ebpf",
+			conf: Conf{DataplaneIface: DataplaneInfo{Address: "localhost"}}, wantErr: true},
+		{name: "ebpf invalid health check interval", datapath: "ebpf",
+			conf: Conf{DataplaneIface: DataplaneInfo{HealthCheckInterval: "often"}}, wantErr: true},
+		{name: "null", datapath: "null", wantType: &NullDatapath{}},
+		{name: "recording", datapath: "recording", wantType: &RecordingDatapath{}},
+		{name: "unknown", datapath: "bess", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp, err := NewDatapath(tt.datapath, &tt.conf)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.IsType(t, tt.wantType, dp)
+		})
+	}
+}
+
+func TestRecordingDatapath(t *testing.T) {
+	r := NewRecordingDatapath()
+	session := newTestSession(1)
+	ctx := context.Background()
+
+	require.True(t, r.IsConnected(nil))
+
+	for _, p := range session.Pdrs {
+		require.NoError(t, r.CreatePDR(ctx, p))
+	}
+
+	require.NoError(t, r.CreateFAR(ctx, session.Fars[0]))
+	require.Error(t, r.CreateFAR(ctx, session.Fars[0]), "duplicate rule must be rejected")
+	require.Error(t, r.ModifyQER(ctx, session.Qers[0]), "missing rule must be rejected")
+
+	r.InjectError(UpfMsgTypeAdd, RuleTypeQER, ie.CauseNoResourcesAvailable)
+
+	err := r.CreateQER(ctx, session.Qers[0])
+	require.Equal(t, ie.CauseNoResourcesAvailable, causeOf(t, err))
+	require.NoError(t, r.CreateQER(ctx, session.Qers[0]), "injected error must only fail once")
+
+	require.NoError(t, r.DeletePDR(ctx, session.Pdrs[0]))
+
+	installed := r.Rules()
+	require.Equal(t, []Pdr{session.Pdrs[1]}, installed.Pdrs)
+	require.Equal(t, []Far{session.Fars[0]}, installed.Fars)
+	require.Equal(t, []Qer{session.Qers[0]}, installed.Qers)
+
+	calls := r.Calls()
+	require.Len(t, calls, 8)
+	require.Equal(t, RecordedCall{Method: UpfMsgTypeAdd, RuleType: RuleTypePDR, SEID: 1, RuleID: 1,
+		Rule: session.Pdrs[0]}, calls[0])
+
+	r.Reset()
+	require.Empty(t, r.Calls())
+}
@@ -0,0 +1,1971 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.0
+// 	protoc        v3.19.4
+// source: dataplane.proto
+
+package dataplane_pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type QosLevel int32
+
+const (
+	QosLevel_QOS_LEVEL_APPLICATION QosLevel = 0
+	QosLevel_QOS_LEVEL_SESSION     QosLevel = 1
+)
+
+// Enum value maps for QosLevel.
+var (
+	QosLevel_name = map[int32]string{
+		0: "QOS_LEVEL_APPLICATION",
+		1: "QOS_LEVEL_SESSION",
+	}
+	QosLevel_value = map[string]int32{
+		"QOS_LEVEL_APPLICATION": 0,
+		"QOS_LEVEL_SESSION":     1,
+	}
+)
+
+func (x QosLevel) Enum() *QosLevel {
+	p := new(QosLevel)
+	*p = x
+	return p
+}
+
+func (x QosLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QosLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_dataplane_proto_enumTypes[0].Descriptor()
+}
+
+func (QosLevel) Type() protoreflect.EnumType {
+	return &file_dataplane_proto_enumTypes[0]
+}
+
+func (x QosLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QosLevel.Descriptor instead.
+func (QosLevel) EnumDescriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{0}
+}
+
+type PortRange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Low  uint32 `protobuf:"varint,1,opt,name=low,proto3" json:"low,omitempty"`
+	High uint32 `protobuf:"varint,2,opt,name=high,proto3" json:"high,omitempty"`
+}
+
+func (x *PortRange) Reset() {
+	*x = PortRange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PortRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortRange) ProtoMessage() {}
+
+func (x *PortRange) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortRange.ProtoReflect.Descriptor instead.
+func (*PortRange) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PortRange) GetLow() uint32 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *PortRange) GetHigh() uint32 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+type ApplicationFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FilterId     uint32     `protobuf:"varint,1,opt,name=filter_id,json=filterId,proto3" json:"filter_id,omitempty"`
+	SrcIp        uint32     `protobuf:"varint,2,opt,name=src_ip,json=srcIp,proto3" json:"src_ip,omitempty"`
+	SrcIpMask    uint32     `protobuf:"varint,3,opt,name=src_ip_mask,json=srcIpMask,proto3" json:"src_ip_mask,omitempty"`
+	DstIp        uint32     `protobuf:"varint,4,opt,name=dst_ip,json=dstIp,proto3" json:"dst_ip,omitempty"`
+	DstIpMask    uint32     `protobuf:"varint,5,opt,name=dst_ip_mask,json=dstIpMask,proto3" json:"dst_ip_mask,omitempty"`
+	SrcPortRange *PortRange `protobuf:"bytes,6,opt,name=src_port_range,json=srcPortRange,proto3" json:"src_port_range,omitempty"`
+	DstPortRange *PortRange `protobuf:"bytes,7,opt,name=dst_port_range,json=dstPortRange,proto3" json:"dst_port_range,omitempty"`
+	Proto        uint32     `protobuf:"varint,8,opt,name=proto,proto3" json:"proto,omitempty"`
+	ProtoMask    uint32     `protobuf:"varint,9,opt,name=proto_mask,json=protoMask,proto3" json:"proto_mask,omitempty"`
+	// IPv6 prefixes matched by IPv6 packets, as 16 bytes addresses. A prefix length of 0 matches
+	// any address.
+	SrcIp6          []byte `protobuf:"bytes,10,opt,name=src_ip6,json=srcIp6,proto3" json:"src_ip6,omitempty"`
+	SrcIp6PrefixLen uint32 `protobuf:"varint,11,opt,name=src_ip6_prefix_len,json=srcIp6PrefixLen,proto3" json:"src_ip6_prefix_len,omitempty"`
+	DstIp6          []byte `protobuf:"bytes,12,opt,name=dst_ip6,json=dstIp6,proto3" json:"dst_ip6,omitempty"`
+	DstIp6PrefixLen uint32 `protobuf:"varint,13,opt,name=dst_ip6_prefix_len,json=dstIp6PrefixLen,proto3" json:"dst_ip6_prefix_len,omitempty"`
+}
+
+func (x *ApplicationFilter) Reset() {
+	*x = ApplicationFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApplicationFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplicationFilter) ProtoMessage() {}
+
+func (x *ApplicationFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplicationFilter.ProtoReflect.Descriptor instead.
+func (*ApplicationFilter) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ApplicationFilter) GetFilterId() uint32 {
+	if x != nil {
+		return x.FilterId
+	}
+	return 0
+}
+
+func (x *ApplicationFilter) GetSrcIp() uint32 {
+	if x != nil {
+		return x.SrcIp
+	}
+	return 0
+}
+
+func (x *ApplicationFilter) GetSrcIpMask() uint32 {
+	if x != nil {
+		return x.SrcIpMask
+	}
+	return 0
+}
+
+func (x *ApplicationFilter) GetDstIp() uint32 {
+	if x != nil {
+		return x.DstIp
+	}
+	return 0
+}
+
+func (x *ApplicationFilter) GetDstIpMask() uint32 {
+	if x != nil {
+		return x.DstIpMask
+	}
+	return 0
+}
+
+func (x *ApplicationFilter) GetSrcPortRange() *PortRange {
+	if x != nil {
+		return x.SrcPortRange
+	}
+	return nil
+}
+
+func (x *ApplicationFilter) GetDstPortRange() *PortRange {
+	if x != nil {
+		return x.DstPortRange
+	}
+	return nil
+}
+
+func (x *ApplicationFilter) GetProto() uint32 {
+	if x != nil {
+		return x.Proto
+	}
+	return 0
+}
+
+func (x *ApplicationFilter) GetProtoMask() uint32 {
+	if x != nil {
+		return x.ProtoMask
+	}
+	return 0
+}
+
+func (x *ApplicationFilter) GetSrcIp6() []byte {
+	if x != nil {
+		return x.SrcIp6
+	}
+	return nil
+}
+
+func (x *ApplicationFilter) GetSrcIp6PrefixLen() uint32 {
+	if x != nil {
+		return x.SrcIp6PrefixLen
+	}
+	return 0
+}
+
+func (x *ApplicationFilter) GetDstIp6() []byte {
+	if x != nil {
+		return x.DstIp6
+	}
+	return nil
+}
+
+func (x *ApplicationFilter) GetDstIp6PrefixLen() uint32 {
+	if x != nil {
+		return x.DstIp6PrefixLen
+	}
+	return 0
+}
+
+type Pdr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PdrId uint32 `protobuf:"varint,1,opt,name=pdr_id,json=pdrId,proto3" json:"pdr_id,omitempty"`
+	// Local SEID of the session owning the rule.
+	Seid              uint64             `protobuf:"varint,2,opt,name=seid,proto3" json:"seid,omitempty"`
+	FseidIp           uint32             `protobuf:"varint,3,opt,name=fseid_ip,json=fseidIp,proto3" json:"fseid_ip,omitempty"`
+	SrcIface          uint32             `protobuf:"varint,4,opt,name=src_iface,json=srcIface,proto3" json:"src_iface,omitempty"`
+	SrcIfaceMask      uint32             `protobuf:"varint,5,opt,name=src_iface_mask,json=srcIfaceMask,proto3" json:"src_iface_mask,omitempty"`
+	TunnelIpv4Dst     uint32             `protobuf:"varint,6,opt,name=tunnel_ipv4_dst,json=tunnelIpv4Dst,proto3" json:"tunnel_ipv4_dst,omitempty"`
+	TunnelIpv4DstMask uint32             `protobuf:"varint,7,opt,name=tunnel_ipv4_dst_mask,json=tunnelIpv4DstMask,proto3" json:"tunnel_ipv4_dst_mask,omitempty"`
+	TunnelTeid        uint32             `protobuf:"varint,8,opt,name=tunnel_teid,json=tunnelTeid,proto3" json:"tunnel_teid,omitempty"`
+	TunnelTeidMask    uint32             `protobuf:"varint,9,opt,name=tunnel_teid_mask,json=tunnelTeidMask,proto3" json:"tunnel_teid_mask,omitempty"`
+	UeAddress         uint32             `protobuf:"varint,10,opt,name=ue_address,json=ueAddress,proto3" json:"ue_address,omitempty"`
+	AppFilter         *ApplicationFilter `protobuf:"bytes,11,opt,name=app_filter,json=appFilter,proto3" json:"app_filter,omitempty"`
+	Precedence        uint32             `protobuf:"varint,12,opt,name=precedence,proto3" json:"precedence,omitempty"`
+	CtrId             uint32             `protobuf:"varint,13,opt,name=ctr_id,json=ctrId,proto3" json:"ctr_id,omitempty"`
+	FarId             uint32             `protobuf:"varint,14,opt,name=far_id,json=farId,proto3" json:"far_id,omitempty"`
+	QerIds            []uint32           `protobuf:"varint,15,rep,packed,name=qer_ids,json=qerIds,proto3" json:"qer_ids,omitempty"`
+	NeedDecap         bool               `protobuf:"varint,16,opt,name=need_decap,json=needDecap,proto3" json:"need_decap,omitempty"`
+	// IPv6 address of the F-TEID and IPv6 prefix of the UE, as 16 bytes addresses. Empty if the
+	// PDR has none.
+	TunnelIpv6Dst       []byte `protobuf:"bytes,17,opt,name=tunnel_ipv6_dst,json=tunnelIpv6Dst,proto3" json:"tunnel_ipv6_dst,omitempty"`
+	UeAddress6          []byte `protobuf:"bytes,18,opt,name=ue_address6,json=ueAddress6,proto3" json:"ue_address6,omitempty"`
+	UeAddress6PrefixLen uint32 `protobuf:"varint,19,opt,name=ue_address6_prefix_len,json=ueAddress6PrefixLen,proto3" json:"ue_address6_prefix_len,omitempty"`
+	// Network instance of the PDI: the name of a network instance of the UPF, or else the
+	// Network Instance IE as is. Empty if the PDR has none.
+	NetworkInstance string `protobuf:"bytes,20,opt,name=network_instance,json=networkInstance,proto3" json:"network_instance,omitempty"`
+}
+
+func (x *Pdr) Reset() {
+	*x = Pdr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Pdr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pdr) ProtoMessage() {}
+
+func (x *Pdr) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pdr.ProtoReflect.Descriptor instead.
+func (*Pdr) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Pdr) GetPdrId() uint32 {
+	if x != nil {
+		return x.PdrId
+	}
+	return 0
+}
+
+func (x *Pdr) GetSeid() uint64 {
+	if x != nil {
+		return x.Seid
+	}
+	return 0
+}
+
+func (x *Pdr) GetFseidIp() uint32 {
+	if x != nil {
+		return x.FseidIp
+	}
+	return 0
+}
+
+func (x *Pdr) GetSrcIface() uint32 {
+	if x != nil {
+		return x.SrcIface
+	}
+	return 0
+}
+
+func (x *Pdr) GetSrcIfaceMask() uint32 {
+	if x != nil {
+		return x.SrcIfaceMask
+	}
+	return 0
+}
+
+func (x *Pdr) GetTunnelIpv4Dst() uint32 {
+	if x != nil {
+		return x.TunnelIpv4Dst
+	}
+	return 0
+}
+
+func (x *Pdr) GetTunnelIpv4DstMask() uint32 {
+	if x != nil {
+		return x.TunnelIpv4DstMask
+	}
+	return 0
+}
+
+func (x *Pdr) GetTunnelTeid() uint32 {
+	if x != nil {
+		return x.TunnelTeid
+	}
+	return 0
+}
+
+func (x *Pdr) GetTunnelTeidMask() uint32 {
+	if x != nil {
+		return x.TunnelTeidMask
+	}
+	return 0
+}
+
+func (x *Pdr) GetUeAddress() uint32 {
+	if x != nil {
+		return x.UeAddress
+	}
+	return 0
+}
+
+func (x *Pdr) GetAppFilter() *ApplicationFilter {
+	if x != nil {
+		return x.AppFilter
+	}
+	return nil
+}
+
+func (x *Pdr) GetPrecedence() uint32 {
+	if x != nil {
+		return x.Precedence
+	}
+	return 0
+}
+
+func (x *Pdr) GetCtrId() uint32 {
+	if x != nil {
+		return x.CtrId
+	}
+	return 0
+}
+
+func (x *Pdr) GetFarId() uint32 {
+	if x != nil {
+		return x.FarId
+	}
+	return 0
+}
+
+func (x *Pdr) GetQerIds() []uint32 {
+	if x != nil {
+		return x.QerIds
+	}
+	return nil
+}
+
+func (x *Pdr) GetNeedDecap() bool {
+	if x != nil {
+		return x.NeedDecap
+	}
+	return false
+}
+
+func (x *Pdr) GetTunnelIpv6Dst() []byte {
+	if x != nil {
+		return x.TunnelIpv6Dst
+	}
+	return nil
+}
+
+func (x *Pdr) GetUeAddress6() []byte {
+	if x != nil {
+		return x.UeAddress6
+	}
+	return nil
+}
+
+func (x *Pdr) GetUeAddress6PrefixLen() uint32 {
+	if x != nil {
+		return x.UeAddress6PrefixLen
+	}
+	return 0
+}
+
+func (x *Pdr) GetNetworkInstance() string {
+	if x != nil {
+		return x.NetworkInstance
+	}
+	return ""
+}
+
+type Far struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FarId         uint32 `protobuf:"varint,1,opt,name=far_id,json=farId,proto3" json:"far_id,omitempty"`
+	Seid          uint64 `protobuf:"varint,2,opt,name=seid,proto3" json:"seid,omitempty"`
+	FseidIp       uint32 `protobuf:"varint,3,opt,name=fseid_ip,json=fseidIp,proto3" json:"fseid_ip,omitempty"`
+	DstIntf       uint32 `protobuf:"varint,4,opt,name=dst_intf,json=dstIntf,proto3" json:"dst_intf,omitempty"`
+	ApplyAction   uint32 `protobuf:"varint,5,opt,name=apply_action,json=applyAction,proto3" json:"apply_action,omitempty"`
+	SendEndMarker bool   `protobuf:"varint,6,opt,name=send_end_marker,json=sendEndMarker,proto3" json:"send_end_marker,omitempty"`
+	TunnelType    uint32 `protobuf:"varint,7,opt,name=tunnel_type,json=tunnelType,proto3" json:"tunnel_type,omitempty"`
+	TunnelIpv4Src uint32 `protobuf:"varint,8,opt,name=tunnel_ipv4_src,json=tunnelIpv4Src,proto3" json:"tunnel_ipv4_src,omitempty"`
+	TunnelIpv4Dst uint32 `protobuf:"varint,9,opt,name=tunnel_ipv4_dst,json=tunnelIpv4Dst,proto3" json:"tunnel_ipv4_dst,omitempty"`
+	TunnelTeid    uint32 `protobuf:"varint,10,opt,name=tunnel_teid,json=tunnelTeid,proto3" json:"tunnel_teid,omitempty"`
+	TunnelPort    uint32 `protobuf:"varint,11,opt,name=tunnel_port,json=tunnelPort,proto3" json:"tunnel_port,omitempty"`
+	// IPv6 tunnel endpoints, as 16 bytes addresses. Empty for an IPv4 tunnel.
+	TunnelIpv6Src []byte `protobuf:"bytes,12,opt,name=tunnel_ipv6_src,json=tunnelIpv6Src,proto3" json:"tunnel_ipv6_src,omitempty"`
+	TunnelIpv6Dst []byte `protobuf:"bytes,13,opt,name=tunnel_ipv6_dst,json=tunnelIpv6Dst,proto3" json:"tunnel_ipv6_dst,omitempty"`
+	// Network instance of the Forwarding Parameters, like the one of a PDR.
+	NetworkInstance string `protobuf:"bytes,14,opt,name=network_instance,json=networkInstance,proto3" json:"network_instance,omitempty"`
+}
+
+func (x *Far) Reset() {
+	*x = Far{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Far) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Far) ProtoMessage() {}
+
+func (x *Far) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Far.ProtoReflect.Descriptor instead.
+func (*Far) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Far) GetFarId() uint32 {
+	if x != nil {
+		return x.FarId
+	}
+	return 0
+}
+
+func (x *Far) GetSeid() uint64 {
+	if x != nil {
+		return x.Seid
+	}
+	return 0
+}
+
+func (x *Far) GetFseidIp() uint32 {
+	if x != nil {
+		return x.FseidIp
+	}
+	return 0
+}
+
+func (x *Far) GetDstIntf() uint32 {
+	if x != nil {
+		return x.DstIntf
+	}
+	return 0
+}
+
+func (x *Far) GetApplyAction() uint32 {
+	if x != nil {
+		return x.ApplyAction
+	}
+	return 0
+}
+
+func (x *Far) GetSendEndMarker() bool {
+	if x != nil {
+		return x.SendEndMarker
+	}
+	return false
+}
+
+func (x *Far) GetTunnelType() uint32 {
+	if x != nil {
+		return x.TunnelType
+	}
+	return 0
+}
+
+func (x *Far) GetTunnelIpv4Src() uint32 {
+	if x != nil {
+		return x.TunnelIpv4Src
+	}
+	return 0
+}
+
+func (x *Far) GetTunnelIpv4Dst() uint32 {
+	if x != nil {
+		return x.TunnelIpv4Dst
+	}
+	return 0
+}
+
+func (x *Far) GetTunnelTeid() uint32 {
+	if x != nil {
+		return x.TunnelTeid
+	}
+	return 0
+}
+
+func (x *Far) GetTunnelPort() uint32 {
+	if x != nil {
+		return x.TunnelPort
+	}
+	return 0
+}
+
+func (x *Far) GetTunnelIpv6Src() []byte {
+	if x != nil {
+		return x.TunnelIpv6Src
+	}
+	return nil
+}
+
+func (x *Far) GetTunnelIpv6Dst() []byte {
+	if x != nil {
+		return x.TunnelIpv6Dst
+	}
+	return nil
+}
+
+func (x *Far) GetNetworkInstance() string {
+	if x != nil {
+		return x.NetworkInstance
+	}
+	return ""
+}
+
+type Qer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	QerId    uint32   `protobuf:"varint,1,opt,name=qer_id,json=qerId,proto3" json:"qer_id,omitempty"`
+	Seid     uint64   `protobuf:"varint,2,opt,name=seid,proto3" json:"seid,omitempty"`
+	FseidIp  uint32   `protobuf:"varint,3,opt,name=fseid_ip,json=fseidIp,proto3" json:"fseid_ip,omitempty"`
+	QosLevel QosLevel `protobuf:"varint,4,opt,name=qos_level,json=qosLevel,proto3,enum=dataplane.v1.QosLevel" json:"qos_level,omitempty"`
+	Qfi      uint32   `protobuf:"varint,5,opt,name=qfi,proto3" json:"qfi,omitempty"`
+	UlStatus uint32   `protobuf:"varint,6,opt,name=ul_status,json=ulStatus,proto3" json:"ul_status,omitempty"`
+	DlStatus uint32   `protobuf:"varint,7,opt,name=dl_status,json=dlStatus,proto3" json:"dl_status,omitempty"`
+	// Bit rates are in kilobits/sec.
+	UlMbr uint64 `protobuf:"varint,8,opt,name=ul_mbr,json=ulMbr,proto3" json:"ul_mbr,omitempty"`
+	DlMbr uint64 `protobuf:"varint,9,opt,name=dl_mbr,json=dlMbr,proto3" json:"dl_mbr,omitempty"`
+	UlGbr uint64 `protobuf:"varint,10,opt,name=ul_gbr,json=ulGbr,proto3" json:"ul_gbr,omitempty"`
+	DlGbr uint64 `protobuf:"varint,11,opt,name=dl_gbr,json=dlGbr,proto3" json:"dl_gbr,omitempty"`
+}
+
+func (x *Qer) Reset() {
+	*x = Qer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Qer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Qer) ProtoMessage() {}
+
+func (x *Qer) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Qer.ProtoReflect.Descriptor instead.
+func (*Qer) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Qer) GetQerId() uint32 {
+	if x != nil {
+		return x.QerId
+	}
+	return 0
+}
+
+func (x *Qer) GetSeid() uint64 {
+	if x != nil {
+		return x.Seid
+	}
+	return 0
+}
+
+func (x *Qer) GetFseidIp() uint32 {
+	if x != nil {
+		return x.FseidIp
+	}
+	return 0
+}
+
+func (x *Qer) GetQosLevel() QosLevel {
+	if x != nil {
+		return x.QosLevel
+	}
+	return QosLevel_QOS_LEVEL_APPLICATION
+}
+
+func (x *Qer) GetQfi() uint32 {
+	if x != nil {
+		return x.Qfi
+	}
+	return 0
+}
+
+func (x *Qer) GetUlStatus() uint32 {
+	if x != nil {
+		return x.UlStatus
+	}
+	return 0
+}
+
+func (x *Qer) GetDlStatus() uint32 {
+	if x != nil {
+		return x.DlStatus
+	}
+	return 0
+}
+
+func (x *Qer) GetUlMbr() uint64 {
+	if x != nil {
+		return x.UlMbr
+	}
+	return 0
+}
+
+func (x *Qer) GetDlMbr() uint64 {
+	if x != nil {
+		return x.DlMbr
+	}
+	return 0
+}
+
+func (x *Qer) GetUlGbr() uint64 {
+	if x != nil {
+		return x.UlGbr
+	}
+	return 0
+}
+
+func (x *Qer) GetDlGbr() uint64 {
+	if x != nil {
+		return x.DlGbr
+	}
+	return 0
+}
+
+type VolumeData struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Flags          uint32 `protobuf:"varint,1,opt,name=flags,proto3" json:"flags,omitempty"`
+	TotalVolume    uint64 `protobuf:"varint,2,opt,name=total_volume,json=totalVolume,proto3" json:"total_volume,omitempty"`
+	UplinkVolume   uint64 `protobuf:"varint,3,opt,name=uplink_volume,json=uplinkVolume,proto3" json:"uplink_volume,omitempty"`
+	DownlinkVolume uint64 `protobuf:"varint,4,opt,name=downlink_volume,json=downlinkVolume,proto3" json:"downlink_volume,omitempty"`
+}
+
+func (x *VolumeData) Reset() {
+	*x = VolumeData{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VolumeData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VolumeData) ProtoMessage() {}
+
+func (x *VolumeData) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VolumeData.ProtoReflect.Descriptor instead.
+func (*VolumeData) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *VolumeData) GetFlags() uint32 {
+	if x != nil {
+		return x.Flags
+	}
+	return 0
+}
+
+func (x *VolumeData) GetTotalVolume() uint64 {
+	if x != nil {
+		return x.TotalVolume
+	}
+	return 0
+}
+
+func (x *VolumeData) GetUplinkVolume() uint64 {
+	if x != nil {
+		return x.UplinkVolume
+	}
+	return 0
+}
+
+func (x *VolumeData) GetDownlinkVolume() uint64 {
+	if x != nil {
+		return x.DownlinkVolume
+	}
+	return 0
+}
+
+type Urr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UrrId             uint32      `protobuf:"varint,1,opt,name=urr_id,json=urrId,proto3" json:"urr_id,omitempty"`
+	Seid              uint64      `protobuf:"varint,2,opt,name=seid,proto3" json:"seid,omitempty"`
+	FseidIp           uint32      `protobuf:"varint,3,opt,name=fseid_ip,json=fseidIp,proto3" json:"fseid_ip,omitempty"`
+	CtrId             uint32      `protobuf:"varint,4,opt,name=ctr_id,json=ctrId,proto3" json:"ctr_id,omitempty"`
+	PdrId             uint32      `protobuf:"varint,5,opt,name=pdr_id,json=pdrId,proto3" json:"pdr_id,omitempty"`
+	MeasurementMethod uint32      `protobuf:"varint,6,opt,name=measurement_method,json=measurementMethod,proto3" json:"measurement_method,omitempty"`
+	ReportOpen        bool        `protobuf:"varint,7,opt,name=report_open,json=reportOpen,proto3" json:"report_open,omitempty"`
+	ReportingTriggers uint32      `protobuf:"varint,8,opt,name=reporting_triggers,json=reportingTriggers,proto3" json:"reporting_triggers,omitempty"`
+	LocalThreshold    uint64      `protobuf:"varint,9,opt,name=local_threshold,json=localThreshold,proto3" json:"local_threshold,omitempty"`
+	VolumeThreshold   *VolumeData `protobuf:"bytes,10,opt,name=volume_threshold,json=volumeThreshold,proto3" json:"volume_threshold,omitempty"`
+	VolumeQuota       *VolumeData `protobuf:"bytes,11,opt,name=volume_quota,json=volumeQuota,proto3" json:"volume_quota,omitempty"`
+}
+
+func (x *Urr) Reset() {
+	*x = Urr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Urr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Urr) ProtoMessage() {}
+
+func (x *Urr) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Urr.ProtoReflect.Descriptor instead.
+func (*Urr) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Urr) GetUrrId() uint32 {
+	if x != nil {
+		return x.UrrId
+	}
+	return 0
+}
+
+func (x *Urr) GetSeid() uint64 {
+	if x != nil {
+		return x.Seid
+	}
+	return 0
+}
+
+func (x *Urr) GetFseidIp() uint32 {
+	if x != nil {
+		return x.FseidIp
+	}
+	return 0
+}
+
+func (x *Urr) GetCtrId() uint32 {
+	if x != nil {
+		return x.CtrId
+	}
+	return 0
+}
+
+func (x *Urr) GetPdrId() uint32 {
+	if x != nil {
+		return x.PdrId
+	}
+	return 0
+}
+
+func (x *Urr) GetMeasurementMethod() uint32 {
+	if x != nil {
+		return x.MeasurementMethod
+	}
+	return 0
+}
+
+func (x *Urr) GetReportOpen() bool {
+	if x != nil {
+		return x.ReportOpen
+	}
+	return false
+}
+
+func (x *Urr) GetReportingTriggers() uint32 {
+	if x != nil {
+		return x.ReportingTriggers
+	}
+	return 0
+}
+
+func (x *Urr) GetLocalThreshold() uint64 {
+	if x != nil {
+		return x.LocalThreshold
+	}
+	return 0
+}
+
+func (x *Urr) GetVolumeThreshold() *VolumeData {
+	if x != nil {
+		return x.VolumeThreshold
+	}
+	return nil
+}
+
+func (x *Urr) GetVolumeQuota() *VolumeData {
+	if x != nil {
+		return x.VolumeQuota
+	}
+	return nil
+}
+
+type PdrRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pdr *Pdr `protobuf:"bytes,1,opt,name=pdr,proto3" json:"pdr,omitempty"`
+}
+
+func (x *PdrRequest) Reset() {
+	*x = PdrRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PdrRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PdrRequest) ProtoMessage() {}
+
+func (x *PdrRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PdrRequest.ProtoReflect.Descriptor instead.
+func (*PdrRequest) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PdrRequest) GetPdr() *Pdr {
+	if x != nil {
+		return x.Pdr
+	}
+	return nil
+}
+
+type FarRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Far *Far `protobuf:"bytes,1,opt,name=far,proto3" json:"far,omitempty"`
+}
+
+func (x *FarRequest) Reset() {
+	*x = FarRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FarRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FarRequest) ProtoMessage() {}
+
+func (x *FarRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FarRequest.ProtoReflect.Descriptor instead.
+func (*FarRequest) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FarRequest) GetFar() *Far {
+	if x != nil {
+		return x.Far
+	}
+	return nil
+}
+
+type QerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Qer *Qer `protobuf:"bytes,1,opt,name=qer,proto3" json:"qer,omitempty"`
+}
+
+func (x *QerRequest) Reset() {
+	*x = QerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QerRequest) ProtoMessage() {}
+
+func (x *QerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QerRequest.ProtoReflect.Descriptor instead.
+func (*QerRequest) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *QerRequest) GetQer() *Qer {
+	if x != nil {
+		return x.Qer
+	}
+	return nil
+}
+
+type UrrRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Urr *Urr `protobuf:"bytes,1,opt,name=urr,proto3" json:"urr,omitempty"`
+}
+
+func (x *UrrRequest) Reset() {
+	*x = UrrRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UrrRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UrrRequest) ProtoMessage() {}
+
+func (x *UrrRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UrrRequest.ProtoReflect.Descriptor instead.
+func (*UrrRequest) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UrrRequest) GetUrr() *Urr {
+	if x != nil {
+		return x.Urr
+	}
+	return nil
+}
+
+type RuleResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RuleResponse) Reset() {
+	*x = RuleResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleResponse) ProtoMessage() {}
+
+func (x *RuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleResponse.ProtoReflect.Descriptor instead.
+func (*RuleResponse) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{11}
+}
+
+// UrrCounters are the counters of a URR, accumulated since the URR was created.
+type UrrCounters struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UrrId uint32 `protobuf:"varint,1,opt,name=urr_id,json=urrId,proto3" json:"urr_id,omitempty"`
+	// Local SEID of the session owning the URR.
+	Seid            uint64 `protobuf:"varint,2,opt,name=seid,proto3" json:"seid,omitempty"`
+	UplinkPackets   uint64 `protobuf:"varint,3,opt,name=uplink_packets,json=uplinkPackets,proto3" json:"uplink_packets,omitempty"`
+	DownlinkPackets uint64 `protobuf:"varint,4,opt,name=downlink_packets,json=downlinkPackets,proto3" json:"downlink_packets,omitempty"`
+	UplinkBytes     uint64 `protobuf:"varint,5,opt,name=uplink_bytes,json=uplinkBytes,proto3" json:"uplink_bytes,omitempty"`
+	DownlinkBytes   uint64 `protobuf:"varint,6,opt,name=downlink_bytes,json=downlinkBytes,proto3" json:"downlink_bytes,omitempty"`
+}
+
+func (x *UrrCounters) Reset() {
+	*x = UrrCounters{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UrrCounters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UrrCounters) ProtoMessage() {}
+
+func (x *UrrCounters) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UrrCounters.ProtoReflect.Descriptor instead.
+func (*UrrCounters) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UrrCounters) GetUrrId() uint32 {
+	if x != nil {
+		return x.UrrId
+	}
+	return 0
+}
+
+func (x *UrrCounters) GetSeid() uint64 {
+	if x != nil {
+		return x.Seid
+	}
+	return 0
+}
+
+func (x *UrrCounters) GetUplinkPackets() uint64 {
+	if x != nil {
+		return x.UplinkPackets
+	}
+	return 0
+}
+
+func (x *UrrCounters) GetDownlinkPackets() uint64 {
+	if x != nil {
+		return x.DownlinkPackets
+	}
+	return 0
+}
+
+func (x *UrrCounters) GetUplinkBytes() uint64 {
+	if x != nil {
+		return x.UplinkBytes
+	}
+	return 0
+}
+
+func (x *UrrCounters) GetDownlinkBytes() uint64 {
+	if x != nil {
+		return x.DownlinkBytes
+	}
+	return 0
+}
+
+type WatchUsageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *WatchUsageRequest) Reset() {
+	*x = WatchUsageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchUsageRequest) ProtoMessage() {}
+
+func (x *WatchUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchUsageRequest.ProtoReflect.Descriptor instead.
+func (*WatchUsageRequest) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{13}
+}
+
+type WatchDownlinkDataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *WatchDownlinkDataRequest) Reset() {
+	*x = WatchDownlinkDataRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchDownlinkDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchDownlinkDataRequest) ProtoMessage() {}
+
+func (x *WatchDownlinkDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchDownlinkDataRequest.ProtoReflect.Descriptor instead.
+func (*WatchDownlinkDataRequest) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{14}
+}
+
+type DownlinkDataEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Local SEID of the session the packets were buffered for.
+	Seid uint64 `protobuf:"varint,1,opt,name=seid,proto3" json:"seid,omitempty"`
+}
+
+func (x *DownlinkDataEvent) Reset() {
+	*x = DownlinkDataEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dataplane_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DownlinkDataEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownlinkDataEvent) ProtoMessage() {}
+
+func (x *DownlinkDataEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_dataplane_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownlinkDataEvent.ProtoReflect.Descriptor instead.
+func (*DownlinkDataEvent) Descriptor() ([]byte, []int) {
+	return file_dataplane_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DownlinkDataEvent) GetSeid() uint64 {
+	if x != nil {
+		return x.Seid
+	}
+	return 0
+}
+
+var File_dataplane_proto protoreflect.FileDescriptor
+
+var file_dataplane_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x22,
+	0x31, 0x0a, 0x09, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x10, 0x0a, 0x03,
+	0x6c, 0x6f, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6c, 0x6f, 0x77, 0x12, 0x12,
+	0x0a, 0x04, 0x68, 0x69, 0x67, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x68, 0x69,
+	0x67, 0x68, 0x22, 0xdd, 0x03, 0x0a, 0x11, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x66, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x73, 0x72, 0x63, 0x5f, 0x69, 0x70, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x73, 0x72, 0x63, 0x49, 0x70, 0x12, 0x1e, 0x0a, 0x0b,
+	0x73, 0x72, 0x63, 0x5f, 0x69, 0x70, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x09, 0x73, 0x72, 0x63, 0x49, 0x70, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x15, 0x0a, 0x06,
+	0x64, 0x73, 0x74, 0x5f, 0x69, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x64, 0x73,
+	0x74, 0x49, 0x70, 0x12, 0x1e, 0x0a, 0x0b, 0x64, 0x73, 0x74, 0x5f, 0x69, 0x70, 0x5f, 0x6d, 0x61,
+	0x73, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x64, 0x73, 0x74, 0x49, 0x70, 0x4d,
+	0x61, 0x73, 0x6b, 0x12, 0x3d, 0x0a, 0x0e, 0x73, 0x72, 0x63, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x5f,
+	0x72, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x64, 0x61,
+	0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x72, 0x74, 0x52,
+	0x61, 0x6e, 0x67, 0x65, 0x52, 0x0c, 0x73, 0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x61, 0x6e,
+	0x67, 0x65, 0x12, 0x3d, 0x0a, 0x0e, 0x64, 0x73, 0x74, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x5f, 0x72,
+	0x61, 0x6e, 0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x52, 0x0c, 0x64, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x72, 0x63, 0x5f, 0x69, 0x70,
+	0x36, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x72, 0x63, 0x49, 0x70, 0x36, 0x12,
+	0x2b, 0x0a, 0x12, 0x73, 0x72, 0x63, 0x5f, 0x69, 0x70, 0x36, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x5f, 0x6c, 0x65, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x73, 0x72, 0x63,
+	0x49, 0x70, 0x36, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x4c, 0x65, 0x6e, 0x12, 0x17, 0x0a, 0x07,
+	0x64, 0x73, 0x74, 0x5f, 0x69, 0x70, 0x36, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64,
+	0x73, 0x74, 0x49, 0x70, 0x36, 0x12, 0x2b, 0x0a, 0x12, 0x64, 0x73, 0x74, 0x5f, 0x69, 0x70, 0x36,
+	0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x5f, 0x6c, 0x65, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0f, 0x64, 0x73, 0x74, 0x49, 0x70, 0x36, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x4c,
+	0x65, 0x6e, 0x22, 0xc0, 0x05, 0x0a, 0x03, 0x50, 0x64, 0x72, 0x12, 0x15, 0x0a, 0x06, 0x70, 0x64,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x70, 0x64, 0x72, 0x49,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x04, 0x73, 0x65, 0x69, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x66, 0x73, 0x65, 0x69, 0x64, 0x5f, 0x69,
+	0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x66, 0x73, 0x65, 0x69, 0x64, 0x49, 0x70,
+	0x12, 0x1b, 0x0a, 0x09, 0x73, 0x72, 0x63, 0x5f, 0x69, 0x66, 0x61, 0x63, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x73, 0x72, 0x63, 0x49, 0x66, 0x61, 0x63, 0x65, 0x12, 0x24, 0x0a,
+	0x0e, 0x73, 0x72, 0x63, 0x5f, 0x69, 0x66, 0x61, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x73, 0x72, 0x63, 0x49, 0x66, 0x61, 0x63, 0x65, 0x4d,
+	0x61, 0x73, 0x6b, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x70,
+	0x76, 0x34, 0x5f, 0x64, 0x73, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x74, 0x75,
+	0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x70, 0x76, 0x34, 0x44, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x14, 0x74,
+	0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x70, 0x76, 0x34, 0x5f, 0x64, 0x73, 0x74, 0x5f, 0x6d,
+	0x61, 0x73, 0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11, 0x74, 0x75, 0x6e, 0x6e, 0x65,
+	0x6c, 0x49, 0x70, 0x76, 0x34, 0x44, 0x73, 0x74, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x1f, 0x0a, 0x0b,
+	0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x74, 0x65, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0a, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x65, 0x69, 0x64, 0x12, 0x28, 0x0a,
+	0x10, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x74, 0x65, 0x69, 0x64, 0x5f, 0x6d, 0x61, 0x73,
+	0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x54,
+	0x65, 0x69, 0x64, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x1d, 0x0a, 0x0a, 0x75, 0x65, 0x5f, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x75, 0x65, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x3e, 0x0a, 0x0a, 0x61, 0x70, 0x70, 0x5f, 0x66, 0x69,
+	0x6c, 0x74, 0x65, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x09, 0x61, 0x70, 0x70,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x65, 0x63, 0x65, 0x64,
+	0x65, 0x6e, 0x63, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x70, 0x72, 0x65, 0x63,
+	0x65, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x63, 0x74, 0x72, 0x5f, 0x69, 0x64,
+	0x18, 0x0d, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x63, 0x74, 0x72, 0x49, 0x64, 0x12, 0x15, 0x0a,
+	0x06, 0x66, 0x61, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x66,
+	0x61, 0x72, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x71, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x73, 0x18,
+	0x0f, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x06, 0x71, 0x65, 0x72, 0x49, 0x64, 0x73, 0x12, 0x1d, 0x0a,
+	0x0a, 0x6e, 0x65, 0x65, 0x64, 0x5f, 0x64, 0x65, 0x63, 0x61, 0x70, 0x18, 0x10, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x6e, 0x65, 0x65, 0x64, 0x44, 0x65, 0x63, 0x61, 0x70, 0x12, 0x26, 0x0a, 0x0f,
+	0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x70, 0x76, 0x36, 0x5f, 0x64, 0x73, 0x74, 0x18,
+	0x11, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x70, 0x76,
+	0x36, 0x44, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x75, 0x65, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x36, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x75, 0x65, 0x41, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x36, 0x12, 0x33, 0x0a, 0x16, 0x75, 0x65, 0x5f, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x36, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x5f, 0x6c, 0x65, 0x6e, 0x18,
+	0x13, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x13, 0x75, 0x65, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x36, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x4c, 0x65, 0x6e, 0x12, 0x29, 0x0a, 0x10, 0x6e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x14,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0xdf, 0x03, 0x0a, 0x03, 0x46, 0x61, 0x72, 0x12, 0x15, 0x0a,
+	0x06, 0x66, 0x61, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x66,
+	0x61, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x04, 0x73, 0x65, 0x69, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x66, 0x73, 0x65, 0x69,
+	0x64, 0x5f, 0x69, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x66, 0x73, 0x65, 0x69,
+	0x64, 0x49, 0x70, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x73, 0x74, 0x5f, 0x69, 0x6e, 0x74, 0x66, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x64, 0x73, 0x74, 0x49, 0x6e, 0x74, 0x66, 0x12, 0x21,
+	0x0a, 0x0c, 0x61, 0x70, 0x70, 0x6c, 0x79, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x61, 0x70, 0x70, 0x6c, 0x79, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x26, 0x0a, 0x0f, 0x73, 0x65, 0x6e, 0x64, 0x5f, 0x65, 0x6e, 0x64, 0x5f, 0x6d, 0x61,
+	0x72, 0x6b, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x73, 0x65, 0x6e, 0x64,
+	0x45, 0x6e, 0x64, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x75, 0x6e,
+	0x6e, 0x65, 0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a,
+	0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x75,
+	0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x70, 0x76, 0x34, 0x5f, 0x73, 0x72, 0x63, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0d, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x70, 0x76, 0x34, 0x53,
+	0x72, 0x63, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x70, 0x76,
+	0x34, 0x5f, 0x64, 0x73, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x74, 0x75, 0x6e,
+	0x6e, 0x65, 0x6c, 0x49, 0x70, 0x76, 0x34, 0x44, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x75,
+	0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x74, 0x65, 0x69, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0a, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x65, 0x69, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x74,
+	0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0a, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x26, 0x0a, 0x0f,
+	0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x70, 0x76, 0x36, 0x5f, 0x73, 0x72, 0x63, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x70, 0x76,
+	0x36, 0x53, 0x72, 0x63, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69,
+	0x70, 0x76, 0x36, 0x5f, 0x64, 0x73, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x74,
+	0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x70, 0x76, 0x36, 0x44, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x10,
+	0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49,
+	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0xa8, 0x02, 0x0a, 0x03, 0x51, 0x65, 0x72, 0x12,
+	0x15, 0x0a, 0x06, 0x71, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x05, 0x71, 0x65, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x73, 0x65, 0x69, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x66, 0x73,
+	0x65, 0x69, 0x64, 0x5f, 0x69, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x66, 0x73,
+	0x65, 0x69, 0x64, 0x49, 0x70, 0x12, 0x33, 0x0a, 0x09, 0x71, 0x6f, 0x73, 0x5f, 0x6c, 0x65, 0x76,
+	0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70,
+	0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x6f, 0x73, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x52, 0x08, 0x71, 0x6f, 0x73, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x71, 0x66,
+	0x69, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x71, 0x66, 0x69, 0x12, 0x1b, 0x0a, 0x09,
+	0x75, 0x6c, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x08, 0x75, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x6c, 0x5f,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x64, 0x6c,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x75, 0x6c, 0x5f, 0x6d, 0x62, 0x72,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x75, 0x6c, 0x4d, 0x62, 0x72, 0x12, 0x15, 0x0a,
+	0x06, 0x64, 0x6c, 0x5f, 0x6d, 0x62, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x64,
+	0x6c, 0x4d, 0x62, 0x72, 0x12, 0x15, 0x0a, 0x06, 0x75, 0x6c, 0x5f, 0x67, 0x62, 0x72, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x75, 0x6c, 0x47, 0x62, 0x72, 0x12, 0x15, 0x0a, 0x06, 0x64,
+	0x6c, 0x5f, 0x67, 0x62, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x64, 0x6c, 0x47,
+	0x62, 0x72, 0x22, 0x93, 0x01, 0x0a, 0x0a, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x44, 0x61, 0x74,
+	0x61, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x5f, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x75, 0x70,
+	0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0c, 0x75, 0x70, 0x6c, 0x69, 0x6e, 0x6b, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12,
+	0x27, 0x0a, 0x0f, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x76, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x69,
+	0x6e, 0x6b, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x22, 0xa3, 0x03, 0x0a, 0x03, 0x55, 0x72, 0x72,
+	0x12, 0x15, 0x0a, 0x06, 0x75, 0x72, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x05, 0x75, 0x72, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x73, 0x65, 0x69, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x66,
+	0x73, 0x65, 0x69, 0x64, 0x5f, 0x69, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x66,
+	0x73, 0x65, 0x69, 0x64, 0x49, 0x70, 0x12, 0x15, 0x0a, 0x06, 0x63, 0x74, 0x72, 0x5f, 0x69, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x63, 0x74, 0x72, 0x49, 0x64, 0x12, 0x15, 0x0a,
+	0x06, 0x70, 0x64, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x70,
+	0x64, 0x72, 0x49, 0x64, 0x12, 0x2d, 0x0a, 0x12, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x11, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x5f, 0x6f, 0x70,
+	0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x4f, 0x70, 0x65, 0x6e, 0x12, 0x2d, 0x0a, 0x12, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x69, 0x6e,
+	0x67, 0x5f, 0x74, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x11, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x69, 0x6e, 0x67, 0x54, 0x72, 0x69, 0x67, 0x67,
+	0x65, 0x72, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f, 0x74, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x6c, 0x6f,
+	0x63, 0x61, 0x6c, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x43, 0x0a, 0x10,
+	0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61,
+	0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x44, 0x61, 0x74, 0x61,
+	0x52, 0x0f, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c,
+	0x64, 0x12, 0x3b, 0x0a, 0x0c, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x71, 0x75, 0x6f, 0x74,
+	0x61, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x44, 0x61, 0x74,
+	0x61, 0x52, 0x0b, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x22, 0x31,
+	0x0a, 0x0a, 0x50, 0x64, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x03,
+	0x70, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x64, 0x61, 0x74, 0x61,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x64, 0x72, 0x52, 0x03, 0x70, 0x64,
+	0x72, 0x22, 0x31, 0x0a, 0x0a, 0x46, 0x61, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x23, 0x0a, 0x03, 0x66, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x64,
+	0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x72, 0x52,
+	0x03, 0x66, 0x61, 0x72, 0x22, 0x31, 0x0a, 0x0a, 0x51, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x23, 0x0a, 0x03, 0x71, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x51,
+	0x65, 0x72, 0x52, 0x03, 0x71, 0x65, 0x72, 0x22, 0x31, 0x0a, 0x0a, 0x55, 0x72, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x03, 0x75, 0x72, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x11, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x55, 0x72, 0x72, 0x52, 0x03, 0x75, 0x72, 0x72, 0x22, 0x0e, 0x0a, 0x0c, 0x52, 0x75,
+	0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xd4, 0x01, 0x0a, 0x0b, 0x55,
+	0x72, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x75, 0x72,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x75, 0x72, 0x72, 0x49,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x04, 0x73, 0x65, 0x69, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x75, 0x70, 0x6c, 0x69, 0x6e, 0x6b, 0x5f,
+	0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x75,
+	0x70, 0x6c, 0x69, 0x6e, 0x6b, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x29, 0x0a, 0x10,
+	0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b,
+	0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x75, 0x70, 0x6c, 0x69, 0x6e,
+	0x6b, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x75,
+	0x70, 0x6c, 0x69, 0x6e, 0x6b, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x6f,
+	0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0d, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x22, 0x13, 0x0a, 0x11, 0x57, 0x61, 0x74, 0x63, 0x68, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x1a, 0x0a, 0x18, 0x57, 0x61, 0x74, 0x63, 0x68, 0x44,
+	0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x22, 0x27, 0x0a, 0x11, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x44, 0x61,
+	0x74, 0x61, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x73, 0x65, 0x69, 0x64, 0x2a, 0x3c, 0x0a, 0x08, 0x51,
+	0x6f, 0x73, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x19, 0x0a, 0x15, 0x51, 0x4f, 0x53, 0x5f, 0x4c,
+	0x45, 0x56, 0x45, 0x4c, 0x5f, 0x41, 0x50, 0x50, 0x4c, 0x49, 0x43, 0x41, 0x54, 0x49, 0x4f, 0x4e,
+	0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x4f, 0x53, 0x5f, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x5f,
+	0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x01, 0x32, 0xc0, 0x08, 0x0a, 0x10, 0x44, 0x61,
+	0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x12, 0x43,
+	0x0a, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x64, 0x72, 0x12, 0x18, 0x2e, 0x64, 0x61,
+	0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x64, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x09, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x50, 0x64, 0x72,
+	0x12, 0x18, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x50, 0x64, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x50, 0x64, 0x72, 0x12, 0x18, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x64, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1a, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43, 0x0a,
+	0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x61, 0x72, 0x12, 0x18, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65,
+	0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x12, 0x43, 0x0a, 0x09, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x46, 0x61, 0x72, 0x12,
+	0x18, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x46,
+	0x61, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x61, 0x74, 0x61,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x46, 0x61, 0x72, 0x12, 0x18, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65,
+	0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75,
+	0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x09,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x51, 0x65, 0x72, 0x12, 0x18, 0x2e, 0x64, 0x61, 0x74, 0x61,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x43, 0x0a, 0x09, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x51, 0x65, 0x72, 0x12, 0x18,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x65,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70,
+	0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x51, 0x65, 0x72, 0x12, 0x18, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x51, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e,
+	0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x09, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x55, 0x72, 0x72, 0x12, 0x18, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70,
+	0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x72, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
+	0x12, 0x43, 0x0a, 0x09, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x55, 0x72, 0x72, 0x12, 0x18, 0x2e,
+	0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x72, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55,
+	0x72, 0x72, 0x12, 0x18, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x55, 0x72, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64,
+	0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x07, 0x52, 0x65,
+	0x61, 0x64, 0x55, 0x72, 0x72, 0x12, 0x18, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x72, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x19, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x55,
+	0x72, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x22, 0x00, 0x12, 0x4c, 0x0a, 0x0a,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x55,
+	0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x64, 0x61,
+	0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x72, 0x72, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x22, 0x00, 0x30, 0x01, 0x12, 0x60, 0x0a, 0x11, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x12,
+	0x26, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x44,
+	0x61, 0x74, 0x61, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x00, 0x30, 0x01, 0x42, 0x34, 0x5a, 0x32,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x72, 0x64, 0x7a, 0x6f,
+	0x68, 0x74, 0x2f, 0x6f, 0x6d, 0x65, 0x63, 0x2d, 0x75, 0x70, 0x66, 0x2f, 0x70, 0x66, 0x63, 0x70,
+	0x69, 0x66, 0x61, 0x63, 0x65, 0x2f, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x5f,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_dataplane_proto_rawDescOnce sync.Once
+	file_dataplane_proto_rawDescData = file_dataplane_proto_rawDesc
+)
+
+func file_dataplane_proto_rawDescGZIP() []byte {
+	file_dataplane_proto_rawDescOnce.Do(func() {
+		file_dataplane_proto_rawDescData = protoimpl.X.CompressGZIP(file_dataplane_proto_rawDescData)
+	})
+	return file_dataplane_proto_rawDescData
+}
+
+var file_dataplane_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_dataplane_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_dataplane_proto_goTypes = []interface{}{
+	(QosLevel)(0),                    // 0: dataplane.v1.QosLevel
+	(*PortRange)(nil),                // 1: dataplane.v1.PortRange
+	(*ApplicationFilter)(nil),        // 2: dataplane.v1.ApplicationFilter
+	(*Pdr)(nil),                      // 3: dataplane.v1.Pdr
+	(*Far)(nil),                      // 4: dataplane.v1.Far
+	(*Qer)(nil),                      // 5: dataplane.v1.Qer
+	(*VolumeData)(nil),               // 6: dataplane.v1.VolumeData
+	(*Urr)(nil),                      // 7: dataplane.v1.Urr
+	(*PdrRequest)(nil),               // 8: dataplane.v1.PdrRequest
+	(*FarRequest)(nil),               // 9: dataplane.v1.FarRequest
+	(*QerRequest)(nil),               // 10: dataplane.v1.QerRequest
+	(*UrrRequest)(nil),               // 11: dataplane.v1.UrrRequest
+	(*RuleResponse)(nil),             // 12: dataplane.v1.RuleResponse
+	(*UrrCounters)(nil),              // 13: dataplane.v1.UrrCounters
+	(*WatchUsageRequest)(nil),        // 14: dataplane.v1.WatchUsageRequest
+	(*WatchDownlinkDataRequest)(nil), // 15: dataplane.v1.WatchDownlinkDataRequest
+	(*DownlinkDataEvent)(nil),        // 16: dataplane.v1.DownlinkDataEvent
+}
+var file_dataplane_proto_depIdxs = []int32{
+	1,  // 0: dataplane.v1.ApplicationFilter.src_port_range:type_name -> dataplane.v1.PortRange
+	1,  // 1: dataplane.v1.ApplicationFilter.dst_port_range:type_name -> dataplane.v1.PortRange
+	2,  // 2: dataplane.v1.Pdr.app_filter:type_name -> dataplane.v1.ApplicationFilter
+	0,  // 3: dataplane.v1.Qer.qos_level:type_name -> dataplane.v1.QosLevel
+	6,  // 4: dataplane.v1.Urr.volume_threshold:type_name -> dataplane.v1.VolumeData
+	6,  // 5: dataplane.v1.Urr.volume_quota:type_name -> dataplane.v1.VolumeData
+	3,  // 6: dataplane.v1.PdrRequest.pdr:type_name -> dataplane.v1.Pdr
+	4,  // 7: dataplane.v1.FarRequest.far:type_name -> dataplane.v1.Far
+	5,  // 8: dataplane.v1.QerRequest.qer:type_name -> dataplane.v1.Qer
+	7,  // 9: dataplane.v1.UrrRequest.urr:type_name -> dataplane.v1.Urr
+	8,  // 10: dataplane.v1.DataplaneControl.CreatePdr:input_type -> dataplane.v1.PdrRequest
+	8,  // 11: dataplane.v1.DataplaneControl.ModifyPdr:input_type -> dataplane.v1.PdrRequest
+	8,  // 12: dataplane.v1.DataplaneControl.DeletePdr:input_type -> dataplane.v1.PdrRequest
+	9,  // 13: dataplane.v1.DataplaneControl.CreateFar:input_type -> dataplane.v1.FarRequest
+	9,  // 14: dataplane.v1.DataplaneControl.ModifyFar:input_type -> dataplane.v1.FarRequest
+	9,  // 15: dataplane.v1.DataplaneControl.DeleteFar:input_type -> dataplane.v1.FarRequest
+	10, // 16: dataplane.v1.DataplaneControl.CreateQer:input_type -> dataplane.v1.QerRequest
+	10, // 17: dataplane.v1.DataplaneControl.ModifyQer:input_type -> dataplane.v1.QerRequest
+	10, // 18: dataplane.v1.DataplaneControl.DeleteQer:input_type -> dataplane.v1.QerRequest
+	11, // 19: dataplane.v1.DataplaneControl.CreateUrr:input_type -> dataplane.v1.UrrRequest
+	11, // 20: dataplane.v1.DataplaneControl.ModifyUrr:input_type -> dataplane.v1.UrrRequest
+	11, // 21: dataplane.v1.DataplaneControl.DeleteUrr:input_type -> dataplane.v1.UrrRequest
+	11, // 22: dataplane.v1.DataplaneControl.ReadUrr:input_type -> dataplane.v1.UrrRequest
+	14, // 23: dataplane.v1.DataplaneControl.WatchUsage:input_type -> dataplane.v1.WatchUsageRequest
+	15, // 24: dataplane.v1.DataplaneControl.WatchDownlinkData:input_type -> dataplane.v1.WatchDownlinkDataRequest
+	12, // 25: dataplane.v1.DataplaneControl.CreatePdr:output_type -> dataplane.v1.RuleResponse
+	12, // 26: dataplane.v1.DataplaneControl.ModifyPdr:output_type -> dataplane.v1.RuleResponse
+	12, // 27: dataplane.v1.DataplaneControl.DeletePdr:output_type -> dataplane.v1.RuleResponse
+	12, // 28: dataplane.v1.DataplaneControl.CreateFar:output_type -> dataplane.v1.RuleResponse
+	12, // 29: dataplane.v1.DataplaneControl.ModifyFar:output_type -> dataplane.v1.RuleResponse
+	12, // 30: dataplane.v1.DataplaneControl.DeleteFar:output_type -> dataplane.v1.RuleResponse
+	12, // 31: dataplane.v1.DataplaneControl.CreateQer:output_type -> dataplane.v1.RuleResponse
+	12, // 32: dataplane.v1.DataplaneControl.ModifyQer:output_type -> dataplane.v1.RuleResponse
+	12, // 33: dataplane.v1.DataplaneControl.DeleteQer:output_type -> dataplane.v1.RuleResponse
+	12, // 34: dataplane.v1.DataplaneControl.CreateUrr:output_type -> dataplane.v1.RuleResponse
+	12, // 35: dataplane.v1.DataplaneControl.ModifyUrr:output_type -> dataplane.v1.RuleResponse
+	12, // 36: dataplane.v1.DataplaneControl.DeleteUrr:output_type -> dataplane.v1.RuleResponse
+	13, // 37: dataplane.v1.DataplaneControl.ReadUrr:output_type -> dataplane.v1.UrrCounters
+	13, // 38: dataplane.v1.DataplaneControl.WatchUsage:output_type -> dataplane.v1.UrrCounters
+	16, // 39: dataplane.v1.DataplaneControl.WatchDownlinkData:output_type -> dataplane.v1.DownlinkDataEvent
+	25, // [25:40] is the sub-list for method output_type
+	10, // [10:25] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_dataplane_proto_init() }
+func file_dataplane_proto_init() {
+	if File_dataplane_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_dataplane_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PortRange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApplicationFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Pdr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Far); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Qer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VolumeData); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Urr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PdrRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FarRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UrrRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UrrCounters); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchUsageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchDownlinkDataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dataplane_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DownlinkDataEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_dataplane_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_dataplane_proto_goTypes,
+		DependencyIndexes: file_dataplane_proto_depIdxs,
+		EnumInfos:         file_dataplane_proto_enumTypes,
+		MessageInfos:      file_dataplane_proto_msgTypes,
+	}.Build()
+	File_dataplane_proto = out.File
+	file_dataplane_proto_rawDesc = nil
+	file_dataplane_proto_goTypes = nil
+	file_dataplane_proto_depIdxs = nil
+}
@@ -0,0 +1,676 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.4
+// source: dataplane.proto
+
+package dataplane_pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DataplaneControlClient is the client API for DataplaneControl service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DataplaneControlClient interface {
+	CreatePdr(ctx context.Context, in *PdrRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	ModifyPdr(ctx context.Context, in *PdrRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	DeletePdr(ctx context.Context, in *PdrRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	CreateFar(ctx context.Context, in *FarRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	ModifyFar(ctx context.Context, in *FarRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	DeleteFar(ctx context.Context, in *FarRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	CreateQer(ctx context.Context, in *QerRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	ModifyQer(ctx context.Context, in *QerRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	DeleteQer(ctx context.Context, in *QerRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	CreateUrr(ctx context.Context, in *UrrRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	ModifyUrr(ctx context.Context, in *UrrRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	DeleteUrr(ctx context.Context, in *UrrRequest, opts ...grpc.CallOption) (*RuleResponse, error)
+	// ReadUrr returns the current counters of a URR.
+	ReadUrr(ctx context.Context, in *UrrRequest, opts ...grpc.CallOption) (*UrrCounters, error)
+	// WatchUsage streams the counters of URRs whenever the dataplane refreshes them, so that the
+	// agent reports usage when the thresholds and quotas of the URRs are reached.
+	WatchUsage(ctx context.Context, in *WatchUsageRequest, opts ...grpc.CallOption) (DataplaneControl_WatchUsageClient, error)
+	// WatchDownlinkData streams an event whenever the dataplane buffers downlink packets of a
+	// session, e.g. for a UE in idle mode. Events are sent for every buffered packet, the agent
+	// rate limits the notifications to the CP function.
+	WatchDownlinkData(ctx context.Context, in *WatchDownlinkDataRequest, opts ...grpc.CallOption) (DataplaneControl_WatchDownlinkDataClient, error)
+}
+
+type dataplaneControlClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDataplaneControlClient(cc grpc.ClientConnInterface) DataplaneControlClient {
+	return &dataplaneControlClient{cc}
+}
+
+func (c *dataplaneControlClient) CreatePdr(ctx context.Context, in *PdrRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/CreatePdr", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) ModifyPdr(ctx context.Context, in *PdrRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/ModifyPdr", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) DeletePdr(ctx context.Context, in *PdrRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/DeletePdr", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) CreateFar(ctx context.Context, in *FarRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/CreateFar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) ModifyFar(ctx context.Context, in *FarRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/ModifyFar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) DeleteFar(ctx context.Context, in *FarRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/DeleteFar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) CreateQer(ctx context.Context, in *QerRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/CreateQer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) ModifyQer(ctx context.Context, in *QerRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/ModifyQer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) DeleteQer(ctx context.Context, in *QerRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/DeleteQer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) CreateUrr(ctx context.Context, in *UrrRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/CreateUrr", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) ModifyUrr(ctx context.Context, in *UrrRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/ModifyUrr", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) DeleteUrr(ctx context.Context, in *UrrRequest, opts ...grpc.CallOption) (*RuleResponse, error) {
+	out := new(RuleResponse)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/DeleteUrr", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) ReadUrr(ctx context.Context, in *UrrRequest, opts ...grpc.CallOption) (*UrrCounters, error) {
+	out := new(UrrCounters)
+	err := c.cc.Invoke(ctx, "/dataplane.v1.DataplaneControl/ReadUrr", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataplaneControlClient) WatchUsage(ctx context.Context, in *WatchUsageRequest, opts ...grpc.CallOption) (DataplaneControl_WatchUsageClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DataplaneControl_ServiceDesc.Streams[0], "/dataplane.v1.DataplaneControl/WatchUsage", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataplaneControlWatchUsageClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataplaneControl_WatchUsageClient interface {
+	Recv() (*UrrCounters, error)
+	grpc.ClientStream
+}
+
+type dataplaneControlWatchUsageClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataplaneControlWatchUsageClient) Recv() (*UrrCounters, error) {
+	m := new(UrrCounters)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataplaneControlClient) WatchDownlinkData(ctx context.Context, in *WatchDownlinkDataRequest, opts ...grpc.CallOption) (DataplaneControl_WatchDownlinkDataClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DataplaneControl_ServiceDesc.Streams[1], "/dataplane.v1.DataplaneControl/WatchDownlinkData", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataplaneControlWatchDownlinkDataClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataplaneControl_WatchDownlinkDataClient interface {
+	Recv() (*DownlinkDataEvent, error)
+	grpc.ClientStream
+}
+
+type dataplaneControlWatchDownlinkDataClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataplaneControlWatchDownlinkDataClient) Recv() (*DownlinkDataEvent, error) {
+	m := new(DownlinkDataEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DataplaneControlServer is the server API for DataplaneControl service.
+// All implementations must embed UnimplementedDataplaneControlServer
+// for forward compatibility
+type DataplaneControlServer interface {
+	CreatePdr(context.Context, *PdrRequest) (*RuleResponse, error)
+	ModifyPdr(context.Context, *PdrRequest) (*RuleResponse, error)
+	DeletePdr(context.Context, *PdrRequest) (*RuleResponse, error)
+	CreateFar(context.Context, *FarRequest) (*RuleResponse, error)
+	ModifyFar(context.Context, *FarRequest) (*RuleResponse, error)
+	DeleteFar(context.Context, *FarRequest) (*RuleResponse, error)
+	CreateQer(context.Context, *QerRequest) (*RuleResponse, error)
+	ModifyQer(context.Context, *QerRequest) (*RuleResponse, error)
+	DeleteQer(context.Context, *QerRequest) (*RuleResponse, error)
+	CreateUrr(context.Context, *UrrRequest) (*RuleResponse, error)
+	ModifyUrr(context.Context, *UrrRequest) (*RuleResponse, error)
+	DeleteUrr(context.Context, *UrrRequest) (*RuleResponse, error)
+	// ReadUrr returns the current counters of a URR.
+	ReadUrr(context.Context, *UrrRequest) (*UrrCounters, error)
+	// WatchUsage streams the counters of URRs whenever the dataplane refreshes them, so that the
+	// agent reports usage when the thresholds and quotas of the URRs are reached.
+	WatchUsage(*WatchUsageRequest, DataplaneControl_WatchUsageServer) error
+	// WatchDownlinkData streams an event whenever the dataplane buffers downlink packets of a
+	// session, e.g. for a UE in idle mode. Events are sent for every buffered packet, the agent
+	// rate limits the notifications to the CP function.
+	WatchDownlinkData(*WatchDownlinkDataRequest, DataplaneControl_WatchDownlinkDataServer) error
+	mustEmbedUnimplementedDataplaneControlServer()
+}
+
+// UnimplementedDataplaneControlServer must be embedded to have forward compatible implementations.
+type UnimplementedDataplaneControlServer struct {
+}
+
+func (UnimplementedDataplaneControlServer) CreatePdr(context.Context, *PdrRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePdr not implemented")
+}
+func (UnimplementedDataplaneControlServer) ModifyPdr(context.Context, *PdrRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModifyPdr not implemented")
+}
+func (UnimplementedDataplaneControlServer) DeletePdr(context.Context, *PdrRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePdr not implemented")
+}
+func (UnimplementedDataplaneControlServer) CreateFar(context.Context, *FarRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFar not implemented")
+}
+func (UnimplementedDataplaneControlServer) ModifyFar(context.Context, *FarRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModifyFar not implemented")
+}
+func (UnimplementedDataplaneControlServer) DeleteFar(context.Context, *FarRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFar not implemented")
+}
+func (UnimplementedDataplaneControlServer) CreateQer(context.Context, *QerRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateQer not implemented")
+}
+func (UnimplementedDataplaneControlServer) ModifyQer(context.Context, *QerRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModifyQer not implemented")
+}
+func (UnimplementedDataplaneControlServer) DeleteQer(context.Context, *QerRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteQer not implemented")
+}
+func (UnimplementedDataplaneControlServer) CreateUrr(context.Context, *UrrRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUrr not implemented")
+}
+func (UnimplementedDataplaneControlServer) ModifyUrr(context.Context, *UrrRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModifyUrr not implemented")
+}
+func (UnimplementedDataplaneControlServer) DeleteUrr(context.Context, *UrrRequest) (*RuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUrr not implemented")
+}
+func (UnimplementedDataplaneControlServer) ReadUrr(context.Context, *UrrRequest) (*UrrCounters, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadUrr not implemented")
+}
+func (UnimplementedDataplaneControlServer) WatchUsage(*WatchUsageRequest, DataplaneControl_WatchUsageServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchUsage not implemented")
+}
+func (UnimplementedDataplaneControlServer) WatchDownlinkData(*WatchDownlinkDataRequest, DataplaneControl_WatchDownlinkDataServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchDownlinkData not implemented")
+}
+func (UnimplementedDataplaneControlServer) mustEmbedUnimplementedDataplaneControlServer() {}
+
+// UnsafeDataplaneControlServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DataplaneControlServer will
+// result in compilation errors.
+type UnsafeDataplaneControlServer interface {
+	mustEmbedUnimplementedDataplaneControlServer()
+}
+
+func RegisterDataplaneControlServer(s grpc.ServiceRegistrar, srv DataplaneControlServer) {
+	s.RegisterService(&DataplaneControl_ServiceDesc, srv)
+}
+
+func _DataplaneControl_CreatePdr_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PdrRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).CreatePdr(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/CreatePdr",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).CreatePdr(ctx, req.(*PdrRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_ModifyPdr_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PdrRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).ModifyPdr(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/ModifyPdr",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).ModifyPdr(ctx, req.(*PdrRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_DeletePdr_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PdrRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).DeletePdr(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/DeletePdr",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).DeletePdr(ctx, req.(*PdrRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_CreateFar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).CreateFar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/CreateFar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).CreateFar(ctx, req.(*FarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_ModifyFar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).ModifyFar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/ModifyFar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).ModifyFar(ctx, req.(*FarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_DeleteFar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).DeleteFar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/DeleteFar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).DeleteFar(ctx, req.(*FarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_CreateQer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).CreateQer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/CreateQer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).CreateQer(ctx, req.(*QerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_ModifyQer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).ModifyQer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/ModifyQer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).ModifyQer(ctx, req.(*QerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_DeleteQer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).DeleteQer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/DeleteQer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).DeleteQer(ctx, req.(*QerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_CreateUrr_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UrrRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).CreateUrr(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/CreateUrr",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).CreateUrr(ctx, req.(*UrrRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_ModifyUrr_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UrrRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).ModifyUrr(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/ModifyUrr",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).ModifyUrr(ctx, req.(*UrrRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_DeleteUrr_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UrrRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).DeleteUrr(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/DeleteUrr",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).DeleteUrr(ctx, req.(*UrrRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_ReadUrr_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UrrRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataplaneControlServer).ReadUrr(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dataplane.v1.DataplaneControl/ReadUrr",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataplaneControlServer).ReadUrr(ctx, req.(*UrrRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataplaneControl_WatchUsage_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchUsageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataplaneControlServer).WatchUsage(m, &dataplaneControlWatchUsageServer{stream})
+}
+
+type DataplaneControl_WatchUsageServer interface {
+	Send(*UrrCounters) error
+	grpc.ServerStream
+}
+
+type dataplaneControlWatchUsageServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataplaneControlWatchUsageServer) Send(m *UrrCounters) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DataplaneControl_WatchDownlinkData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchDownlinkDataRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataplaneControlServer).WatchDownlinkData(m, &dataplaneControlWatchDownlinkDataServer{stream})
+}
+
+type DataplaneControl_WatchDownlinkDataServer interface {
+	Send(*DownlinkDataEvent) error
+	grpc.ServerStream
+}
+
+type dataplaneControlWatchDownlinkDataServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataplaneControlWatchDownlinkDataServer) Send(m *DownlinkDataEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// DataplaneControl_ServiceDesc is the grpc.ServiceDesc for DataplaneControl service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DataplaneControl_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dataplane.v1.DataplaneControl",
+	HandlerType: (*DataplaneControlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePdr",
+			Handler:    _DataplaneControl_CreatePdr_Handler,
+		},
+		{
+			MethodName: "ModifyPdr",
+			Handler:    _DataplaneControl_ModifyPdr_Handler,
+		},
+		{
+			MethodName: "DeletePdr",
+			Handler:    _DataplaneControl_DeletePdr_Handler,
+		},
+		{
+			MethodName: "CreateFar",
+			Handler:    _DataplaneControl_CreateFar_Handler,
+		},
+		{
+			MethodName: "ModifyFar",
+			Handler:    _DataplaneControl_ModifyFar_Handler,
+		},
+		{
+			MethodName: "DeleteFar",
+			Handler:    _DataplaneControl_DeleteFar_Handler,
+		},
+		{
+			MethodName: "CreateQer",
+			Handler:    _DataplaneControl_CreateQer_Handler,
+		},
+		{
+			MethodName: "ModifyQer",
+			Handler:    _DataplaneControl_ModifyQer_Handler,
+		},
+		{
+			MethodName: "DeleteQer",
+			Handler:    _DataplaneControl_DeleteQer_Handler,
+		},
+		{
+			MethodName: "CreateUrr",
+			Handler:    _DataplaneControl_CreateUrr_Handler,
+		},
+		{
+			MethodName: "ModifyUrr",
+			Handler:    _DataplaneControl_ModifyUrr_Handler,
+		},
+		{
+			MethodName: "DeleteUrr",
+			Handler:    _DataplaneControl_DeleteUrr_Handler,
+		},
+		{
+			MethodName: "ReadUrr",
+			Handler:    _DataplaneControl_ReadUrr_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchUsage",
+			Handler:       _DataplaneControl_WatchUsage_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchDownlinkData",
+			Handler:       _DataplaneControl_WatchDownlinkData_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "dataplane.proto",
+}
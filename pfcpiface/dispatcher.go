@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"sync"
+
+	"github.com/wmnsk/go-pfcp/message"
+)
+
+// workerQueueLen is the number of messages queued for a worker before the reader waits.
+const workerQueueLen = 64
+
+// dispatcher distributes the messages received from the peer of a PFCPConn to workers sharded
+// by session, so that a slow datapath call for one session does not delay the others. The
+// messages of a session are handled in order by the same worker.
+//
+// Node related messages, like association or Session Set Deletion messages, may affect every
+// session: they are handled by the reader, once all the messages received before them are.
+type dispatcher struct {
+	shards []chan []byte
+	// workers tracks the running workers, they return once the PFCPConn is shut down
+	workers sync.WaitGroup
+
+	mu   sync.Mutex
+	idle *sync.Cond
+	// pending counts the messages dispatched to the workers and not handled yet
+	pending int
+	stopped bool
+}
+
+func newDispatcher(workers int) *dispatcher {
+	d := &dispatcher{shards: make([]chan []byte, workers)}
+	d.idle = sync.NewCond(&d.mu)
+
+	for i := range d.shards {
+		d.shards[i] = make(chan []byte, workerQueueLen)
+	}
+
+	return d
+}
+
+func (d *dispatcher) add() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pending++
+}
+
+func (d *dispatcher) done() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pending--
+	if d.pending == 0 {
+		d.idle.Broadcast()
+	}
+}
+
+// wait waits for the workers to handle all the messages dispatched to them. It returns false
+// if the dispatcher is stopped meanwhile.
+func (d *dispatcher) wait() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for d.pending > 0 && !d.stopped {
+		d.idle.Wait()
+	}
+
+	return !d.stopped
+}
+
+// stop releases the reader waiting for the workers, and waits for the workers to return, once
+// done with the message they are handling. It must be called after the PFCPConn is shut down,
+// and not by a worker.
+func (d *dispatcher) stop() {
+	d.mu.Lock()
+	d.stopped = true
+	d.idle.Broadcast()
+	d.mu.Unlock()
+
+	d.workers.Wait()
+}
+
+// shard returns the worker handling the message of header h, -1 for node related messages.
+func (d *dispatcher) shard(h *message.Header) int {
+	if !h.HasSEID() {
+		return -1
+	}
+
+	// A session is not known by its local SEID until it is established: the retransmissions
+	// of a request share its sequence number, and the peer waits for the response to send
+	// other messages for the session.
+	key := h.SEID
+	if h.MessageType() == message.MsgTypeSessionEstablishmentRequest {
+		key = uint64(h.Sequence())
+	}
+
+	return int(key % uint64(len(d.shards)))
+}
+
+// startWorkers starts the workers of pConn, which run until it is shut down.
+func (pConn *PFCPConn) startWorkers() {
+	for _, shard := range pConn.dispatcher.shards {
+		pConn.dispatcher.workers.Add(1)
+
+		go pConn.work(shard)
+	}
+}
+
+func (pConn *PFCPConn) work(shard <-chan []byte) {
+	defer pConn.dispatcher.workers.Done()
+
+	for {
+		select {
+		case buf := <-shard:
+			pConn.HandlePFCPMsg(buf)
+			pConn.dispatcher.done()
+		case <-pConn.shutdown:
+			return
+		}
+	}
+}
+
+// dispatch handles buf, the message received from the peer, on the worker of its session.
+// Without workers, messages are handled inline.
+func (pConn *PFCPConn) dispatch(buf []byte) {
+	d := pConn.dispatcher
+	if d == nil {
+		pConn.HandlePFCPMsg(buf)
+		return
+	}
+
+	// Undecodable messages are reported by HandlePFCPMsg
+	h, err := message.ParseHeader(buf)
+	if err != nil {
+		pConn.HandlePFCPMsg(buf)
+		return
+	}
+
+	i := d.shard(h)
+	if i < 0 {
+		if d.wait() {
+			pConn.HandlePFCPMsg(buf)
+		}
+
+		return
+	}
+
+	d.add()
+
+	select {
+	case d.shards[i] <- buf:
+	case <-pConn.shutdown:
+		d.done()
+	}
+}
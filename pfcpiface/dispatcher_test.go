@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+	"github.com/wmnsk/go-pfcp/message"
+)
+
+// slowDatapath delays the creation of every PDR, and blocks the first one until released.
+type slowDatapath struct {
+	*RecordingDatapath
+	delay time.Duration
+
+	once    sync.Once
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newSlowDatapath(delay time.Duration) *slowDatapath {
+	return &slowDatapath{
+		RecordingDatapath: NewRecordingDatapath(),
+		delay:             delay,
+		entered:           make(chan struct{}),
+		release:           make(chan struct{}),
+	}
+}
+
+func (s *slowDatapath) CreatePDR(ctx context.Context, pdr Pdr) error {
+	first := false
+	s.once.Do(func() { first = true })
+
+	if first {
+		close(s.entered)
+		<-s.release
+	}
+
+	time.Sleep(s.delay)
+
+	return s.RecordingDatapath.CreatePDR(ctx, pdr)
+}
+
+// newTestDispatchingPFCPConn returns a PFCPConn dispatching its messages to workers, whose
+// responses are received on the returned socket.
+func newTestDispatchingPFCPConn(tb testing.TB, dp Datapath, workers int) (*PFCPConn, net.PacketConn) {
+	smf, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(tb, err)
+
+	tb.Cleanup(func() { _ = smf.Close() })
+
+	conn, err := net.Dial("udp", smf.LocalAddr().String())
+	require.NoError(tb, err)
+
+	tb.Cleanup(func() { _ = conn.Close() })
+
+	pConn := newTestPFCPConnWithUpf(tb, newTestUpf(dp))
+	pConn.Conn = conn
+	pConn.shutdown = make(chan struct{})
+
+	if workers > 0 {
+		pConn.dispatcher = newDispatcher(workers)
+		pConn.startWorkers()
+	}
+
+	tb.Cleanup(func() {
+		select {
+		case <-pConn.shutdown:
+		default:
+			close(pConn.shutdown)
+		}
+
+		if pConn.dispatcher != nil {
+			pConn.dispatcher.stop()
+		}
+	})
+
+	return pConn, smf
+}
+
+// marshalTestEstablishmentRequest returns an establishment request for a session of remote
+// SEID rseid, with sequence number seq.
+func marshalTestEstablishmentRequest(tb testing.TB, pConn *PFCPConn, seq uint32, rseid uint64) []byte {
+	req := newTestEstablishmentRequest(pConn)
+	req.SetSequenceNumber(seq)
+	req.CPFSEID = ie.NewFSEID(rseid, net.ParseIP(testSMFNodeID), nil)
+
+	buf := make([]byte, req.MarshalLen())
+	require.NoError(tb, req.MarshalTo(buf))
+
+	return buf
+}
+
+func TestDispatcher_Shard(t *testing.T) {
+	d := newDispatcher(4)
+
+	shard := func(msg message.Message) int {
+		buf := make([]byte, msg.MarshalLen())
+		require.NoError(t, msg.MarshalTo(buf))
+
+		h, err := message.ParseHeader(buf)
+		require.NoError(t, err)
+
+		return d.shard(h)
+	}
+
+	require.Equal(t, -1, shard(message.NewHeartbeatRequest(1, nil, nil)))
+	require.Equal(t, -1, shard(message.NewAssociationSetupRequest(2)))
+	require.Equal(t, -1, shard(message.NewSessionSetDeletionRequest(3, nil, nil)))
+
+	// Messages of a session share a worker
+	require.Equal(t, 1, shard(message.NewSessionModificationRequest(0, 0, 5, 1, 0)))
+	require.Equal(t, 1, shard(message.NewSessionDeletionRequest(0, 0, 5, 2, 0)))
+	require.Equal(t, 2, shard(message.NewSessionModificationRequest(0, 0, 6, 3, 0)))
+
+	// Establishment requests are sharded by sequence number
+	require.Equal(t, 3, shard(message.NewSessionEstablishmentRequest(0, 0, 0, 7, 0)))
+	require.Equal(t, 0, shard(message.NewSessionEstablishmentRequest(0, 0, 0, 8, 0)))
+}
+
+func TestPFCPConn_Dispatch(t *testing.T) {
+	dp := newSlowDatapath(0)
+	pConn, smf := newTestDispatchingPFCPConn(t, dp, 4)
+
+	// The first session is blocked in the datapath
+	pConn.dispatch(marshalTestEstablishmentRequest(t, pConn, 1, 0x100))
+	<-dp.entered
+
+	// Sessions of other workers proceed meanwhile
+	pConn.dispatch(marshalTestEstablishmentRequest(t, pConn, 2, 0x200))
+
+	msg := readPFCPMessage(t, smf)
+	require.NotNil(t, msg)
+	require.Equal(t, message.MsgTypeSessionEstablishmentResponse, msg.MessageType())
+	require.Equal(t, uint32(2), msg.Sequence())
+
+	// Node related messages wait for the messages received before them
+	hbreq := message.NewHeartbeatRequest(3, ie.NewRecoveryTimeStamp(time.Now()), nil)
+	buf := make([]byte, hbreq.MarshalLen())
+	require.NoError(t, hbreq.MarshalTo(buf))
+
+	dispatched := make(chan struct{})
+
+	go func() {
+		pConn.dispatch(buf)
+		close(dispatched)
+	}()
+
+	require.Nil(t, readPFCPMessage(t, smf))
+
+	close(dp.release)
+	<-dispatched
+
+	msg = readPFCPMessage(t, smf)
+	require.NotNil(t, msg)
+	require.Equal(t, message.MsgTypeSessionEstablishmentResponse, msg.MessageType())
+	require.Equal(t, uint32(1), msg.Sequence())
+
+	msg = readPFCPMessage(t, smf)
+	require.NotNil(t, msg)
+	require.Equal(t, message.MsgTypeHeartbeatResponse, msg.MessageType())
+
+	require.Len(t, pConn.store.GetAllSessions(), 2)
+}
+
+func TestPFCPConn_DispatchStopped(t *testing.T) {
+	dp := newSlowDatapath(0)
+	pConn, _ := newTestDispatchingPFCPConn(t, dp, 2)
+
+	pConn.dispatch(marshalTestEstablishmentRequest(t, pConn, 1, 0x100))
+	<-dp.entered
+
+	// A reader waiting for the workers is released on shutdown
+	dispatched := make(chan struct{})
+
+	go func() {
+		hbreq := message.NewHeartbeatRequest(2, nil, nil)
+		buf := make([]byte, hbreq.MarshalLen())
+		_ = hbreq.MarshalTo(buf)
+
+		pConn.dispatch(buf)
+		close(dispatched)
+	}()
+
+	close(pConn.shutdown)
+
+	stopped := make(chan struct{})
+
+	go func() {
+		pConn.dispatcher.stop()
+		close(stopped)
+	}()
+
+	<-dispatched
+
+	// Stopping waits for the message in flight
+	select {
+	case <-stopped:
+		t.Fatal("dispatcher stopped while a worker was handling a message")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(dp.release)
+	<-stopped
+
+	require.Len(t, pConn.store.GetAllSessions(), 1)
+}
+
+func TestPFCPConn_ShutdownPurgesAfterWorkers(t *testing.T) {
+	dp := newSlowDatapath(0)
+	pConn, _ := newTestDispatchingPFCPConn(t, dp, 2)
+	pConn.ctx, pConn.done = context.Background(), make(chan string, 1)
+
+	pConn.dispatch(marshalTestEstablishmentRequest(t, pConn, 1, 0x100))
+	<-dp.entered
+
+	stopped := make(chan struct{})
+
+	go func() {
+		pConn.Shutdown()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("PFCPConn shut down while a worker was handling a message")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(dp.release)
+	<-stopped
+
+	// The session established while shutting down is purged too
+	require.Equal(t, PacketForwardingRules{}, dp.Rules())
+	require.Empty(t, pConn.store.GetAllSessions())
+	require.Zero(t, pConn.upf.teidPools.byName[teidPoolAccess].ids.Used())
+}
+
+func BenchmarkPFCPConn_SessionEstablishment(b *testing.B) {
+	for _, workers := range []int{0, 1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			dp := newSlowDatapath(100 * time.Microsecond)
+			close(dp.release)
+
+			pConn, _ := newTestDispatchingPFCPConn(b, dp, workers)
+
+			reqs := make([][]byte, b.N)
+			for i := range reqs {
+				reqs[i] = marshalTestEstablishmentRequest(b, pConn, uint32(i+1), uint64(i+1))
+			}
+
+			b.ResetTimer()
+
+			for _, req := range reqs {
+				pConn.dispatch(req)
+			}
+
+			if pConn.dispatcher != nil {
+				pConn.dispatcher.wait()
+			}
+
+			b.StopTimer()
+
+			require.Len(b, pConn.store.GetAllSessions(), b.N)
+		})
+	}
+}
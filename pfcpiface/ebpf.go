@@ -4,60 +4,337 @@
 package pfcpiface
 
 import (
+	"context"
 	"net"
+	"sync/atomic"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/wmnsk/go-pfcp/ie"
-
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/ardzoht/omec-upf/pfcpiface/dataplane_pb"
+)
+
+const (
+	// dataplaneAddrDefault is the address of the DataplaneControl service, if not configured.
+	dataplaneAddrDefault = "localhost:50051"
+	// healthCheckIntervalDefault is the period of the dataplane health check, if not configured.
+	healthCheckIntervalDefault = 2 * time.Second
 )
 
+func init() {
+	mustRegisterDatapath("ebpf", newEbpfFromConf)
+}
+
+// newEbpfFromConf validates the dataplane section of the config and creates an Ebpf datapath.
+func newEbpfFromConf(conf *Conf) (Datapath, error) {
+	if address := conf.DataplaneIface.Address; address != "" {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return nil, ErrInvalidArgumentWithReason("conf.DataplaneIface.Address", address, err.Error())
+		}
+	}
+
+	if interval := conf.DataplaneIface.HealthCheckInterval; interval != "" {
+		if d, err := time.ParseDuration(interval); err != nil || d <= 0 {
+			return nil, ErrInvalidArgumentWithReason("conf.DataplaneIface.HealthCheckInterval", interval,
+				"invalid duration")
+		}
+	}
+
+	return &Ebpf{}, nil
+}
+
+// Ebpf programs an external dataplane through the DataplaneControl gRPC service.
 type Ebpf struct {
-	conn *grpc.ClientConn
+	conn   *grpc.ClientConn
+	client pb.DataplaneControlClient
+
+	// state holds the DatapathState determined by the last health check.
+	state               int32
+	healthCheckInterval time.Duration
+
+	// ctx is canceled on Exit, stopping the health checks and the streams of dataplane events.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
+// IsConnected reports whether the last health check found the dataplane serving.
 func (d *Ebpf) IsConnected(accessIP *net.IP) bool {
-	// TODO(ardzoht): Add connection check for server to DP service
-	// Defaulting to true for now to test with PFCPsim
-	return true
+	return d.getState() == DatapathStateUp
+}
+
+func (d *Ebpf) getState() DatapathState {
+	return DatapathState(atomic.LoadInt32(&d.state))
 }
 
 func (d *Ebpf) Exit() {
 	log.Info("Shutting down datapath...")
+
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	if d.conn != nil {
+		if err := d.conn.Close(); err != nil {
+			log.Error("Failed to close dataplane connection: ", err)
+		}
+	}
 }
 
 // SetUpfInfo is only called at pfcp-agent's startup
 func (d *Ebpf) SetUpfInfo(u *Upf, conf *Conf) {
+	var err error
+
 	log.Info("Setting UPF config...")
+
+	address := conf.DataplaneIface.Address
+	if address == "" {
+		address = dataplaneAddrDefault
+	}
+
+	log.Info("Dataplane service address ", address)
+
+	d.conn, err = grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal("did not connect: ", err)
+	}
+
+	d.client = pb.NewDataplaneControlClient(d.conn)
+
+	d.healthCheckInterval = healthCheckIntervalDefault
+	if conf.DataplaneIface.HealthCheckInterval != "" {
+		d.healthCheckInterval, err = time.ParseDuration(conf.DataplaneIface.HealthCheckInterval)
+		if err != nil {
+			log.Fatal("Unable to parse health_check_interval")
+		}
+	}
+
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	go d.healthCheckLoop(u.DatapathStateChan)
+	go d.watchLoop("downlink data notifications", func(ctx context.Context) error {
+		return d.watchDownlinkData(ctx, u)
+	})
+	go d.watchLoop("usage counters", func(ctx context.Context) error {
+		return d.watchUsage(ctx, u)
+	})
+}
+
+// healthCheckLoop periodically queries the gRPC health service of the dataplane and reports
+// every liveness transition on stateChan.
+func (d *Ebpf) healthCheckLoop(stateChan chan<- DatapathState) {
+	client := healthpb.NewHealthClient(d.conn)
+
+	ticker := time.NewTicker(d.healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		d.checkHealth(client, stateChan)
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
-func (d *Ebpf) SendMsgToUPF(
-	method UpfMsgType, session PFCPSession, updated PacketForwardingRules) uint8 {
-	var cause uint8 = ie.CauseRequestAccepted
+func (d *Ebpf) checkHealth(client healthpb.HealthClient, stateChan chan<- DatapathState) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
 
-	pdrs := session.Pdrs
-	fars := session.Fars
-	qers := session.Qers
+	state := DatapathStateDown
 
-	if method == UpfMsgTypeMod {
-		pdrs = updated.Pdrs
-		fars = updated.Fars
-		qers = updated.Qers
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: pb.DataplaneControl_ServiceDesc.ServiceName})
+	if err == nil && resp.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+		state = DatapathStateUp
 	}
 
-	for _, pdr := range pdrs {
-		log.Debugf("%s %s", method, pdr)
+	old := DatapathState(atomic.SwapInt32(&d.state, int32(state)))
+	if old == state {
+		return
 	}
 
-	for _, far := range fars {
-		log.Debugf("%s %s", method, far)
+	if state == DatapathStateUp {
+		log.Infof("Dataplane state changed from %v to %v", old, state)
+	} else {
+		log.Warnf("Dataplane state changed from %v to %v: %v", old, state, err)
 	}
 
-	for _, qer := range qers {
-		log.Debugf("%s %s", method, qer)
+	// non-blocking write to channel
+	select {
+	case stateChan <- state:
+	default:
+		log.Warn("Dataplane state channel full, dropping transition to ", state)
 	}
+}
+
+// watchLoop runs watch, which consumes a stream of events of the dataplane, until Exit. The
+// stream is opened again every health check interval once it breaks, e.g. while the dataplane
+// is down, unless the dataplane doesn't implement it.
+func (d *Ebpf) watchLoop(name string, watch func(ctx context.Context) error) {
+	for {
+		err := watch(d.ctx)
+		if d.ctx.Err() != nil {
+			return
+		}
+
+		if status.Code(err) == codes.Unimplemented {
+			log.Warnf("Dataplane does not support %v: %v", name, err)
+			return
+		}
 
-	return cause
+		log.Debugf("Stream of %v ended: %v", name, err)
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(d.healthCheckInterval):
+		}
+	}
+}
+
+// watchDownlinkData reports the downlink data buffered by the dataplane to u, until the stream
+// of events ends.
+func (d *Ebpf) watchDownlinkData(ctx context.Context, u *Upf) error {
+	stream, err := d.client.WatchDownlinkData(ctx, &pb.WatchDownlinkDataRequest{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		u.NotifyDownlinkData(event.GetSeid())
+	}
+}
+
+// watchUsage reports the URR counters refreshed by the dataplane to u, until the stream of
+// counters ends.
+func (d *Ebpf) watchUsage(ctx context.Context, u *Upf) error {
+	stream, err := d.client.WatchUsage(ctx, &pb.WatchUsageRequest{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		counters, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		u.ReportUsage(counters.GetSeid(), counters.GetUrrId(), usageCountersFromPb(counters))
+	}
+}
+
+// dataplaneError wraps an error returned by the DataplaneControl service, translating its
+// status to a PFCP cause.
+func dataplaneError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &DatapathError{Cause: causeFromDataplaneError(err), Err: err}
+}
+
+func (d *Ebpf) CreatePDR(ctx context.Context, pdr Pdr) error {
+	_, err := d.client.CreatePdr(ctx, &pb.PdrRequest{Pdr: pdrToPb(pdr)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) ModifyPDR(ctx context.Context, pdr Pdr) error {
+	_, err := d.client.ModifyPdr(ctx, &pb.PdrRequest{Pdr: pdrToPb(pdr)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) DeletePDR(ctx context.Context, pdr Pdr) error {
+	_, err := d.client.DeletePdr(ctx, &pb.PdrRequest{Pdr: pdrToPb(pdr)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) CreateFAR(ctx context.Context, far Far) error {
+	_, err := d.client.CreateFar(ctx, &pb.FarRequest{Far: farToPb(far)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) ModifyFAR(ctx context.Context, far Far) error {
+	_, err := d.client.ModifyFar(ctx, &pb.FarRequest{Far: farToPb(far)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) DeleteFAR(ctx context.Context, far Far) error {
+	_, err := d.client.DeleteFar(ctx, &pb.FarRequest{Far: farToPb(far)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) CreateQER(ctx context.Context, qer Qer) error {
+	_, err := d.client.CreateQer(ctx, &pb.QerRequest{Qer: qerToPb(qer)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) ModifyQER(ctx context.Context, qer Qer) error {
+	_, err := d.client.ModifyQer(ctx, &pb.QerRequest{Qer: qerToPb(qer)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) DeleteQER(ctx context.Context, qer Qer) error {
+	_, err := d.client.DeleteQer(ctx, &pb.QerRequest{Qer: qerToPb(qer)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) CreateURR(ctx context.Context, urr Urr) error {
+	_, err := d.client.CreateUrr(ctx, &pb.UrrRequest{Urr: urrToPb(urr)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) ModifyURR(ctx context.Context, urr Urr) error {
+	_, err := d.client.ModifyUrr(ctx, &pb.UrrRequest{Urr: urrToPb(urr)})
+	return dataplaneError(err)
+}
+
+func (d *Ebpf) DeleteURR(ctx context.Context, urr Urr) error {
+	_, err := d.client.DeleteUrr(ctx, &pb.UrrRequest{Urr: urrToPb(urr)})
+	return dataplaneError(err)
+}
+
+// ReadUsage reads the current counters of urr from the dataplane.
+func (d *Ebpf) ReadUsage(ctx context.Context, urr Urr) (UsageCounters, error) {
+	counters, err := d.client.ReadUrr(ctx, &pb.UrrRequest{Urr: urrToPb(urr)})
+	if err != nil {
+		return UsageCounters{}, dataplaneError(err)
+	}
+
+	return usageCountersFromPb(counters), nil
+}
+
+// causeFromDataplaneError maps the gRPC status returned by the dataplane to a PFCP cause.
+func causeFromDataplaneError(err error) uint8 {
+	switch status.Code(err) {
+	case codes.OK:
+		return ie.CauseRequestAccepted
+	case codes.InvalidArgument, codes.AlreadyExists, codes.FailedPrecondition, codes.OutOfRange:
+		return ie.CauseRuleCreationModificationFailure
+	case codes.NotFound:
+		return ie.CauseSessionContextNotFound
+	case codes.ResourceExhausted:
+		return ie.CauseNoResourcesAvailable
+	case codes.Unimplemented:
+		return ie.CauseServiceNotSupported
+	case codes.DeadlineExceeded:
+		return ie.CausePFCPEntityInCongestion
+	case codes.Unavailable, codes.Internal, codes.DataLoss:
+		return ie.CauseSystemFailure
+	default:
+		return ie.CauseRequestRejected
+	}
 }
 
 func (d *Ebpf) AddSliceInfo(sliceInfo *SliceInfo) error {
@@ -0,0 +1,338 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/ardzoht/omec-upf/pfcpiface/dataplane_pb"
+	"github.com/ardzoht/omec-upf/pfcpiface/fake_dataplane"
+)
+
+func newTestEbpf(t *testing.T) (*Ebpf, *fake_dataplane.FakeDataplane) {
+	d, fdp, _ := newTestEbpfWithUpf(t)
+
+	return d, fdp
+}
+
+func newTestEbpfWithUpf(t *testing.T) (*Ebpf, *fake_dataplane.FakeDataplane, *Upf) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	fdp := fake_dataplane.NewFakeDataplane()
+
+	go func() {
+		_ = fdp.Serve(listener)
+	}()
+
+	upf := &Upf{
+		ReportNotifyChan:  make(chan uint64, 16),
+		UsageReportChan:   make(chan UsageEvent, 16),
+		DatapathStateChan: make(chan DatapathState, 16),
+	}
+	upf.ddnNotifier = NewDownlinkDataNotifier(upf.ReportNotifyChan, time.Hour)
+
+	d := &Ebpf{}
+	d.SetUpfInfo(upf, &Conf{DataplaneIface: DataplaneInfo{
+		Address:             listener.Addr().String(),
+		HealthCheckInterval: "50ms",
+	}})
+
+	t.Cleanup(func() {
+		d.Exit()
+		fdp.Stop()
+	})
+
+	return d, fdp, upf
+}
+
+func waitDatapathState(t *testing.T, stateChan chan DatapathState, want DatapathState) {
+	select {
+	case got := <-stateChan:
+		require.Equal(t, want, got)
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for datapath state %v", want)
+	}
+}
+
+func newTestSession(seid uint64) PFCPSession {
+	return PFCPSession{
+		localSEID:  seid,
+		remoteSEID: seid,
+		PacketForwardingRules: PacketForwardingRules{
+			Pdrs: []Pdr{
+				{PdrID: 1, FseID: seid, SrcIface: access, TunnelTEID: 0x10, FarID: 1, QerIDList: []uint32{1}},
+				{PdrID: 2, FseID: seid, SrcIface: core, UeAddress: ip2int(net.ParseIP("10.0.0.1")), FarID: 2},
+			},
+			Fars: []Far{
+				{FarID: 1, FseID: seid, ApplyAction: ActionForward, DstIntf: ie.DstInterfaceCore},
+				{FarID: 2, FseID: seid, ApplyAction: ActionForward, DstIntf: ie.DstInterfaceAccess, TunnelTEID: 0x20},
+			},
+			Qers: []Qer{
+				{QerID: 1, FseID: seid, QosLevel: SessionQos, UlMbr: 1000, DlMbr: 2000},
+			},
+			Urrs: []Urr{
+				{UrrID: 1, FseID: seid, VolThreshold: VolumeData{TotalVol: 100}},
+			},
+		},
+	}
+}
+
+func commitTestTransaction(t *testing.T, dp Datapath, build func(tx *Transaction)) error {
+	tx := NewTransaction(dp)
+	build(tx)
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	return tx.Commit(ctx)
+}
+
+func TestEbpf_RuleOperations(t *testing.T) {
+	d, fdp := newTestEbpf(t)
+	session := newTestSession(1)
+
+	err := commitTestTransaction(t, d, func(tx *Transaction) { tx.CreateRules(session.PacketForwardingRules) })
+	require.NoError(t, err)
+
+	pdrs := fdp.GetPdrs()
+	require.Len(t, pdrs, 2)
+	require.Equal(t, uint32(0x10), pdrs[fake_dataplane.RuleKey{SEID: 1, ID: 1}].TunnelTeid)
+	require.Equal(t, []uint32{1}, pdrs[fake_dataplane.RuleKey{SEID: 1, ID: 1}].QerIds)
+	require.Len(t, fdp.GetFars(), 2)
+	require.Len(t, fdp.GetQers(), 1)
+	require.Len(t, fdp.GetUrrs(), 1)
+
+	updatedFar := session.Fars[1]
+	updatedFar.TunnelTEID = 0x30
+	err = commitTestTransaction(t, d, func(tx *Transaction) { tx.ModifyFAR(session.Fars[1], updatedFar) })
+	require.NoError(t, err)
+	require.Equal(t, uint32(0x30), fdp.GetFars()[fake_dataplane.RuleKey{SEID: 1, ID: 2}].TunnelTeid)
+
+	err = commitTestTransaction(t, d, func(tx *Transaction) { tx.DeleteRules(session.PacketForwardingRules) })
+	require.NoError(t, err)
+	require.Empty(t, fdp.GetPdrs())
+	require.Empty(t, fdp.GetFars())
+	require.Empty(t, fdp.GetQers())
+	require.Empty(t, fdp.GetUrrs())
+}
+
+func TestEbpf_NetworkInstance(t *testing.T) {
+	d, fdp := newTestEbpf(t)
+	session := newTestSession(1)
+	session.Pdrs[0].NetworkInstance = "enterprise"
+	session.Fars[0].NetworkInstance = "internet"
+
+	err := commitTestTransaction(t, d, func(tx *Transaction) { tx.CreateRules(session.PacketForwardingRules) })
+	require.NoError(t, err)
+
+	require.Equal(t, "enterprise", fdp.GetPdrs()[fake_dataplane.RuleKey{SEID: 1, ID: 1}].NetworkInstance)
+	require.Empty(t, fdp.GetPdrs()[fake_dataplane.RuleKey{SEID: 1, ID: 2}].NetworkInstance)
+	require.Equal(t, "internet", fdp.GetFars()[fake_dataplane.RuleKey{SEID: 1, ID: 1}].NetworkInstance)
+
+	updatedFar := session.Fars[0]
+	updatedFar.NetworkInstance = "enterprise"
+	err = commitTestTransaction(t, d, func(tx *Transaction) { tx.ModifyFAR(session.Fars[0], updatedFar) })
+	require.NoError(t, err)
+	require.Equal(t, "enterprise", fdp.GetFars()[fake_dataplane.RuleKey{SEID: 1, ID: 1}].NetworkInstance)
+}
+
+func TestEbpf_RuleOperationErrors(t *testing.T) {
+	t.Run("duplicate rule", func(t *testing.T) {
+		d, fdp := newTestEbpf(t)
+		session := newTestSession(1)
+
+		create := func(tx *Transaction) { tx.CreateRules(session.PacketForwardingRules) }
+		require.NoError(t, commitTestTransaction(t, d, create))
+
+		err := commitTestTransaction(t, d, create)
+		require.Equal(t, ie.CauseRuleCreationModificationFailure, causeOf(t, err))
+		// The rules installed by the first transaction are untouched
+		require.Len(t, fdp.GetPdrs(), 2)
+		require.Len(t, fdp.GetFars(), 2)
+	})
+
+	t.Run("unknown rule", func(t *testing.T) {
+		d, _ := newTestEbpf(t)
+		session := newTestSession(1)
+
+		err := commitTestTransaction(t, d, func(tx *Transaction) { tx.ModifyPDR(session.Pdrs[0], session.Pdrs[0]) })
+		require.Equal(t, ie.CauseSessionContextNotFound, causeOf(t, err))
+	})
+
+	t.Run("dataplane out of resources", func(t *testing.T) {
+		d, fdp := newTestEbpf(t)
+		session := newTestSession(1)
+
+		fdp.InjectError("CreatePdr", codes.ResourceExhausted)
+
+		err := commitTestTransaction(t, d, func(tx *Transaction) { tx.CreateRules(session.PacketForwardingRules) })
+		require.Equal(t, ie.CauseNoResourcesAvailable, causeOf(t, err))
+		// FARs, QERs and URRs installed before the failing PDR are rolled back
+		require.Empty(t, fdp.GetPdrs())
+		require.Empty(t, fdp.GetFars())
+		require.Empty(t, fdp.GetQers())
+		require.Empty(t, fdp.GetUrrs())
+	})
+}
+
+func TestEbpf_IPv6Rules(t *testing.T) {
+	d, fdp := newTestEbpf(t)
+	session := newTestSession(1)
+
+	uplink := &session.Pdrs[0]
+	uplink.TunnelIP6Dst = net.ParseIP("2001:db8:10::1")
+	uplink.AppFilter.DstIP6 = net.ParseIP("2001:db8:20::")
+	uplink.AppFilter.DstIP6PrefixLen = 48
+
+	downlink := &session.Pdrs[1]
+	downlink.UeAddress6 = net.ParseIP("2001:db8:1:2::")
+	downlink.UeAddress6PrefixLen = 64
+
+	session.Fars[1].TunnelIP6Src = net.ParseIP("2001:db8:10::1")
+	session.Fars[1].TunnelIP6Dst = net.ParseIP("2001:db8:30::2")
+
+	err := commitTestTransaction(t, d, func(tx *Transaction) { tx.CreateRules(session.PacketForwardingRules) })
+	require.NoError(t, err)
+
+	pdrs := fdp.GetPdrs()
+
+	pdr := pdrs[fake_dataplane.RuleKey{SEID: 1, ID: 1}]
+	require.Equal(t, []byte(net.ParseIP("2001:db8:10::1")), pdr.TunnelIpv6Dst)
+	require.Equal(t, []byte(net.ParseIP("2001:db8:20::")), pdr.AppFilter.DstIp6)
+	require.Equal(t, uint32(48), pdr.AppFilter.DstIp6PrefixLen)
+	require.Empty(t, pdr.AppFilter.SrcIp6)
+	require.Empty(t, pdr.UeAddress6)
+
+	pdr = pdrs[fake_dataplane.RuleKey{SEID: 1, ID: 2}]
+	require.Equal(t, []byte(net.ParseIP("2001:db8:1:2::")), pdr.UeAddress6)
+	require.Equal(t, uint32(64), pdr.UeAddress6PrefixLen)
+	require.Empty(t, pdr.TunnelIpv6Dst)
+
+	far := fdp.GetFars()[fake_dataplane.RuleKey{SEID: 1, ID: 2}]
+	require.Equal(t, []byte(net.ParseIP("2001:db8:10::1")), far.TunnelIpv6Src)
+	require.Equal(t, []byte(net.ParseIP("2001:db8:30::2")), far.TunnelIpv6Dst)
+	require.Empty(t, fdp.GetFars()[fake_dataplane.RuleKey{SEID: 1, ID: 1}].TunnelIpv6Dst)
+}
+
+func TestEbpf_HealthCheck(t *testing.T) {
+	d, fdp, upf := newTestEbpfWithUpf(t)
+
+	waitDatapathState(t, upf.DatapathStateChan, DatapathStateUp)
+	require.True(t, d.IsConnected(nil))
+
+	fdp.SetServing(false)
+	waitDatapathState(t, upf.DatapathStateChan, DatapathStateDown)
+	require.False(t, d.IsConnected(nil))
+
+	fdp.SetServing(true)
+	waitDatapathState(t, upf.DatapathStateChan, DatapathStateUp)
+	require.True(t, d.IsConnected(nil))
+}
+
+func TestEbpf_DownlinkDataNotification(t *testing.T) {
+	_, fdp, upf := newTestEbpfWithUpf(t)
+
+	// Events are lost until the agent watches them
+	require.Eventually(t, func() bool {
+		fdp.BufferDownlinkData(7)
+
+		select {
+		case seid := <-upf.ReportNotifyChan:
+			require.Equal(t, uint64(7), seid)
+			return true
+		default:
+			return false
+		}
+	}, 2*time.Second, 50*time.Millisecond)
+
+	// Notifications are rate limited
+	fdp.BufferDownlinkData(7)
+	require.Never(t, func() bool { return len(upf.ReportNotifyChan) > 0 }, 200*time.Millisecond, 50*time.Millisecond)
+}
+
+func TestEbpf_Usage(t *testing.T) {
+	d, fdp, upf := newTestEbpfWithUpf(t)
+	session := newTestSession(1)
+
+	err := commitTestTransaction(t, d, func(tx *Transaction) { tx.CreateRules(session.PacketForwardingRules) })
+	require.NoError(t, err)
+
+	counters := &pb.UrrCounters{UrrId: 1, Seid: 1, UplinkPackets: 1, DownlinkPackets: 2, UplinkBytes: 100, DownlinkBytes: 200}
+	want := UsageCounters{UplinkPackets: 1, DownlinkPackets: 2, UplinkBytes: 100, DownlinkBytes: 200}
+
+	fdp.SetUrrCounters(counters)
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	got, err := d.ReadUsage(ctx, session.Urrs[0])
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	missing := session.Urrs[0]
+	missing.UrrID = 9
+	_, err = d.ReadUsage(ctx, missing)
+	require.Equal(t, ie.CauseSessionContextNotFound, causeOf(t, err))
+
+	// Counters refreshed by the dataplane are reported, once the agent watches them
+	require.Eventually(t, func() bool {
+		fdp.SetUrrCounters(counters)
+
+		select {
+		case event := <-upf.UsageReportChan:
+			require.Equal(t, UsageEvent{FseID: 1, UrrID: 1, Counters: want}, event)
+			return true
+		default:
+			return false
+		}
+	}, 2*time.Second, 50*time.Millisecond)
+}
+
+func TestEbpf_HealthCheckUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	// Nothing serves on this address
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	d := &Ebpf{}
+	d.SetUpfInfo(&Upf{}, &Conf{DataplaneIface: DataplaneInfo{Address: address, HealthCheckInterval: "50ms"}})
+	t.Cleanup(d.Exit)
+
+	require.Never(t, func() bool { return d.IsConnected(nil) }, 300*time.Millisecond, 50*time.Millisecond)
+}
+
+func Test_causeFromDataplaneError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want uint8
+	}{
+		{name: "no error", err: nil, want: ie.CauseRequestAccepted},
+		{name: "invalid rule", err: status.Error(codes.InvalidArgument, ""), want: ie.CauseRuleCreationModificationFailure},
+		{name: "rule exists", err: status.Error(codes.AlreadyExists, ""), want: ie.CauseRuleCreationModificationFailure},
+		{name: "rule not found", err: status.Error(codes.NotFound, ""), want: ie.CauseSessionContextNotFound},
+		{name: "table full", err: status.Error(codes.ResourceExhausted, ""), want: ie.CauseNoResourcesAvailable},
+		{name: "unsupported", err: status.Error(codes.Unimplemented, ""), want: ie.CauseServiceNotSupported},
+		{name: "timeout", err: status.Error(codes.DeadlineExceeded, ""), want: ie.CausePFCPEntityInCongestion},
+		{name: "dataplane down", err: status.Error(codes.Unavailable, ""), want: ie.CauseSystemFailure},
+		{name: "non gRPC error", err: errors.New("boom"), want: ie.CauseRequestRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, causeFromDataplaneError(tt.err))
+		})
+	}
+}
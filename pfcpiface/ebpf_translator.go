@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+
+	pb "github.com/ardzoht/omec-upf/pfcpiface/dataplane_pb"
+)
+
+// Translation of PFCP rules to messages of the DataplaneControl gRPC service.
+
+// ip6ToPb returns the 16 bytes of an IPv6 address, nil if there is none.
+func ip6ToPb(ip net.IP) []byte {
+	if ip == nil {
+		return nil
+	}
+
+	return ip.To16()
+}
+
+func portRangeToPb(pr PortRange) *pb.PortRange {
+	return &pb.PortRange{
+		Low:  uint32(pr.low),
+		High: uint32(pr.high),
+	}
+}
+
+func appFilterToPb(af ApplicationFilter) *pb.ApplicationFilter {
+	return &pb.ApplicationFilter{
+		FilterId:     af.FilterID,
+		SrcIp:        af.SrcIP,
+		SrcIpMask:    af.SrcIPMask,
+		DstIp:        af.DstIP,
+		DstIpMask:    af.DstIPMask,
+		SrcPortRange: portRangeToPb(af.SrcPortRange),
+		DstPortRange: portRangeToPb(af.DstPortRange),
+		Proto:        uint32(af.Proto),
+		ProtoMask:    uint32(af.ProtoMask),
+
+		SrcIp6:          ip6ToPb(af.SrcIP6),
+		SrcIp6PrefixLen: uint32(af.SrcIP6PrefixLen),
+		DstIp6:          ip6ToPb(af.DstIP6),
+		DstIp6PrefixLen: uint32(af.DstIP6PrefixLen),
+	}
+}
+
+func pdrToPb(p Pdr) *pb.Pdr {
+	return &pb.Pdr{
+		PdrId:             p.PdrID,
+		Seid:              p.FseID,
+		FseidIp:           p.FseidIP,
+		SrcIface:          uint32(p.SrcIface),
+		SrcIfaceMask:      uint32(p.SrcIfaceMask),
+		TunnelIpv4Dst:     p.TunnelIP4Dst,
+		TunnelIpv4DstMask: p.TunnelIP4DstMask,
+		TunnelTeid:        p.TunnelTEID,
+		TunnelTeidMask:    p.TunnelTEIDMask,
+		UeAddress:         p.UeAddress,
+		AppFilter:         appFilterToPb(p.AppFilter),
+		Precedence:        p.Precedence,
+		CtrId:             p.CtrID,
+		FarId:             p.FarID,
+		QerIds:            append([]uint32{}, p.QerIDList...),
+		NeedDecap:         p.NeedDecap != 0,
+
+		TunnelIpv6Dst:       ip6ToPb(p.TunnelIP6Dst),
+		UeAddress6:          ip6ToPb(p.UeAddress6),
+		UeAddress6PrefixLen: uint32(p.UeAddress6PrefixLen),
+
+		NetworkInstance: p.NetworkInstance,
+	}
+}
+
+func farToPb(f Far) *pb.Far {
+	return &pb.Far{
+		FarId:         f.FarID,
+		Seid:          f.FseID,
+		FseidIp:       f.FseidIP,
+		DstIntf:       uint32(f.DstIntf),
+		ApplyAction:   uint32(f.ApplyAction),
+		SendEndMarker: f.SendEndMarker,
+		TunnelType:    uint32(f.TunnelType),
+		TunnelIpv4Src: f.TunnelIP4Src,
+		TunnelIpv4Dst: f.TunnelIP4Dst,
+		TunnelTeid:    f.TunnelTEID,
+		TunnelPort:    uint32(f.TunnelPort),
+
+		TunnelIpv6Src: ip6ToPb(f.TunnelIP6Src),
+		TunnelIpv6Dst: ip6ToPb(f.TunnelIP6Dst),
+
+		NetworkInstance: f.NetworkInstance,
+	}
+}
+
+func qerToPb(q Qer) *pb.Qer {
+	qosLevel := pb.QosLevel_QOS_LEVEL_APPLICATION
+	if q.QosLevel == SessionQos {
+		qosLevel = pb.QosLevel_QOS_LEVEL_SESSION
+	}
+
+	return &pb.Qer{
+		QerId:    q.QerID,
+		Seid:     q.FseID,
+		FseidIp:  q.FseidIP,
+		QosLevel: qosLevel,
+		Qfi:      uint32(q.Qfi),
+		UlStatus: uint32(q.UlStatus),
+		DlStatus: uint32(q.DlStatus),
+		UlMbr:    q.UlMbr,
+		DlMbr:    q.DlMbr,
+		UlGbr:    q.UlGbr,
+		DlGbr:    q.DlGbr,
+	}
+}
+
+func volumeDataToPb(v VolumeData) *pb.VolumeData {
+	return &pb.VolumeData{
+		Flags:          uint32(v.Flags),
+		TotalVolume:    v.TotalVol,
+		UplinkVolume:   v.UplinkVol,
+		DownlinkVolume: v.DownlinkVol,
+	}
+}
+
+func urrToPb(u Urr) *pb.Urr {
+	return &pb.Urr{
+		UrrId:             u.UrrID,
+		Seid:              u.FseID,
+		FseidIp:           u.FseidIP,
+		CtrId:             u.CtrID,
+		PdrId:             u.PdrID,
+		MeasurementMethod: uint32(u.MeasureMethod),
+		ReportOpen:        u.ReportOpen,
+		ReportingTriggers: uint32(u.Trigger.Flags),
+		LocalThreshold:    u.LocalThreshold,
+		VolumeThreshold:   volumeDataToPb(u.VolThreshold),
+		VolumeQuota:       volumeDataToPb(u.VolQuota),
+	}
+}
+
+func usageCountersFromPb(c *pb.UrrCounters) UsageCounters {
+	return UsageCounters{
+		UplinkPackets:   c.GetUplinkPackets(),
+		DownlinkPackets: c.GetDownlinkPackets(),
+		UplinkBytes:     c.GetUplinkBytes(),
+		DownlinkBytes:   c.GetDownlinkBytes(),
+	}
+}
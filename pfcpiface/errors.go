@@ -13,8 +13,13 @@ var (
 	errInvalidOperation = errors.New("invalid operation")
 	errFailed           = errors.New("failed")
 	errUnsupported      = errors.New("unsupported")
+	errExhausted        = errors.New("exhausted")
 )
 
+func ErrExhausted(what string) error {
+	return fmt.Errorf("%s %w", what, errExhausted)
+}
+
 func ErrUnsupported(what string, value interface{}) error {
 	return fmt.Errorf("%s=%v %w", what, value, errUnsupported)
 }
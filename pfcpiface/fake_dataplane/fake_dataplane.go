@@ -0,0 +1,432 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package fake_dataplane
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/ardzoht/omec-upf/pfcpiface/dataplane_pb"
+)
+
+// RuleKey identifies a rule of a given type installed in the fake dataplane.
+type RuleKey struct {
+	SEID uint64
+	ID   uint32
+}
+
+type FakeDataplane struct {
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+	service      *fakeDataplaneService
+}
+
+// NewFakeDataplane creates a new in-process reference implementation of the DataplaneControl
+// gRPC service. It keeps track of the installed rules instead of programming any hardware, so
+// the whole control path can be exercised in unit tests.
+func NewFakeDataplane() *FakeDataplane {
+	d := &FakeDataplane{
+		healthServer: health.NewServer(),
+		service:      newFakeDataplaneService(),
+	}
+	d.SetServing(true)
+
+	return d
+}
+
+// Run starts and runs the gRPC server on the given address. Blocking until Stop is called.
+func (d *FakeDataplane) Run(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return d.Serve(listener)
+}
+
+// Serve runs the gRPC server on an existing listener. Blocking until Stop is called.
+func (d *FakeDataplane) Serve(listener net.Listener) error {
+	d.grpcServer = grpc.NewServer()
+	pb.RegisterDataplaneControlServer(d.grpcServer, d.service)
+	healthpb.RegisterHealthServer(d.grpcServer, d.healthServer)
+
+	// Blocking
+	return d.grpcServer.Serve(listener)
+}
+
+// Stop the gRPC server.
+func (d *FakeDataplane) Stop() {
+	d.grpcServer.Stop()
+}
+
+// SetServing sets the status reported by the gRPC health service of the fake dataplane.
+func (d *FakeDataplane) SetServing(serving bool) {
+	st := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		st = healthpb.HealthCheckResponse_SERVING
+	}
+
+	d.healthServer.SetServingStatus(pb.DataplaneControl_ServiceDesc.ServiceName, st)
+}
+
+// InjectError makes the next call to the given RPC (e.g. "CreatePdr") fail with code.
+func (d *FakeDataplane) InjectError(method string, code codes.Code) {
+	d.service.mu.Lock()
+	defer d.service.mu.Unlock()
+
+	d.service.injectedErrors[method] = code
+}
+
+// BufferDownlinkData reports downlink packets buffered for the session with local SEID seid to
+// the PFCP agents watching downlink data.
+func (d *FakeDataplane) BufferDownlinkData(seid uint64) {
+	d.service.mu.Lock()
+	defer d.service.mu.Unlock()
+
+	for watcher := range d.service.ddnWatchers {
+		// non-blocking write to channel, like a dataplane dropping events of slow watchers
+		select {
+		case watcher <- &pb.DownlinkDataEvent{Seid: seid}:
+		default:
+		}
+	}
+}
+
+// SetUrrCounters sets the counters of a URR, as if the dataplane refreshed them, and sends them to
+// the PFCP agents watching usage.
+func (d *FakeDataplane) SetUrrCounters(counters *pb.UrrCounters) {
+	d.service.mu.Lock()
+	defer d.service.mu.Unlock()
+
+	d.service.counters[RuleKey{SEID: counters.Seid, ID: counters.UrrId}] = proto.Clone(counters).(*pb.UrrCounters)
+
+	for watcher := range d.service.usageWatchers {
+		// non-blocking write to channel, like a dataplane dropping events of slow watchers
+		select {
+		case watcher <- proto.Clone(counters).(*pb.UrrCounters):
+		default:
+		}
+	}
+}
+
+func (d *FakeDataplane) GetPdrs() map[RuleKey]*pb.Pdr {
+	d.service.mu.Lock()
+	defer d.service.mu.Unlock()
+
+	entries := make(map[RuleKey]*pb.Pdr)
+	for k, v := range d.service.pdrs {
+		entries[k] = proto.Clone(v).(*pb.Pdr)
+	}
+
+	return entries
+}
+
+func (d *FakeDataplane) GetFars() map[RuleKey]*pb.Far {
+	d.service.mu.Lock()
+	defer d.service.mu.Unlock()
+
+	entries := make(map[RuleKey]*pb.Far)
+	for k, v := range d.service.fars {
+		entries[k] = proto.Clone(v).(*pb.Far)
+	}
+
+	return entries
+}
+
+func (d *FakeDataplane) GetQers() map[RuleKey]*pb.Qer {
+	d.service.mu.Lock()
+	defer d.service.mu.Unlock()
+
+	entries := make(map[RuleKey]*pb.Qer)
+	for k, v := range d.service.qers {
+		entries[k] = proto.Clone(v).(*pb.Qer)
+	}
+
+	return entries
+}
+
+func (d *FakeDataplane) GetUrrs() map[RuleKey]*pb.Urr {
+	d.service.mu.Lock()
+	defer d.service.mu.Unlock()
+
+	entries := make(map[RuleKey]*pb.Urr)
+	for k, v := range d.service.urrs {
+		entries[k] = proto.Clone(v).(*pb.Urr)
+	}
+
+	return entries
+}
+
+type fakeDataplaneService struct {
+	pb.UnimplementedDataplaneControlServer
+
+	mu             sync.Mutex
+	pdrs           map[RuleKey]*pb.Pdr
+	fars           map[RuleKey]*pb.Far
+	qers           map[RuleKey]*pb.Qer
+	urrs           map[RuleKey]*pb.Urr
+	injectedErrors map[string]codes.Code
+	counters       map[RuleKey]*pb.UrrCounters
+	ddnWatchers    map[chan *pb.DownlinkDataEvent]struct{}
+	usageWatchers  map[chan *pb.UrrCounters]struct{}
+}
+
+func newFakeDataplaneService() *fakeDataplaneService {
+	return &fakeDataplaneService{
+		pdrs:           make(map[RuleKey]*pb.Pdr),
+		fars:           make(map[RuleKey]*pb.Far),
+		qers:           make(map[RuleKey]*pb.Qer),
+		urrs:           make(map[RuleKey]*pb.Urr),
+		injectedErrors: make(map[string]codes.Code),
+		counters:       make(map[RuleKey]*pb.UrrCounters),
+		ddnWatchers:    make(map[chan *pb.DownlinkDataEvent]struct{}),
+		usageWatchers:  make(map[chan *pb.UrrCounters]struct{}),
+	}
+}
+
+// checkInjectedError must be called with the lock held.
+func (s *fakeDataplaneService) checkInjectedError(method string) error {
+	code, ok := s.injectedErrors[method]
+	if !ok {
+		return nil
+	}
+
+	delete(s.injectedErrors, method)
+
+	return status.Errorf(code, "injected error for %s", method)
+}
+
+// apply performs a create, modify or delete operation on a rule table. Tables are passed as
+// closures so that the same validation is shared by all rule types.
+func (s *fakeDataplaneService) apply(method string, op string, key RuleKey,
+	exists func() bool, store func(), remove func()) (*pb.RuleResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkInjectedError(method); err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "create":
+		if exists() {
+			return nil, status.Errorf(codes.AlreadyExists, "%s: rule %v already exists", method, key)
+		}
+
+		store()
+	case "modify":
+		if !exists() {
+			return nil, status.Errorf(codes.NotFound, "%s: rule %v not found", method, key)
+		}
+
+		store()
+	case "delete":
+		if !exists() {
+			return nil, status.Errorf(codes.NotFound, "%s: rule %v not found", method, key)
+		}
+
+		remove()
+	}
+
+	return &pb.RuleResponse{}, nil
+}
+
+func (s *fakeDataplaneService) applyPdr(method, op string, req *pb.PdrRequest) (*pb.RuleResponse, error) {
+	if req.GetPdr() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: missing PDR", method)
+	}
+
+	key := RuleKey{SEID: req.Pdr.Seid, ID: req.Pdr.PdrId}
+
+	return s.apply(method, op, key,
+		func() bool { _, ok := s.pdrs[key]; return ok },
+		func() { s.pdrs[key] = proto.Clone(req.Pdr).(*pb.Pdr) },
+		func() { delete(s.pdrs, key) },
+	)
+}
+
+func (s *fakeDataplaneService) applyFar(method, op string, req *pb.FarRequest) (*pb.RuleResponse, error) {
+	if req.GetFar() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: missing FAR", method)
+	}
+
+	key := RuleKey{SEID: req.Far.Seid, ID: req.Far.FarId}
+
+	return s.apply(method, op, key,
+		func() bool { _, ok := s.fars[key]; return ok },
+		func() { s.fars[key] = proto.Clone(req.Far).(*pb.Far) },
+		func() { delete(s.fars, key) },
+	)
+}
+
+func (s *fakeDataplaneService) applyQer(method, op string, req *pb.QerRequest) (*pb.RuleResponse, error) {
+	if req.GetQer() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: missing QER", method)
+	}
+
+	key := RuleKey{SEID: req.Qer.Seid, ID: req.Qer.QerId}
+
+	return s.apply(method, op, key,
+		func() bool { _, ok := s.qers[key]; return ok },
+		func() { s.qers[key] = proto.Clone(req.Qer).(*pb.Qer) },
+		func() { delete(s.qers, key) },
+	)
+}
+
+func (s *fakeDataplaneService) applyUrr(method, op string, req *pb.UrrRequest) (*pb.RuleResponse, error) {
+	if req.GetUrr() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: missing URR", method)
+	}
+
+	key := RuleKey{SEID: req.Urr.Seid, ID: req.Urr.UrrId}
+
+	return s.apply(method, op, key,
+		func() bool { _, ok := s.urrs[key]; return ok },
+		func() { s.urrs[key] = proto.Clone(req.Urr).(*pb.Urr) },
+		func() { delete(s.urrs, key); delete(s.counters, key) },
+	)
+}
+
+func (s *fakeDataplaneService) CreatePdr(_ context.Context, req *pb.PdrRequest) (*pb.RuleResponse, error) {
+	return s.applyPdr("CreatePdr", "create", req)
+}
+
+func (s *fakeDataplaneService) ModifyPdr(_ context.Context, req *pb.PdrRequest) (*pb.RuleResponse, error) {
+	return s.applyPdr("ModifyPdr", "modify", req)
+}
+
+func (s *fakeDataplaneService) DeletePdr(_ context.Context, req *pb.PdrRequest) (*pb.RuleResponse, error) {
+	return s.applyPdr("DeletePdr", "delete", req)
+}
+
+func (s *fakeDataplaneService) CreateFar(_ context.Context, req *pb.FarRequest) (*pb.RuleResponse, error) {
+	return s.applyFar("CreateFar", "create", req)
+}
+
+func (s *fakeDataplaneService) ModifyFar(_ context.Context, req *pb.FarRequest) (*pb.RuleResponse, error) {
+	return s.applyFar("ModifyFar", "modify", req)
+}
+
+func (s *fakeDataplaneService) DeleteFar(_ context.Context, req *pb.FarRequest) (*pb.RuleResponse, error) {
+	return s.applyFar("DeleteFar", "delete", req)
+}
+
+func (s *fakeDataplaneService) CreateQer(_ context.Context, req *pb.QerRequest) (*pb.RuleResponse, error) {
+	return s.applyQer("CreateQer", "create", req)
+}
+
+func (s *fakeDataplaneService) ModifyQer(_ context.Context, req *pb.QerRequest) (*pb.RuleResponse, error) {
+	return s.applyQer("ModifyQer", "modify", req)
+}
+
+func (s *fakeDataplaneService) DeleteQer(_ context.Context, req *pb.QerRequest) (*pb.RuleResponse, error) {
+	return s.applyQer("DeleteQer", "delete", req)
+}
+
+func (s *fakeDataplaneService) CreateUrr(_ context.Context, req *pb.UrrRequest) (*pb.RuleResponse, error) {
+	return s.applyUrr("CreateUrr", "create", req)
+}
+
+func (s *fakeDataplaneService) ModifyUrr(_ context.Context, req *pb.UrrRequest) (*pb.RuleResponse, error) {
+	return s.applyUrr("ModifyUrr", "modify", req)
+}
+
+func (s *fakeDataplaneService) DeleteUrr(_ context.Context, req *pb.UrrRequest) (*pb.RuleResponse, error) {
+	return s.applyUrr("DeleteUrr", "delete", req)
+}
+
+func (s *fakeDataplaneService) WatchDownlinkData(_ *pb.WatchDownlinkDataRequest,
+	stream pb.DataplaneControl_WatchDownlinkDataServer) error {
+	events := make(chan *pb.DownlinkDataEvent, 64)
+
+	s.mu.Lock()
+
+	if err := s.checkInjectedError("WatchDownlinkData"); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	s.ddnWatchers[events] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.ddnWatchers, events)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *fakeDataplaneService) ReadUrr(_ context.Context, req *pb.UrrRequest) (*pb.UrrCounters, error) {
+	if req.GetUrr() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "ReadUrr: missing URR")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkInjectedError("ReadUrr"); err != nil {
+		return nil, err
+	}
+
+	key := RuleKey{SEID: req.Urr.Seid, ID: req.Urr.UrrId}
+	if _, ok := s.urrs[key]; !ok {
+		return nil, status.Errorf(codes.NotFound, "ReadUrr: rule %v not found", key)
+	}
+
+	if counters, ok := s.counters[key]; ok {
+		return proto.Clone(counters).(*pb.UrrCounters), nil
+	}
+
+	return &pb.UrrCounters{UrrId: key.ID, Seid: key.SEID}, nil
+}
+
+func (s *fakeDataplaneService) WatchUsage(_ *pb.WatchUsageRequest, stream pb.DataplaneControl_WatchUsageServer) error {
+	updates := make(chan *pb.UrrCounters, 64)
+
+	s.mu.Lock()
+
+	if err := s.checkInjectedError("WatchUsage"); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	s.usageWatchers[updates] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.usageWatchers, updates)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case counters := <-updates:
+			if err := stream.Send(counters); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,427 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+const (
+	fileStorePrefix = "sessions-"
+	fileStoreSuffix = ".log"
+
+	// fileStoreCompactThreshold is the number of stale records tolerated in the log before it
+	// is compacted.
+	fileStoreCompactThreshold = 1024
+	// fileStoreMaxRecordSize bounds the size of a single record, i.e. of a serialized session.
+	fileStoreMaxRecordSize = 4 * 1024 * 1024
+)
+
+const (
+	fileStoreOpPut    = "put"
+	fileStoreOpDelete = "delete"
+	fileStoreOpClear  = "clear"
+)
+
+// storedSession is the on-disk representation of a PFCP session. Allocations made by the UPF
+// (TEIDs and UE IPs) are not stored separately: they are recorded in the PDRs of the session.
+type storedSession struct {
+	LocalSEID  uint64   `json:"local_seid"`
+	RemoteSEID uint64   `json:"remote_seid"`
+	RemoteIP   net.IP   `json:"remote_ip"`
+	CPFQCSID   *fqCSID  `json:"cp_fq_csid,omitempty"`
+	UeAddress  uint32   `json:"ue_address"`
+	UeAddress6 net.IP   `json:"ue_address6,omitempty"`
+	UEKeys     []string `json:"ue_keys,omitempty"`
+	PacketForwardingRules
+}
+
+type fileStoreRecord struct {
+	Op      string         `json:"op"`
+	SEID    uint64         `json:"seid,omitempty"`
+	Session *storedSession `json:"session,omitempty"`
+}
+
+// FileStore is a SessionsStore that persists PFCP sessions to an append-only log, so that they
+// survive a restart of the PFCP agent. Every change is synced to disk before returning. The log
+// is replayed when the store is opened, and compacted once it holds too many stale records.
+type FileStore struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	sessions map[uint64]PFCPSession
+	indexes  *sessionIndexes
+	// records is the number of records in the log.
+	records int
+}
+
+// NewFileStore opens the store backed by the log at path, creating it if needed, and loads the
+// sessions it holds.
+func NewFileStore(path string) (*FileStore, error) {
+	f := &FileStore{
+		path:     path,
+		sessions: make(map[uint64]PFCPSession),
+		indexes:  newSessionIndexes(),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	if err := f.replay(); err != nil {
+		return nil, err
+	}
+
+	for seid := range f.sessions {
+		session := f.sessions[seid]
+		f.indexes.add(&session)
+	}
+
+	// Compacting also drops a record torn by a crash, so that new records are not appended to it.
+	if err := f.compact(); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Loaded %v PFCP sessions from %v", len(f.sessions), path)
+
+	return f, nil
+}
+
+// fileStorePath returns the path of the log holding the sessions of peer in dir.
+func fileStorePath(dir, peer string) string {
+	return filepath.Join(dir, fileStorePrefix+peer+fileStoreSuffix)
+}
+
+// fileStorePeers returns the peers that have a log in dir.
+func fileStorePeers(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	peers := make([]string, 0, len(entries))
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, fileStorePrefix) || !strings.HasSuffix(name, fileStoreSuffix) {
+			continue
+		}
+
+		peers = append(peers, strings.TrimSuffix(strings.TrimPrefix(name, fileStorePrefix), fileStoreSuffix))
+	}
+
+	sort.Strings(peers)
+
+	return peers, nil
+}
+
+func (f *FileStore) replay() error {
+	data, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), fileStoreMaxRecordSize)
+
+	var pending error
+
+	for scanner.Scan() {
+		if pending != nil {
+			// Only the last record can be torn by a crash, anything else is corruption.
+			return pending
+		}
+
+		var r fileStoreRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			pending = ErrOperationFailedWithReason("replay of "+f.path, err.Error())
+			continue
+		}
+
+		f.apply(r)
+		f.records++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if pending != nil {
+		log.Warnw("Dropping incomplete last record of session store", zap.String("path", f.path),
+			zap.Error(pending))
+	}
+
+	return nil
+}
+
+func (f *FileStore) apply(r fileStoreRecord) {
+	switch r.Op {
+	case fileStoreOpPut:
+		if r.Session == nil {
+			return
+		}
+
+		f.sessions[r.Session.LocalSEID] = PFCPSession{
+			localSEID:             r.Session.LocalSEID,
+			remoteSEID:            r.Session.RemoteSEID,
+			remoteIP:              r.Session.RemoteIP,
+			cpFQCSID:              r.Session.CPFQCSID,
+			UeAddress:             r.Session.UeAddress,
+			UeAddress6:            r.Session.UeAddress6,
+			ueKeys:                r.Session.UEKeys,
+			PacketForwardingRules: r.Session.PacketForwardingRules,
+		}
+	case fileStoreOpDelete:
+		delete(f.sessions, r.SEID)
+	case fileStoreOpClear:
+		f.sessions = make(map[uint64]PFCPSession)
+	}
+}
+
+func newPutRecord(session PFCPSession) fileStoreRecord {
+	return fileStoreRecord{
+		Op: fileStoreOpPut,
+		Session: &storedSession{
+			LocalSEID:             session.localSEID,
+			RemoteSEID:            session.remoteSEID,
+			RemoteIP:              session.remoteIP,
+			CPFQCSID:              session.cpFQCSID,
+			UeAddress:             session.UeAddress,
+			UeAddress6:            session.UeAddress6,
+			UEKeys:                session.ueKeys,
+			PacketForwardingRules: session.PacketForwardingRules,
+		},
+	}
+}
+
+// compact rewrites the log with a single record per session, and reopens it for appending.
+func (f *FileStore) compact() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+
+		f.file = nil
+	}
+
+	tmpPath := f.path + ".tmp"
+
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+
+	for _, session := range f.sessions {
+		if err = enc.Encode(newPutRecord(session)); err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(tmpPath, f.path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	f.file, err = os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	f.records = len(f.sessions)
+
+	return nil
+}
+
+func (f *FileStore) append(r fileStoreRecord) error {
+	if f.file == nil {
+		return ErrOperationFailedWithReason("write session store", "store is closed")
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	if err = f.file.Sync(); err != nil {
+		return err
+	}
+
+	f.records++
+
+	if f.records-len(f.sessions) > fileStoreCompactThreshold {
+		return f.compact()
+	}
+
+	return nil
+}
+
+func (f *FileStore) GetAllSessions() []PFCPSession {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sessions := make([]PFCPSession, 0, len(f.sessions))
+	for _, session := range f.sessions {
+		sessions = append(sessions, session)
+	}
+
+	return sessions
+}
+
+func (f *FileStore) PutSession(session PFCPSession) error {
+	if session.localSEID == 0 {
+		return ErrInvalidArgument("session.localSEID", session.localSEID)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// The session is cached before it is written, as a failed write may have reached the disk.
+	if old, ok := f.sessions[session.localSEID]; ok {
+		f.indexes.remove(&old)
+	}
+
+	f.sessions[session.localSEID] = session
+	f.indexes.add(&session)
+
+	if err := f.append(newPutRecord(session)); err != nil {
+		return ErrOperationFailedWithReason("save of PFCP session", err.Error())
+	}
+
+	log.Debugw(
+		"Saved PFCP session to file store",
+		zap.Any("session", session),
+	)
+
+	return nil
+}
+
+func (f *FileStore) DeleteSession(fseid uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session, ok := f.sessions[fseid]
+	if !ok {
+		return nil
+	}
+
+	delete(f.sessions, fseid)
+	f.indexes.remove(&session)
+
+	if err := f.append(fileStoreRecord{Op: fileStoreOpDelete, SEID: fseid}); err != nil {
+		return ErrOperationFailedWithReason("removal of PFCP session", err.Error())
+	}
+
+	log.Debugw(
+		"PFCP session removed from file store",
+		zap.Uint64("F-SEID", fseid),
+	)
+
+	return nil
+}
+
+func (f *FileStore) DeleteAllSessions() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sessions = make(map[uint64]PFCPSession)
+	f.indexes = newSessionIndexes()
+
+	if err := f.append(fileStoreRecord{Op: fileStoreOpClear}); err != nil {
+		log.Errorf("Failed to remove PFCP sessions from file store: %v", err)
+		return false
+	}
+
+	log.Debug("All PFCP sessions removed from file store")
+
+	return true
+}
+
+func (f *FileStore) GetSession(fseid uint64) (PFCPSession, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session, ok := f.sessions[fseid]
+
+	return session, ok
+}
+
+// getSessionBy returns the session whose local SEID is found by lookup in the indexes.
+func (f *FileStore) getSessionBy(lookup func(x *sessionIndexes) (uint64, bool)) (PFCPSession, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	seid, ok := lookup(f.indexes)
+	if !ok {
+		return PFCPSession{}, false
+	}
+
+	session, ok := f.sessions[seid]
+
+	return session, ok
+}
+
+func (f *FileStore) GetSessionByUEAddress(ueAddress net.IP) (PFCPSession, bool) {
+	return f.getSessionBy(func(x *sessionIndexes) (uint64, bool) { return x.byUEAddress(ueAddress) })
+}
+
+func (f *FileStore) GetSessionByFTEID(teid uint32, ip net.IP) (PFCPSession, bool) {
+	return f.getSessionBy(func(x *sessionIndexes) (uint64, bool) { return x.byFTEID(teid, ip) })
+}
+
+func (f *FileStore) GetSessionByRemoteFSEID(seid uint64, ip net.IP) (PFCPSession, bool) {
+	return f.getSessionBy(func(x *sessionIndexes) (uint64, bool) { return x.byRemoteFSEID(seid, ip) })
+}
+
+// Close closes the log. The sessions remain on disk.
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
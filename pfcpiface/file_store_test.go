@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStoredSession(seid uint64) PFCPSession {
+	session := newTestSession(seid)
+	session.remoteSEID = seid + 100
+	session.UeAddress = ip2int(net.ParseIP("10.250.0.1"))
+	session.cpFQCSID = &fqCSID{NodeAddress: "10.0.0.1", CSIDs: []uint16{1, 2}}
+	session.ueKeys = []string{"imsi-001010000000001"}
+	session.Pdrs[0].AppFilter.SrcPortRange = NewRangeMatchPortRange(80, 8080)
+	session.Pdrs[0].AppFilter.DstPortRange = newExactMatchPortRange(443)
+
+	return session
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store", "sessions-test.log")
+
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	s1, s2, s3 := newTestStoredSession(1), newTestStoredSession(2), newTestStoredSession(3)
+
+	require.NoError(t, store.PutSession(s1))
+	require.NoError(t, store.PutSession(s2))
+	require.NoError(t, store.PutSession(s3))
+	require.Error(t, store.PutSession(PFCPSession{}), "sessions without SEID must be rejected")
+
+	s2.Fars[0].TunnelTEID = 0x42
+	require.NoError(t, store.PutSession(s2))
+	require.NoError(t, store.DeleteSession(3))
+	require.NoError(t, store.DeleteSession(3), "deleting a missing session is not an error")
+
+	got, ok := store.GetSession(2)
+	require.True(t, ok)
+	require.Equal(t, s2, got)
+
+	_, ok = store.GetSession(3)
+	require.False(t, ok)
+
+	// The store is dropped without being closed, as it would be by a crash
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	defer reopened.Close()
+
+	require.ElementsMatch(t, []PFCPSession{s1, s2}, reopened.GetAllSessions())
+
+	require.True(t, reopened.DeleteAllSessions())
+	require.Empty(t, reopened.GetAllSessions())
+	require.NoError(t, reopened.Close())
+	require.Error(t, reopened.PutSession(s1), "closed store must reject writes")
+
+	reopened, err = NewFileStore(path)
+	require.NoError(t, err)
+	require.Empty(t, reopened.GetAllSessions())
+}
+
+func TestFileStore_Replay(t *testing.T) {
+	newLog := func(t *testing.T, tail string) string {
+		path := filepath.Join(t.TempDir(), "sessions-test.log")
+
+		store, err := NewFileStore(path)
+		require.NoError(t, err)
+		require.NoError(t, store.PutSession(newTestStoredSession(1)))
+		require.NoError(t, store.PutSession(newTestStoredSession(2)))
+
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+		require.NoError(t, err)
+
+		_, err = f.WriteString(tail)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		return path
+	}
+
+	t.Run("torn last record is dropped", func(t *testing.T) {
+		path := newLog(t, `{"op":"delete","se`)
+
+		store, err := NewFileStore(path)
+		require.NoError(t, err)
+		require.Len(t, store.GetAllSessions(), 2)
+
+		// New records must not be appended to the torn one
+		require.NoError(t, store.DeleteSession(1))
+
+		store, err = NewFileStore(path)
+		require.NoError(t, err)
+		require.Len(t, store.GetAllSessions(), 1)
+	})
+
+	t.Run("corrupt record is rejected", func(t *testing.T) {
+		path := newLog(t, "garbage\n"+`{"op":"delete","seid":1}`+"\n")
+
+		_, err := NewFileStore(path)
+		require.Error(t, err)
+	})
+}
+
+func TestFileStore_Compaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions-test.log")
+
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	session := newTestStoredSession(1)
+
+	for i := 0; i < 2*fileStoreCompactThreshold; i++ {
+		session.Fars[0].TunnelTEID = uint32(i)
+		require.NoError(t, store.PutSession(session))
+	}
+
+	require.LessOrEqual(t, store.records, fileStoreCompactThreshold+1)
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.LessOrEqual(t, bytes.Count(data, []byte("\n")), fileStoreCompactThreshold+1)
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	got, ok := reopened.GetSession(1)
+	require.True(t, ok)
+	require.Equal(t, session, got)
+}
+
+func TestFileStorePeers(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, peer := range []string{"10.0.0.2", "10.0.0.1"} {
+		store, err := NewFileStore(fileStorePath(dir, peer))
+		require.NoError(t, err)
+		require.NoError(t, store.Close())
+	}
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "other.log"), nil, 0600))
+
+	peers, err := fileStorePeers(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, peers)
+
+	peers, err = fileStorePeers(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	require.Empty(t, peers)
+}
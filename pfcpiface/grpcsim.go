@@ -265,13 +265,19 @@ func (u *Upf) sim(mode simMode, s *SimModeInfo) {
 			UeAddress:             ip2int(ueip),
 		}
 
+		tx := NewTransaction(u.Datapath)
+
 		if mode.create() {
-			u.SendMsgToUPF(UpfMsgTypeAdd, session, allRules)
+			tx.CreateRules(allRules)
 		} else if mode.delete() {
-			u.SendMsgToUPF(UpfMsgTypeDel, session, allRules)
+			tx.DeleteRules(allRules)
 		} else {
 			log.Fatalf("Unsupported method %v", mode)
 		}
+
+		if err := u.commitTransaction(tx); err != nil {
+			log.Errorf("Failed to %v session %v: %v", mode, session.localSEID, err)
+		}
 	}
 
 	log.Infof("Sessions/s: %v", float64(s.MaxSessions)/time.Since(start).Seconds())
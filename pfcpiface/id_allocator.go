@@ -4,16 +4,99 @@ package pfcpiface
 
 import (
 	"errors"
+	"math/bits"
 	"sync"
 )
 
+const (
+	// idLeafBits is the number of bits of the offsets of the IDs in a leaf of an IDAllocator.
+	idLeafBits  = 16
+	idLeafSize  = 1 << idLeafBits
+	idLeafWords = idLeafSize / 64
+)
+
+// idLeaf is the bitmap of a block of idLeafSize IDs, with a bit set for each used ID.
+type idLeaf struct {
+	words [idLeafWords]uint64
+	// full has a bit set for each word of words whose bits are all set
+	full [idLeafWords / 64]uint64
+	// used is the number of bits set, including those past the end of the range
+	used uint32
+}
+
+// set sets bit off, returning false if it was already set.
+func (l *idLeaf) set(off uint32) bool {
+	w, mask := off/64, uint64(1)<<(off%64)
+	if l.words[w]&mask != 0 {
+		return false
+	}
+
+	l.words[w] |= mask
+	l.used++
+
+	if l.words[w] == ^uint64(0) {
+		l.full[w/64] |= 1 << (w % 64)
+	}
+
+	return true
+}
+
+// clear clears bit off, returning false if it was not set.
+func (l *idLeaf) clear(off uint32) bool {
+	w, mask := off/64, uint64(1)<<(off%64)
+	if l.words[w]&mask == 0 {
+		return false
+	}
+
+	l.words[w] &^= mask
+	l.used--
+	l.full[w/64] &^= 1 << (w % 64)
+
+	return true
+}
+
+// findFree returns the offset of the first clear bit from off.
+func (l *idLeaf) findFree(off uint32) (uint32, bool) {
+	w := off / 64
+
+	if free := ^l.words[w] & (^uint64(0) << (off % 64)); free != 0 {
+		return w*64 + uint32(bits.TrailingZeros64(free)), true
+	}
+
+	// Look for a word that is not full after w
+	for w++; w < idLeafWords; {
+		notFull := ^l.full[w/64] & (^uint64(0) << (w % 64))
+		if notFull == 0 {
+			w = (w/64 + 1) * 64
+			continue
+		}
+
+		w = w/64*64 + uint32(bits.TrailingZeros64(notFull))
+
+		return w*64 + uint32(bits.TrailingZeros64(^l.words[w])), true
+	}
+
+	return 0, false
+}
+
+// IDAllocator allocates IDs in the range [minValue, maxValue]. Used IDs are tracked in a two
+// level bitmap: leaves of idLeafSize IDs, created on first use and dropped once unused, and a
+// bitmap of the full leaves. Allocations go round-robin, so that a freed ID is not reused at
+// once.
 type IDAllocator struct {
-	lock       sync.Mutex
-	minValue   uint32
-	maxValue   uint32
-	valueRange uint32
-	offset     uint32
-	usedMap    map[uint32]bool
+	lock     sync.Mutex
+	minValue uint32
+	maxValue uint32
+	// size is the number of IDs in the range
+	size uint64
+	// next is the offset of the ID from which the next allocation looks for a free ID
+	next uint64
+	// used is the number of IDs in use
+	used uint64
+	// leaves are the bitmaps of the IDs, by offset / idLeafSize, nil while none is used
+	leaves []*idLeaf
+	// full has a bit set for each leaf whose IDs are all used
+	full []uint64
 }
 
 // NewIDAllocator with minValue and maxValue.
@@ -23,12 +106,56 @@ func NewIDAllocator(minValue, maxValue uint32) *IDAllocator {
 	return idAllocator
 }
 
+// NewIDAllocatorWithPrefix returns an allocator of the IDs whose prefixLen most significant
+// bits are prefix, e.g. to split the TEIDs between UPF instances or interfaces. The ID made of
+// zeros only is never allocated.
+func NewIDAllocatorWithPrefix(prefix uint32, prefixLen uint8) (*IDAllocator, error) {
+	minValue, maxValue, err := prefixRange(prefix, prefixLen)
+	if err != nil {
+		return nil, err
+	}
+
+	if minValue == 0 {
+		minValue = 1
+	}
+
+	return NewIDAllocator(minValue, maxValue), nil
+}
+
+// prefixRange returns the range of the IDs whose prefixLen most significant bits are prefix.
+func prefixRange(prefix uint32, prefixLen uint8) (minValue, maxValue uint32, err error) {
+	if prefixLen >= 32 {
+		return 0, 0, ErrInvalidArgumentWithReason("prefixLen", prefixLen, "must leave bits to allocate")
+	}
+
+	if prefixLen == 0 {
+		if prefix != 0 {
+			return 0, 0, ErrInvalidArgumentWithReason("prefix", prefix, "longer than the prefix length")
+		}
+
+		return 0, ^uint32(0), nil
+	}
+
+	if prefix>>prefixLen != 0 {
+		return 0, 0, ErrInvalidArgumentWithReason("prefix", prefix, "longer than the prefix length")
+	}
+
+	hostBits := 32 - prefixLen
+	minValue = prefix << hostBits
+
+	return minValue, minValue | (1<<hostBits - 1), nil
+}
+
 func (idAllocator *IDAllocator) init(minValue, maxValue uint32) {
-	idAllocator.offset = 0
+	idAllocator.next = 0
+	idAllocator.used = 0
 	idAllocator.minValue = minValue
 	idAllocator.maxValue = maxValue
-	idAllocator.valueRange = maxValue - minValue + 1
-	idAllocator.usedMap = make(map[uint32]bool)
+	idAllocator.size = uint64(maxValue) - uint64(minValue) + 1
+
+	nLeaves := (idAllocator.size + idLeafSize - 1) / idLeafSize
+	idAllocator.leaves = make([]*idLeaf, nLeaves)
+	idAllocator.full = make([]uint64, (nLeaves+63)/64)
 }
 
 // Allocate and return an id in range [minValue, maxValue]
@@ -36,23 +163,29 @@ func (idAllocator *IDAllocator) Allocate() (id uint32, err error) {
 	idAllocator.lock.Lock()
 	defer idAllocator.lock.Unlock()
 
-	offsetBegin := idAllocator.offset
-	for {
-		if _, ok := idAllocator.usedMap[idAllocator.offset]; ok {
-			idAllocator.updateOffset()
+	offset, ok := idAllocator.findFree(idAllocator.next)
+	if !ok {
+		err = errors.New("no available value range to allocate id")
+		return
+	}
+
+	idAllocator.set(offset)
+	idAllocator.next = (offset + 1) % idAllocator.size
 
-			if idAllocator.offset == offsetBegin {
-				err = errors.New("no available value range to allocate id")
-				return
-			}
-		} else {
-			break
-		}
+	return idAllocator.minValue + uint32(offset), nil
+}
+
+// Reserve marks id as allocated, e.g. to restore an allocation made before a restart.
+func (idAllocator *IDAllocator) Reserve(id uint32) error {
+	if id < idAllocator.minValue || id > idAllocator.maxValue {
+		return ErrInvalidArgumentWithReason("id", id, "out of range")
+	}
+	idAllocator.lock.Lock()
+	defer idAllocator.lock.Unlock()
+	if !idAllocator.set(uint64(id - idAllocator.minValue)) {
+		return ErrInvalidArgumentWithReason("id", id, "already allocated")
 	}
-	idAllocator.usedMap[idAllocator.offset] = true
-	id = idAllocator.offset + idAllocator.minValue
-	idAllocator.updateOffset()
-	return
+	return nil
 }
 
 // Free releases an already allocated ID
@@ -62,10 +195,221 @@ func (idAllocator *IDAllocator) Free(id uint32) {
 	}
 	idAllocator.lock.Lock()
 	defer idAllocator.lock.Unlock()
-	delete(idAllocator.usedMap, id-idAllocator.minValue)
+	idAllocator.clear(uint64(id - idAllocator.minValue))
+}
+
+// InUse reports whether id is allocated.
+func (idAllocator *IDAllocator) InUse(id uint32) bool {
+	if id < idAllocator.minValue || id > idAllocator.maxValue {
+		return false
+	}
+
+	idAllocator.lock.Lock()
+	defer idAllocator.lock.Unlock()
+
+	offset := uint64(id - idAllocator.minValue)
+	leaf := idAllocator.leaves[offset/idLeafSize]
+
+	return leaf != nil && leaf.words[offset%idLeafSize/64]&(1<<(offset%64)) != 0
+}
+
+// Used returns the number of allocated IDs.
+func (idAllocator *IDAllocator) Used() uint64 {
+	idAllocator.lock.Lock()
+	defer idAllocator.lock.Unlock()
+
+	return idAllocator.used
+}
+
+// padding returns the number of bits of leaf i past the end of the range.
+func (idAllocator *IDAllocator) padding(i uint64) uint32 {
+	if end := (i + 1) * idLeafSize; end > idAllocator.size {
+		return uint32(end - idAllocator.size)
+	}
+
+	return 0
 }
 
-func (idAllocator *IDAllocator) updateOffset() {
-	idAllocator.offset++
-	idAllocator.offset = idAllocator.offset % idAllocator.valueRange
+// set marks the ID at offset as used, returning false if it already was.
+func (idAllocator *IDAllocator) set(offset uint64) bool {
+	i := offset / idLeafSize
+
+	leaf := idAllocator.leaves[i]
+	if leaf == nil {
+		leaf = &idLeaf{}
+
+		// The IDs past the end of the range are never free
+		for off := uint32(idLeafSize - idAllocator.padding(i)); off < idLeafSize; off++ {
+			leaf.set(off)
+		}
+
+		idAllocator.leaves[i] = leaf
+	}
+
+	if !leaf.set(uint32(offset % idLeafSize)) {
+		return false
+	}
+
+	idAllocator.used++
+
+	if leaf.used == idLeafSize {
+		idAllocator.full[i/64] |= 1 << (i % 64)
+	}
+
+	return true
+}
+
+// clear marks the ID at offset as free, returning false if it already was.
+func (idAllocator *IDAllocator) clear(offset uint64) bool {
+	i := offset / idLeafSize
+
+	leaf := idAllocator.leaves[i]
+	if leaf == nil || !leaf.clear(uint32(offset%idLeafSize)) {
+		return false
+	}
+
+	idAllocator.used--
+	idAllocator.full[i/64] &^= 1 << (i % 64)
+
+	if leaf.used == idAllocator.padding(i) {
+		idAllocator.leaves[i] = nil
+	}
+
+	return true
+}
+
+// findFree returns the offset of the first free ID from offset, wrapping around the range.
+func (idAllocator *IDAllocator) findFree(offset uint64) (uint64, bool) {
+	if idAllocator.used == idAllocator.size {
+		return 0, false
+	}
+
+	nLeaves := uint64(len(idAllocator.leaves))
+	i, off := offset/idLeafSize, uint32(offset%idLeafSize)
+
+	// The leaf of offset is visited twice when wrapping around: from offset, then from 0
+	for n := uint64(0); n <= nLeaves; n++ {
+		if idAllocator.full[i/64]&(1<<(i%64)) == 0 {
+			leaf := idAllocator.leaves[i]
+			if leaf == nil {
+				if i*idLeafSize+uint64(off) < idAllocator.size {
+					return i*idLeafSize + uint64(off), true
+				}
+			} else if free, ok := leaf.findFree(off); ok {
+				return i*idLeafSize + uint64(free), true
+			}
+		}
+
+		i, off = idAllocator.nextLeaf(i), 0
+	}
+
+	return 0, false
+}
+
+// nextLeaf returns the index of the first leaf after leaf i that is not full, wrapping around.
+func (idAllocator *IDAllocator) nextLeaf(i uint64) uint64 {
+	nLeaves := uint64(len(idAllocator.leaves))
+	j := (i + 1) % nLeaves
+
+	// One more word than full holds, as the word of j is first visited from j only
+	for n := 0; n <= len(idAllocator.full); n++ {
+		notFull := ^idAllocator.full[j/64] & (^uint64(0) << (j % 64))
+		if next := j/64*64 + uint64(bits.TrailingZeros64(notFull)); notFull != 0 && next < nLeaves {
+			return next
+		}
+
+		if j = (j/64 + 1) * 64; j >= nLeaves {
+			j = 0
+		}
+	}
+
+	return i
+}
+
+// IDAllocatorSnapshot is the state of an IDAllocator, e.g. to restore it after a restart.
+type IDAllocatorSnapshot struct {
+	MinValue uint32 `json:"min_value"`
+	MaxValue uint32 `json:"max_value"`
+	// Next is the ID from which the next allocation looks for a free ID
+	Next uint32 `json:"next"`
+	// Used are the allocated IDs, as ranges of consecutive IDs [first, last]
+	Used [][2]uint32 `json:"used"`
+}
+
+// Snapshot returns the state of the allocator.
+func (idAllocator *IDAllocator) Snapshot() IDAllocatorSnapshot {
+	idAllocator.lock.Lock()
+	defer idAllocator.lock.Unlock()
+
+	s := IDAllocatorSnapshot{
+		MinValue: idAllocator.minValue,
+		MaxValue: idAllocator.maxValue,
+		Next:     idAllocator.minValue + uint32(idAllocator.next),
+	}
+
+	inRange := false
+
+	for i, leaf := range idAllocator.leaves {
+		if leaf == nil {
+			inRange = false
+			continue
+		}
+
+		for w, word := range leaf.words {
+			if word == 0 {
+				inRange = false
+				continue
+			}
+
+			for b := 0; b < 64; b++ {
+				offset := uint64(i)*idLeafSize + uint64(w)*64 + uint64(b)
+				if word&(1<<b) == 0 || offset >= idAllocator.size {
+					inRange = false
+					continue
+				}
+
+				id := idAllocator.minValue + uint32(offset)
+				if inRange {
+					s.Used[len(s.Used)-1][1] = id
+				} else {
+					s.Used = append(s.Used, [2]uint32{id, id})
+					inRange = true
+				}
+			}
+		}
+	}
+
+	return s
+}
+
+// Restore replaces the state of the allocator with the snapshot s, taken of an allocator of the
+// same range.
+func (idAllocator *IDAllocator) Restore(s IDAllocatorSnapshot) error {
+	if s.MinValue != idAllocator.minValue || s.MaxValue != idAllocator.maxValue {
+		return ErrInvalidArgumentWithReason("snapshot", s.MinValue, "range does not match the allocator")
+	}
+
+	for _, r := range s.Used {
+		if r[0] > r[1] || r[0] < s.MinValue || r[1] > s.MaxValue {
+			return ErrInvalidArgumentWithReason("snapshot range", r, "out of range")
+		}
+	}
+
+	if s.Next < s.MinValue || s.Next > s.MaxValue {
+		return ErrInvalidArgumentWithReason("snapshot next", s.Next, "out of range")
+	}
+
+	idAllocator.lock.Lock()
+	defer idAllocator.lock.Unlock()
+
+	idAllocator.init(s.MinValue, s.MaxValue)
+	idAllocator.next = uint64(s.Next - s.MinValue)
+
+	for _, r := range s.Used {
+		for id := uint64(r[0]); id <= uint64(r[1]); id++ {
+			idAllocator.set(id - uint64(s.MinValue))
+		}
+	}
+
+	return nil
 }
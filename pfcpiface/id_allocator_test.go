@@ -4,6 +4,8 @@ package pfcpiface
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"sync"
 	"testing"
 )
@@ -84,9 +86,7 @@ func TestTriggerNoSpaceToAllocateError(t *testing.T) {
 			idGenerator := NewIDAllocator(testCase.minValue, testCase.maxValue)
 
 			for i := 0; i < valueRange; i++ {
-				p, err := idGenerator.Allocate()
-				fmt.Sprint(p)
-
+				_, err := idGenerator.Allocate()
 				if err != nil {
 					t.Error(err)
 					t.FailNow()
@@ -101,3 +101,300 @@ func TestTriggerNoSpaceToAllocateError(t *testing.T) {
 		})
 	}
 }
+
+func TestReserve(t *testing.T) {
+	idGenerator := NewIDAllocator(1, 3)
+
+	if err := idGenerator.Reserve(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := idGenerator.Reserve(2); err == nil {
+		t.Fatal("expect error when reserving an allocated id, but error is nil")
+	}
+
+	if err := idGenerator.Reserve(4); err == nil {
+		t.Fatal("expect error when reserving an out of range id, but error is nil")
+	}
+
+	for _, want := range []uint32{1, 3} {
+		id, err := idGenerator.Allocate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != want {
+			t.Fatalf("expect id %d, got %d", want, id)
+		}
+	}
+
+	if _, err := idGenerator.Allocate(); err == nil {
+		t.Fatal("expect return error, but error is nil")
+	}
+}
+
+func TestAllocateRoundRobin(t *testing.T) {
+	// Three leaves, the last one partial
+	const maxValue = 2*idLeafSize + 100
+
+	idGenerator := NewIDAllocator(1, maxValue)
+
+	for i := uint32(1); i <= maxValue; i++ {
+		if err := idGenerator.Reserve(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if idGenerator.Used() != maxValue {
+		t.Fatalf("expect %d used ids, got %d", maxValue, idGenerator.Used())
+	}
+
+	if _, err := idGenerator.Allocate(); err == nil {
+		t.Fatal("expect return error, but error is nil")
+	}
+
+	// Freed ids are allocated in order from the last allocation, wrapping around the range
+	for _, id := range []uint32{maxValue, 5, idLeafSize + 7} {
+		idGenerator.Free(id)
+	}
+
+	for _, want := range []uint32{5, idLeafSize + 7, maxValue} {
+		id, err := idGenerator.Allocate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != want {
+			t.Fatalf("expect id %d, got %d", want, id)
+		}
+
+		if !idGenerator.InUse(id) {
+			t.Fatalf("expect id %d to be in use", id)
+		}
+	}
+
+	for i := uint32(1); i <= maxValue; i++ {
+		idGenerator.Free(i)
+	}
+
+	idGenerator.Free(1)
+
+	if idGenerator.Used() != 0 || idGenerator.InUse(1) {
+		t.Fatal("expect all ids to be free")
+	}
+
+	for i, leaf := range idGenerator.leaves {
+		if leaf != nil {
+			t.Fatalf("expect unused leaf %d to be dropped", i)
+		}
+	}
+
+	id, err := idGenerator.Allocate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != 1 {
+		t.Fatalf("expect id 1 after wrapping around, got %d", id)
+	}
+}
+
+func TestAllocateFullRange(t *testing.T) {
+	idGenerator := NewIDAllocator(math.MaxUint32-1, math.MaxUint32)
+
+	for _, want := range []uint32{math.MaxUint32 - 1, math.MaxUint32} {
+		id, err := idGenerator.Allocate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != want {
+			t.Fatalf("expect id %d, got %d", want, id)
+		}
+	}
+
+	if _, err := idGenerator.Allocate(); err == nil {
+		t.Fatal("expect return error, but error is nil")
+	}
+
+	idGenerator = NewIDAllocator(0, math.MaxUint32)
+	if idGenerator.size != 1<<32 {
+		t.Fatalf("expect a range of 2^32 ids, got %d", idGenerator.size)
+	}
+
+	if err := idGenerator.Reserve(math.MaxUint32); err != nil {
+		t.Fatal(err)
+	}
+
+	if !idGenerator.InUse(math.MaxUint32) || idGenerator.InUse(0) {
+		t.Fatal("expect only the last id to be in use")
+	}
+}
+
+func TestNewIDAllocatorWithPrefix(t *testing.T) {
+	testCases := []struct {
+		prefix    uint32
+		prefixLen uint8
+		minValue  uint32
+		maxValue  uint32
+		wantErr   bool
+	}{
+		{prefix: 0, prefixLen: 0, minValue: 1, maxValue: math.MaxUint32},
+		{prefix: 0, prefixLen: 4, minValue: 1, maxValue: 0x0fffffff},
+		{prefix: 0xa, prefixLen: 4, minValue: 0xa0000000, maxValue: 0xafffffff},
+		{prefix: 0x1234, prefixLen: 16, minValue: 0x12340000, maxValue: 0x1234ffff},
+		{prefix: 0x10, prefixLen: 4, wantErr: true},
+		{prefix: 1, prefixLen: 0, wantErr: true},
+		{prefix: 0, prefixLen: 32, wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("prefix: %#x/%d", testCase.prefix, testCase.prefixLen), func(t *testing.T) {
+			idGenerator, err := NewIDAllocatorWithPrefix(testCase.prefix, testCase.prefixLen)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatal("expect return error, but error is nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if idGenerator.minValue != testCase.minValue || idGenerator.maxValue != testCase.maxValue {
+				t.Fatalf("expect range [%#x, %#x], got [%#x, %#x]", testCase.minValue, testCase.maxValue,
+					idGenerator.minValue, idGenerator.maxValue)
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	idGenerator := NewIDAllocator(10, 10+2*idLeafSize)
+
+	for i := 0; i < idLeafSize+10; i++ {
+		if _, err := idGenerator.Allocate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idGenerator.Free(20)
+	idGenerator.Free(21)
+
+	if err := idGenerator.Reserve(10 + 2*idLeafSize); err != nil {
+		t.Fatal(err)
+	}
+
+	s := idGenerator.Snapshot()
+
+	want := IDAllocatorSnapshot{
+		MinValue: 10,
+		MaxValue: 10 + 2*idLeafSize,
+		Next:     20 + idLeafSize,
+		Used:     [][2]uint32{{10, 19}, {22, 19 + idLeafSize}, {10 + 2*idLeafSize, 10 + 2*idLeafSize}},
+	}
+	if fmt.Sprint(s) != fmt.Sprint(want) {
+		t.Fatalf("expect snapshot %v, got %v", want, s)
+	}
+
+	restored := NewIDAllocator(10, 10+2*idLeafSize)
+	if err := restored.Restore(s); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Used() != idGenerator.Used() {
+		t.Fatalf("expect %d used ids, got %d", idGenerator.Used(), restored.Used())
+	}
+
+	id, err := restored.Allocate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != 20+idLeafSize {
+		t.Fatalf("expect allocations to resume from %d, got %d", 20+idLeafSize, id)
+	}
+
+	if err := NewIDAllocator(1, 10).Restore(s); err == nil {
+		t.Fatal("expect error when restoring a snapshot of another range, but error is nil")
+	}
+
+	s.Used = append(s.Used, [2]uint32{5, 9})
+	if err := restored.Restore(s); err == nil {
+		t.Fatal("expect error when restoring out of range ids, but error is nil")
+	}
+
+	if restored.Used() != idGenerator.Used()+1 {
+		t.Fatal("expect a failed restore to leave the allocator unchanged")
+	}
+}
+
+func BenchmarkIDAllocator(b *testing.B) {
+	b.Run("allocate", func(b *testing.B) {
+		idGenerator := NewIDAllocator(1, math.MaxUint32)
+
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := idGenerator.Allocate(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	for _, used := range []int{1 << 20, 4 << 20} {
+		b.Run(fmt.Sprintf("churn with %d used", used), func(b *testing.B) {
+			idGenerator := NewIDAllocator(1, math.MaxUint32)
+
+			for i := 0; i < used; i++ {
+				if _, err := idGenerator.Allocate(); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				id, err := idGenerator.Allocate()
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				idGenerator.Free(id - uint32(used))
+			}
+		})
+	}
+
+	b.Run("nearly full range", func(b *testing.B) {
+		const size = 1 << 20
+
+		idGenerator := NewIDAllocator(1, size)
+		rng := rand.New(rand.NewSource(1)) // #nosec G404
+
+		for i := 0; i < size; i++ {
+			if _, err := idGenerator.Allocate(); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		// Free 1% of the IDs, scattered over the range
+		for i := 0; i < size/100; i++ {
+			idGenerator.Free(uint32(rng.Intn(size)) + 1)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			id, err := idGenerator.Allocate()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			idGenerator.Free(id)
+		}
+	})
+}
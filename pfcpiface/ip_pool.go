@@ -4,6 +4,7 @@
 package pfcpiface
 
 import (
+	"encoding/binary"
 	"fmt"
 	"net"
 	"strings"
@@ -15,6 +16,8 @@ type IPPool struct {
 	freePool []net.IP
 	// inventory keeps track of allocated sessions and their IPs.
 	inventory map[uint64]net.IP
+	// pinned are the reserved IPs, kept out of freePool: true while assigned to a session.
+	pinned map[string]bool
 }
 
 // NewIPPool creates a new pool of IP addresses with the given subnet.
@@ -27,6 +30,7 @@ func NewIPPool(poolSubnet string) (*IPPool, error) {
 
 	i := &IPPool{
 		inventory: make(map[uint64]net.IP),
+		pinned:    make(map[string]bool),
 	}
 
 	for ip := ip.Mask(ipnet.Mask); ipnet.Contains(ip); inc(ip) {
@@ -58,7 +62,7 @@ func (i *IPPool) LookupOrAllocIP(seid uint64) (net.IP, error) {
 
 	// Check capacity before new allocations.
 	if len(i.freePool) == 0 {
-		return nil, ErrOperationFailedWithReason("IP allocation", "ip pool empty")
+		return nil, ErrExhausted("IP pool")
 	}
 
 	ip = i.freePool[0]
@@ -72,6 +76,117 @@ func (i *IPPool) LookupOrAllocIP(seid uint64) (net.IP, error) {
 	return ipVal, nil
 }
 
+// ReserveIP assigns ip to the session, e.g. to restore an allocation made before a restart.
+// ip must be free, unless it is already assigned to the session.
+func (i *IPPool) ReserveIP(seid uint64, ip net.IP) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if allocated, found := i.inventory[seid]; found {
+		if allocated.Equal(ip) {
+			return nil
+		}
+
+		return ErrInvalidArgumentWithReason("seid", seid, "session already has IP "+allocated.String())
+	}
+
+	if inUse, ok := i.pinned[ip.String()]; ok && !inUse {
+		i.pinned[ip.String()] = true
+		i.inventory[seid] = ip
+		log.Debugf("Reserved session %v pinned IP %v", seid, ip)
+
+		return nil
+	}
+
+	idx := i.freeIndex(ip)
+	if idx < 0 {
+		return ErrInvalidArgumentWithReason("ip", ip, "not available in pool")
+	}
+
+	i.inventory[seid] = i.freePool[idx]
+	i.freePool = append(i.freePool[:idx], i.freePool[idx+1:]...)
+	log.Debugf("Reserved session %v IP %v", seid, ip)
+
+	return nil
+}
+
+// freeIndex returns the index of ip in freePool, -1 if it is not free.
+func (i *IPPool) freeIndex(ip net.IP) int {
+	for idx, free := range i.freePool {
+		if free.Equal(ip) {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// PinIP reserves ip, so that it is only assigned by AllocPinnedIP. ip must be free.
+func (i *IPPool) PinIP(ip net.IP) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.pinned[ip.String()]; ok {
+		return ErrInvalidArgumentWithReason("ip", ip, "already reserved")
+	}
+
+	idx := i.freeIndex(ip)
+	if idx < 0 {
+		return ErrInvalidArgumentWithReason("ip", ip, "not available in pool")
+	}
+
+	i.pinned[ip.String()] = false
+	i.freePool = append(i.freePool[:idx], i.freePool[idx+1:]...)
+
+	return nil
+}
+
+// UnpinIP cancels the reservation of ip. An IP still assigned to a session is freed once the
+// session releases it.
+func (i *IPPool) UnpinIP(ip net.IP) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	inUse, ok := i.pinned[ip.String()]
+	if !ok {
+		return ErrNotFoundWithParam("reserved IP", "ip", ip)
+	}
+
+	delete(i.pinned, ip.String())
+
+	if !inUse {
+		i.freePool = append(i.freePool, ip)
+	}
+
+	return nil
+}
+
+// AllocPinnedIP returns the IP of the session, assigning it the reserved ip if it has none.
+func (i *IPPool) AllocPinnedIP(seid uint64, ip net.IP) (net.IP, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if allocated, found := i.inventory[seid]; found {
+		log.Debugf("Found existing session %v IP %v", seid, allocated)
+		return allocated, nil
+	}
+
+	inUse, ok := i.pinned[ip.String()]
+	if !ok {
+		return nil, ErrNotFoundWithParam("reserved IP", "ip", ip)
+	}
+
+	if inUse {
+		return nil, ErrInvalidArgumentWithReason("ip", ip, "reserved IP already in use")
+	}
+
+	i.pinned[ip.String()] = true
+	i.inventory[seid] = ip
+	log.Debugf("Allocated session %v reserved IP %v", seid, ip)
+
+	return ip, nil
+}
+
 func (i *IPPool) DeallocIP(seid uint64) error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
@@ -83,12 +198,46 @@ func (i *IPPool) DeallocIP(seid uint64) error {
 	}
 
 	delete(i.inventory, seid)
-	i.freePool = append(i.freePool, ip) // Simply append to enqueue.
+
+	if _, ok := i.pinned[ip.String()]; ok {
+		i.pinned[ip.String()] = false
+	} else {
+		i.freePool = append(i.freePool, ip) // Simply append to enqueue.
+	}
+
 	log.Debugf("Deallocated session %v IP %v", seid, ip)
 
 	return nil
 }
 
+// usage returns the number of IP addresses allocated, left in the pool, and reserved but not
+// allocated.
+func (i *IPPool) usage() (used, free, reserved uint64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, inUse := range i.pinned {
+		if !inUse {
+			reserved++
+		}
+	}
+
+	return uint64(len(i.inventory)), uint64(len(i.freePool)), reserved
+}
+
+// allocations returns the IPs allocated to sessions, by SEID.
+func (i *IPPool) allocations() map[uint64]net.IP {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	allocs := make(map[uint64]net.IP, len(i.inventory))
+	for seid, ip := range i.inventory {
+		allocs[seid] = ip
+	}
+
+	return allocs
+}
+
 func (i *IPPool) String() string {
 	i.mu.Lock()
 	defer i.mu.Unlock()
@@ -104,3 +253,267 @@ func (i *IPPool) String() string {
 
 	return sb.String()
 }
+
+// uePrefixLen is the length of the IPv6 prefixes delegated to UEs.
+const uePrefixLen = 64
+
+// maxPrefixPoolBits bounds the number of prefixes of a prefixPool to 2^maxPrefixPoolBits.
+const maxPrefixPoolBits = 31
+
+// prefixPool delegates IPv6 prefixes of the same length, carved out of a shorter prefix, to
+// sessions.
+type prefixPool struct {
+	mu        sync.Mutex
+	base      net.IP
+	prefixLen uint8
+	// bits is the number of bits of the prefixes not in the base prefix
+	bits int
+	ids  *IDAllocator
+	// inventory keeps track of allocated sessions and the index of their prefix.
+	inventory map[uint64]uint32
+	// pinned are the indexes of the reserved prefixes: true while assigned to a session.
+	pinned map[uint32]bool
+}
+
+// newPrefixPool creates a pool of the prefixes of length prefixLen (at most 64) in poolSubnet.
+func newPrefixPool(poolSubnet string, prefixLen uint8) (*prefixPool, error) {
+	_, ipnet, err := net.ParseCIDR(poolSubnet)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isIPv6(ipnet.IP) {
+		return nil, ErrInvalidArgumentWithReason("newPrefixPool", poolSubnet, "not an IPv6 prefix")
+	}
+
+	if prefixLen > 64 {
+		return nil, ErrInvalidArgumentWithReason("prefixLen", prefixLen, "longer than 64 bits")
+	}
+
+	ones, _ := ipnet.Mask.Size()
+	bits := int(prefixLen) - ones
+
+	if bits <= 0 || bits > maxPrefixPoolBits {
+		return nil, ErrInvalidArgumentWithReason("newPrefixPool", poolSubnet,
+			fmt.Sprintf("must hold between 2 and 2^%d /%d prefixes", maxPrefixPoolBits, prefixLen))
+	}
+
+	return &prefixPool{
+		base:      ipnet.IP,
+		prefixLen: prefixLen,
+		bits:      bits,
+		ids:       NewIDAllocator(0, uint32(uint64(1)<<bits-1)),
+		inventory: make(map[uint64]uint32),
+		pinned:    make(map[uint32]bool),
+	}, nil
+}
+
+// prefix returns the prefix of index idx.
+func (p *prefixPool) prefix(idx uint32) net.IP {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, p.base)
+
+	hi := binary.BigEndian.Uint64(ip[:8]) | uint64(idx)<<(64-p.prefixLen)
+	binary.BigEndian.PutUint64(ip[:8], hi)
+
+	return ip
+}
+
+// index returns the index of prefix, false if it is not a prefix of the pool.
+func (p *prefixPool) index(prefix net.IP) (uint32, bool) {
+	ipnet := net.IPNet{IP: p.base, Mask: net.CIDRMask(int(p.prefixLen)-p.bits, 8*net.IPv6len)}
+	if !isIPv6(prefix) || !ipnet.Contains(prefix) {
+		return 0, false
+	}
+
+	hi := binary.BigEndian.Uint64(prefix.To16()[:8])
+	idx := uint32(hi>>(64-p.prefixLen)) & uint32(uint64(1)<<p.bits-1)
+
+	return idx, p.prefix(idx).Equal(prefix)
+}
+
+// LookupOrAllocPrefix returns the prefix of the session, allocating one if it has none.
+func (p *prefixPool) LookupOrAllocPrefix(seid uint64) (net.IP, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if idx, found := p.inventory[seid]; found {
+		return p.prefix(idx), nil
+	}
+
+	idx, err := p.ids.Allocate()
+	if err != nil {
+		return nil, ErrExhausted("IPv6 prefix pool")
+	}
+
+	p.inventory[seid] = idx
+	log.Debugf("Allocated new session %v IPv6 prefix %v/%v", seid, p.prefix(idx), p.prefixLen)
+
+	return p.prefix(idx), nil
+}
+
+// ReservePrefix assigns prefix to the session, e.g. to restore an allocation made before a
+// restart. prefix must be free, unless it is already assigned to the session.
+func (p *prefixPool) ReservePrefix(seid uint64, prefix net.IP) error {
+	idx, ok := p.index(prefix)
+	if !ok {
+		return ErrInvalidArgumentWithReason("prefix", prefix, "not a prefix of the pool")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if allocated, found := p.inventory[seid]; found {
+		if allocated == idx {
+			return nil
+		}
+
+		return ErrInvalidArgumentWithReason("seid", seid, "session already has prefix "+p.prefix(allocated).String())
+	}
+
+	if inUse, ok := p.pinned[idx]; ok && !inUse {
+		p.pinned[idx] = true
+		p.inventory[seid] = idx
+
+		return nil
+	}
+
+	if err := p.ids.Reserve(idx); err != nil {
+		return ErrInvalidArgumentWithReason("prefix", prefix, "not available in pool")
+	}
+
+	p.inventory[seid] = idx
+
+	return nil
+}
+
+func (p *prefixPool) DeallocPrefix(seid uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	idx, ok := p.inventory[seid]
+	if !ok {
+		return ErrInvalidArgumentWithReason("seid", seid, "can't dealloc non-existent session")
+	}
+
+	delete(p.inventory, seid)
+
+	if _, ok := p.pinned[idx]; ok {
+		p.pinned[idx] = false
+	} else {
+		p.ids.Free(idx)
+	}
+	log.Debugf("Deallocated session %v IPv6 prefix %v/%v", seid, p.prefix(idx), p.prefixLen)
+
+	return nil
+}
+
+// PinPrefix reserves prefix, so that it is only assigned by AllocPinnedPrefix. prefix must be
+// free.
+func (p *prefixPool) PinPrefix(prefix net.IP) error {
+	idx, ok := p.index(prefix)
+	if !ok {
+		return ErrInvalidArgumentWithReason("prefix", prefix, "not a prefix of the pool")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.pinned[idx]; ok {
+		return ErrInvalidArgumentWithReason("prefix", prefix, "already reserved")
+	}
+
+	if err := p.ids.Reserve(idx); err != nil {
+		return ErrInvalidArgumentWithReason("prefix", prefix, "not available in pool")
+	}
+
+	p.pinned[idx] = false
+
+	return nil
+}
+
+// UnpinPrefix cancels the reservation of prefix. A prefix still assigned to a session is freed
+// once the session releases it.
+func (p *prefixPool) UnpinPrefix(prefix net.IP) error {
+	idx, ok := p.index(prefix)
+	if !ok {
+		return ErrNotFoundWithParam("reserved prefix", "prefix", prefix)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inUse, ok := p.pinned[idx]
+	if !ok {
+		return ErrNotFoundWithParam("reserved prefix", "prefix", prefix)
+	}
+
+	delete(p.pinned, idx)
+
+	if !inUse {
+		p.ids.Free(idx)
+	}
+
+	return nil
+}
+
+// AllocPinnedPrefix returns the prefix of the session, assigning it the reserved prefix if it
+// has none.
+func (p *prefixPool) AllocPinnedPrefix(seid uint64, prefix net.IP) (net.IP, error) {
+	idx, ok := p.index(prefix)
+	if !ok {
+		return nil, ErrNotFoundWithParam("reserved prefix", "prefix", prefix)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if allocated, found := p.inventory[seid]; found {
+		return p.prefix(allocated), nil
+	}
+
+	inUse, ok := p.pinned[idx]
+	if !ok {
+		return nil, ErrNotFoundWithParam("reserved prefix", "prefix", prefix)
+	}
+
+	if inUse {
+		return nil, ErrInvalidArgumentWithReason("prefix", prefix, "reserved prefix already in use")
+	}
+
+	p.pinned[idx] = true
+	p.inventory[seid] = idx
+	log.Debugf("Allocated session %v reserved IPv6 prefix %v/%v", seid, prefix, p.prefixLen)
+
+	return p.prefix(idx), nil
+}
+
+// usage returns the number of prefixes allocated, left in the pool, and reserved but not
+// allocated.
+func (p *prefixPool) usage() (used, free, reserved uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, inUse := range p.pinned {
+		if !inUse {
+			reserved++
+		}
+	}
+
+	used = uint64(len(p.inventory))
+
+	return used, uint64(1)<<p.bits - used - reserved, reserved
+}
+
+// allocations returns the prefixes allocated to sessions, by SEID.
+func (p *prefixPool) allocations() map[uint64]net.IP {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	allocs := make(map[uint64]net.IP, len(p.inventory))
+	for seid, idx := range p.inventory {
+		allocs[seid] = p.prefix(idx)
+	}
+
+	return allocs
+}
@@ -145,3 +145,151 @@ func TestIPPool_DeallocIP(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestIPPool_ReserveIP(t *testing.T) {
+	pool, err := NewIPPool("10.0.0.0/30")
+	require.NoError(t, err)
+
+	ip := net.ParseIP("10.0.0.2")
+
+	require.NoError(t, pool.ReserveIP(1, ip))
+	require.NoError(t, pool.ReserveIP(1, ip), "reserving the same IP again is a no-op")
+	require.Error(t, pool.ReserveIP(2, ip), "IP is already in use")
+	require.Error(t, pool.ReserveIP(1, net.ParseIP("10.0.0.1")), "session already has an IP")
+	require.Error(t, pool.ReserveIP(3, net.ParseIP("10.1.0.1")), "IP is not in the pool")
+
+	allocated, err := pool.LookupOrAllocIP(3)
+	require.NoError(t, err)
+	require.True(t, allocated.Equal(net.ParseIP("10.0.0.1")))
+
+	_, err = pool.LookupOrAllocIP(4)
+	require.Error(t, err, "pool must be exhausted")
+}
+
+func TestPrefixPool(t *testing.T) {
+	t.Run("invalid pools", func(t *testing.T) {
+		for _, subnet := range []string{"10.0.0.0/24", "2001:db8::/64", "2001:db8::/16", "foobar"} {
+			_, err := newPrefixPool(subnet, uePrefixLen)
+			require.Error(t, err, subnet)
+		}
+	})
+
+	t.Run("allocation and release", func(t *testing.T) {
+		pool, err := newPrefixPool("2001:db8:1::/63", uePrefixLen)
+		require.NoError(t, err)
+
+		prefix, err := pool.LookupOrAllocPrefix(1)
+		require.NoError(t, err)
+		require.True(t, prefix.Equal(net.ParseIP("2001:db8:1::")))
+
+		again, err := pool.LookupOrAllocPrefix(1)
+		require.NoError(t, err)
+		require.True(t, prefix.Equal(again), "a session keeps its prefix")
+
+		other, err := pool.LookupOrAllocPrefix(2)
+		require.NoError(t, err)
+		require.True(t, other.Equal(net.ParseIP("2001:db8:1:1::")))
+
+		_, err = pool.LookupOrAllocPrefix(3)
+		require.ErrorIs(t, err, errExhausted)
+
+		used, free, reserved := pool.usage()
+		require.Equal(t, uint64(2), used)
+		require.Equal(t, uint64(0), free)
+		require.Equal(t, uint64(0), reserved)
+
+		require.NoError(t, pool.DeallocPrefix(1))
+		require.Error(t, pool.DeallocPrefix(1))
+
+		prefix, err = pool.LookupOrAllocPrefix(3)
+		require.NoError(t, err)
+		require.True(t, prefix.Equal(net.ParseIP("2001:db8:1::")))
+	})
+
+	t.Run("reservation", func(t *testing.T) {
+		pool, err := newPrefixPool("2001:db8:1::/48", uePrefixLen)
+		require.NoError(t, err)
+
+		prefix := net.ParseIP("2001:db8:1:2a::")
+
+		require.NoError(t, pool.ReservePrefix(1, prefix))
+		require.NoError(t, pool.ReservePrefix(1, prefix), "reserving the same prefix again is a no-op")
+		require.Error(t, pool.ReservePrefix(2, prefix), "prefix is already in use")
+		require.Error(t, pool.ReservePrefix(1, net.ParseIP("2001:db8:1:2b::")), "session already has a prefix")
+		require.Error(t, pool.ReservePrefix(3, net.ParseIP("2001:db8:2::")), "prefix is not in the pool")
+		require.Error(t, pool.ReservePrefix(3, net.ParseIP("2001:db8:1:2c::1")), "not a /64 prefix")
+	})
+
+	t.Run("pinning", func(t *testing.T) {
+		pool, err := newPrefixPool("2001:db8:1::/62", uePrefixLen)
+		require.NoError(t, err)
+
+		pinned := net.ParseIP("2001:db8:1::")
+
+		require.NoError(t, pool.PinPrefix(pinned))
+		require.Error(t, pool.PinPrefix(pinned), "prefix is already reserved")
+
+		prefix, err := pool.LookupOrAllocPrefix(1)
+		require.NoError(t, err)
+		require.False(t, prefix.Equal(pinned), "reserved prefixes are not allocated dynamically")
+
+		prefix, err = pool.AllocPinnedPrefix(2, pinned)
+		require.NoError(t, err)
+		require.True(t, prefix.Equal(pinned))
+
+		_, err = pool.AllocPinnedPrefix(3, pinned)
+		require.Error(t, err, "reserved prefix is in use")
+
+		require.NoError(t, pool.DeallocPrefix(2))
+
+		used, free, reserved := pool.usage()
+		require.Equal(t, []uint64{1, 2, 1}, []uint64{used, free, reserved})
+
+		require.NoError(t, pool.UnpinPrefix(pinned))
+
+		used, free, reserved = pool.usage()
+		require.Equal(t, []uint64{1, 3, 0}, []uint64{used, free, reserved})
+	})
+}
+
+func TestIPPool_PinIP(t *testing.T) {
+	pool, err := NewIPPool("10.0.0.0/29")
+	require.NoError(t, err)
+
+	pinned := net.ParseIP("10.0.0.1")
+
+	require.NoError(t, pool.PinIP(pinned))
+	require.Error(t, pool.PinIP(pinned), "IP is already reserved")
+	require.Error(t, pool.PinIP(net.ParseIP("10.1.0.1")), "IP is not in the pool")
+
+	used, free, reserved := pool.usage()
+	require.Equal(t, []uint64{0, 5, 1}, []uint64{used, free, reserved})
+
+	ip, err := pool.LookupOrAllocIP(1)
+	require.NoError(t, err)
+	require.False(t, ip.Equal(pinned), "reserved IPs are not allocated dynamically")
+
+	ip, err = pool.AllocPinnedIP(2, pinned)
+	require.NoError(t, err)
+	require.True(t, ip.Equal(pinned))
+
+	_, err = pool.AllocPinnedIP(3, pinned)
+	require.Error(t, err, "reserved IP is in use")
+
+	_, err = pool.AllocPinnedIP(3, net.ParseIP("10.0.0.3"))
+	require.Error(t, err, "IP is not reserved")
+
+	require.NoError(t, pool.DeallocIP(2))
+
+	used, free, reserved = pool.usage()
+	require.Equal(t, []uint64{1, 4, 1}, []uint64{used, free, reserved}, "released IP stays reserved")
+
+	require.NoError(t, pool.ReserveIP(2, pinned), "sessions recovered after a restart keep their reserved IP")
+
+	require.NoError(t, pool.UnpinIP(pinned))
+	require.Error(t, pool.UnpinIP(pinned))
+	require.NoError(t, pool.DeallocIP(2))
+
+	used, free, reserved = pool.usage()
+	require.Equal(t, []uint64{1, 5, 0}, []uint64{used, free, reserved}, "unreserved IP is freed once released")
+}
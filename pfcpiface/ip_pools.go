@@ -0,0 +1,548 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/wmnsk/go-pfcp/ie"
+)
+
+// defaultPoolName is the name of the pool configured with ue_ip_pool.
+const defaultPoolName = "default"
+
+const (
+	familyIPv4 = "ipv4"
+	familyIPv6 = "ipv6"
+)
+
+// uePool is a named pool of UE addresses serving a DNN: IPv4 addresses and/or IPv6 prefixes.
+type uePool struct {
+	name string
+	dnn  string
+	ip4  *IPPool
+	ip6  *prefixPool
+
+	mu sync.Mutex
+	// reservations are the addresses reserved for UEs, by key
+	reservations map[string]ueReservation
+
+	// exhausted4 and exhausted6 count the allocations that failed for lack of addresses, they
+	// are accessed atomically
+	exhausted4 uint64
+	exhausted6 uint64
+}
+
+func newUEPool(conf UEIPPoolInfo) (*uePool, error) {
+	p := &uePool{name: conf.Name, dnn: conf.Dnn, reservations: make(map[string]ueReservation)}
+
+	var err error
+
+	if conf.IPv4Subnet != "" {
+		if p.ip4, err = NewIPPool(conf.IPv4Subnet); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.IPv6Subnet != "" {
+		if p.ip6, err = newPrefixPool(conf.IPv6Subnet, uePrefixLen); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.ip4 == nil && p.ip6 == nil {
+		return nil, ErrInvalidArgumentWithReason("UE IP pool", conf.Name, "no IPv4 nor IPv6 subnet")
+	}
+
+	return p, nil
+}
+
+// ueReservation is the IPv4 address and/or the IPv6 prefix reserved for a UE, nil if none.
+type ueReservation struct {
+	ip4 net.IP
+	ip6 net.IP
+}
+
+// ueKeys returns the keys of the reservations of the UE identified by a User ID IE: its SUPI
+// and GPSI, e.g. "imsi-001010000000001".
+func ueKeys(userID *ie.UserIDFields) []string {
+	var keys []string
+
+	if userID.IMSI != "" {
+		keys = append(keys, "imsi-"+userID.IMSI)
+	}
+
+	if userID.MSISDN != "" {
+		keys = append(keys, "msisdn-"+userID.MSISDN)
+	}
+
+	if userID.NAI != "" {
+		keys = append(keys, "nai-"+userID.NAI)
+	}
+
+	if userID.IMEI != "" {
+		keys = append(keys, "imei-"+userID.IMEI)
+	}
+
+	return keys
+}
+
+// reserve reserves the addresses of r for the UE with the given key.
+func (p *uePool) reserve(key string, r ueReservation) error {
+	if key == "" {
+		return ErrInvalidArgumentWithReason("reservation key", key, "empty key")
+	}
+
+	if r.ip4 == nil && r.ip6 == nil {
+		return ErrInvalidArgumentWithReason("reservation", key, "no IPv4 address nor IPv6 prefix")
+	}
+
+	if (r.ip4 != nil && p.ip4 == nil) || (r.ip6 != nil && p.ip6 == nil) {
+		return ErrInvalidArgumentWithReason("reservation", key, "address family not served by pool "+p.name)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.reservations[key]; ok {
+		return ErrInvalidArgumentWithReason("reservation", key, "already exists")
+	}
+
+	if r.ip4 != nil {
+		if err := p.ip4.PinIP(r.ip4); err != nil {
+			return err
+		}
+	}
+
+	if r.ip6 != nil {
+		if err := p.ip6.PinPrefix(r.ip6); err != nil {
+			if r.ip4 != nil {
+				_ = p.ip4.UnpinIP(r.ip4)
+			}
+
+			return err
+		}
+	}
+
+	p.reservations[key] = r
+
+	return nil
+}
+
+// unreserve deletes the reservation of the UE with the given key. Addresses still allocated
+// return to the pool once released.
+func (p *uePool) unreserve(key string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.reservations[key]
+	if !ok {
+		return ErrNotFoundWithParam("reservation", "key", key)
+	}
+
+	delete(p.reservations, key)
+
+	if r.ip4 != nil {
+		if err := p.ip4.UnpinIP(r.ip4); err != nil {
+			return err
+		}
+	}
+
+	if r.ip6 != nil {
+		if err := p.ip6.UnpinPrefix(r.ip6); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reservation returns the reservation of the first of keys that has one.
+func (p *uePool) reservation(keys []string) (ueReservation, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, key := range keys {
+		if r, ok := p.reservations[key]; ok {
+			return r, true
+		}
+	}
+
+	return ueReservation{}, false
+}
+
+// allReservations returns the reservations of the pool, by key.
+func (p *uePool) allReservations() map[string]ueReservation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	reservations := make(map[string]ueReservation, len(p.reservations))
+	for key, r := range p.reservations {
+		reservations[key] = r
+	}
+
+	return reservations
+}
+
+// allocIPv4 returns the IPv4 address of the session, allocating one if it has none: the
+// address reserved for the UE identified by keys, if any.
+func (p *uePool) allocIPv4(seid uint64, keys []string) (net.IP, error) {
+	if p.ip4 == nil {
+		return nil, ErrNotFoundWithParam("IPv4 subnet", "pool", p.name)
+	}
+
+	if r, ok := p.reservation(keys); ok && r.ip4 != nil {
+		return p.ip4.AllocPinnedIP(seid, r.ip4)
+	}
+
+	ip, err := p.ip4.LookupOrAllocIP(seid)
+	if err != nil {
+		atomic.AddUint64(&p.exhausted4, 1)
+		log.Warnf("UE IP pool %v: %v", p.name, err)
+
+		return nil, err
+	}
+
+	return ip, nil
+}
+
+// allocIPv6 returns the IPv6 prefix of the session, allocating one if it has none: the prefix
+// reserved for the UE identified by keys, if any.
+func (p *uePool) allocIPv6(seid uint64, keys []string) (net.IP, error) {
+	if p.ip6 == nil {
+		return nil, ErrNotFoundWithParam("IPv6 subnet", "pool", p.name)
+	}
+
+	if r, ok := p.reservation(keys); ok && r.ip6 != nil {
+		return p.ip6.AllocPinnedPrefix(seid, r.ip6)
+	}
+
+	prefix, err := p.ip6.LookupOrAllocPrefix(seid)
+	if err != nil {
+		atomic.AddUint64(&p.exhausted6, 1)
+		log.Warnf("UE IP pool %v: %v", p.name, err)
+
+		return nil, err
+	}
+
+	return prefix, nil
+}
+
+// ipPools holds the UE address pools of the UPF. The pool of a PDR is selected by its Network
+// Instance, the DNN it belongs to: the pool named for the DNN in the slice configuration if
+// any, otherwise the pool configured for the DNN, otherwise the default pool.
+type ipPools struct {
+	mu    sync.RWMutex
+	pools map[string]*uePool
+	byDnn map[string]*uePool
+	// sliceDnns maps DNNs to the name of their pool in the slice configuration
+	sliceDnns map[string]string
+	// dflt is the pool of PDRs of other DNNs, nil if there is none
+	dflt *uePool
+}
+
+func newIPPools() *ipPools {
+	return &ipPools{
+		pools:     make(map[string]*uePool),
+		byDnn:     make(map[string]*uePool),
+		sliceDnns: make(map[string]string),
+	}
+}
+
+// newUEIPPools returns the UE address pools configured in conf: the default pool of UEIPPool,
+// if set, and the named pools of UEIPPools, with the reservations of UEIPReservations.
+func newUEIPPools(conf CPIfaceInfo) (*ipPools, error) {
+	pools := newIPPools()
+
+	if conf.UEIPPool != "" {
+		if err := pools.add(UEIPPoolInfo{Name: defaultPoolName, IPv4Subnet: conf.UEIPPool}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, poolConf := range conf.UEIPPools {
+		if poolConf.Name == "" {
+			return nil, ErrInvalidArgumentWithReason("UE IP pool", poolConf, "missing name")
+		}
+
+		if err := pools.add(poolConf); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, resConf := range conf.UEIPReservations {
+		if err := pools.addReservation(resConf); err != nil {
+			return nil, err
+		}
+	}
+
+	return pools, nil
+}
+
+// addReservation adds the reservation described by conf to its pool.
+func (p *ipPools) addReservation(conf UEIPReservationInfo) error {
+	pool, err := p.get(conf.Pool)
+	if err != nil {
+		return err
+	}
+
+	var r ueReservation
+
+	if conf.IPv4 != "" {
+		if r.ip4 = net.ParseIP(conf.IPv4).To4(); r.ip4 == nil {
+			return ErrInvalidArgumentWithReason("reservation IPv4", conf.IPv4, "invalid IPv4 address")
+		}
+	}
+
+	if conf.IPv6 != "" {
+		if r.ip6 = net.ParseIP(conf.IPv6); r.ip6 == nil || !isIPv6(r.ip6) {
+			return ErrInvalidArgumentWithReason("reservation IPv6", conf.IPv6, "invalid IPv6 prefix")
+		}
+	}
+
+	return pool.reserve(conf.Key, r)
+}
+
+// add adds the pool described by conf. The first pool without DNN is the default pool.
+func (p *ipPools) add(conf UEIPPoolInfo) error {
+	pool, err := newUEPool(conf)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.pools[conf.Name]; ok {
+		return ErrInvalidArgumentWithReason("UE IP pool", conf.Name, "duplicate pool name")
+	}
+
+	if conf.Dnn != "" {
+		if other, ok := p.byDnn[conf.Dnn]; ok {
+			return ErrInvalidArgumentWithReason("UE IP pool", conf.Name, "DNN already served by pool "+other.name)
+		}
+
+		p.byDnn[conf.Dnn] = pool
+	} else if p.dflt == nil {
+		p.dflt = pool
+	}
+
+	p.pools[conf.Name] = pool
+
+	return nil
+}
+
+// setSliceResources selects the pools of DNNs as listed in the UE resources of a slice,
+// replacing the previous slice configuration.
+func (p *ipPools) setSliceResources(resources []UeResource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sliceDnns = make(map[string]string, len(resources))
+
+	for _, res := range resources {
+		if _, ok := p.pools[res.name]; !ok {
+			log.Warnf("Unknown UE IP pool %v for DNN %v in slice configuration", res.name, res.dnn)
+			continue
+		}
+
+		p.sliceDnns[res.dnn] = res.name
+	}
+}
+
+// lookup returns the pool of the DNN dnn.
+func (p *ipPools) lookup(dnn string) (*uePool, error) {
+	if p == nil {
+		return nil, ErrInvalidOperation("UE IP allocation is disabled")
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if name, ok := p.sliceDnns[dnn]; ok {
+		return p.pools[name], nil
+	}
+
+	if pool, ok := p.byDnn[dnn]; ok {
+		return pool, nil
+	}
+
+	if p.dflt == nil {
+		return nil, ErrNotFoundWithParam("UE IP pool", "DNN", dnn)
+	}
+
+	return p.dflt, nil
+}
+
+// get returns the pool called name, the default pool for an empty name.
+func (p *ipPools) get(name string) (*uePool, error) {
+	if p == nil {
+		return nil, ErrInvalidOperation("UE IP allocation is disabled")
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if name == "" && p.dflt != nil {
+		return p.dflt, nil
+	}
+
+	pool, ok := p.pools[name]
+	if !ok {
+		return nil, ErrNotFoundWithParam("UE IP pool", "name", name)
+	}
+
+	return pool, nil
+}
+
+// ueAllocations returns the pools of the UE addresses allocated by pdrs, with the families
+// allocated from each.
+func ueAllocations(pdrs []Pdr) map[string][2]bool {
+	allocs := make(map[string][2]bool)
+
+	for _, pdr := range pdrs {
+		if !pdr.AllocIPFlag && !pdr.AllocIP6Flag {
+			continue
+		}
+
+		families := allocs[pdr.UePool]
+		families[0] = families[0] || pdr.AllocIPFlag
+		families[1] = families[1] || pdr.AllocIP6Flag
+		allocs[pdr.UePool] = families
+	}
+
+	return allocs
+}
+
+// release releases the UE addresses allocated to session seid by pdrs.
+func (p *ipPools) release(seid uint64, pdrs []Pdr) error {
+	for name, families := range ueAllocations(pdrs) {
+		pool, err := p.get(name)
+		if err != nil {
+			return err
+		}
+
+		if families[0] && pool.ip4 != nil {
+			if err := pool.ip4.DeallocIP(seid); err != nil {
+				return err
+			}
+		}
+
+		if families[1] && pool.ip6 != nil {
+			if err := pool.ip6.DeallocPrefix(seid); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// reserve assigns again the UE addresses allocated to session seid by pdrs, e.g. to restore
+// them after a restart.
+func (p *ipPools) reserve(seid uint64, pdrs []Pdr) error {
+	for _, pdr := range pdrs {
+		if !pdr.AllocIPFlag && !pdr.AllocIP6Flag {
+			continue
+		}
+
+		pool, err := p.get(pdr.UePool)
+		if err != nil {
+			return err
+		}
+
+		if pdr.AllocIPFlag {
+			if pool.ip4 == nil {
+				return ErrNotFoundWithParam("IPv4 subnet", "pool", pool.name)
+			}
+
+			if err := pool.ip4.ReserveIP(seid, int2ip(pdr.UeAddress)); err != nil {
+				return err
+			}
+		}
+
+		if pdr.AllocIP6Flag {
+			if pool.ip6 == nil {
+				return ErrNotFoundWithParam("IPv6 subnet", "pool", pool.name)
+			}
+
+			if err := pool.ip6.ReservePrefix(seid, pdr.UeAddress6); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// forceRelease releases the addresses allocated to session seid in the pool called name,
+// whichever the PDRs of the session, e.g. to recover addresses leaked by a session that is gone.
+func (p *ipPools) forceRelease(name string, seid uint64) error {
+	pool, err := p.get(name)
+	if err != nil {
+		return err
+	}
+
+	released := false
+
+	if pool.ip4 != nil {
+		released = pool.ip4.DeallocIP(seid) == nil
+	}
+
+	if pool.ip6 != nil {
+		released = pool.ip6.DeallocPrefix(seid) == nil || released
+	}
+
+	if !released {
+		return ErrNotFoundWithParam("UE address allocation", "seid", seid)
+	}
+
+	log.Warnf("Forcibly released the UE addresses of session %v in pool %v", seid, name)
+
+	return nil
+}
+
+// uePoolStats is the usage of the addresses of a family in a pool.
+type uePoolStats struct {
+	pool, dnn, family    string
+	used, free, reserved uint64
+	exhausted            uint64
+}
+
+// stats returns the usage of the pools, sorted by name.
+func (p *ipPools) stats() []uePoolStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stats := make([]uePoolStats, 0, 2*len(p.pools))
+
+	for name, pool := range p.pools {
+		if pool.ip4 != nil {
+			s := uePoolStats{pool: name, dnn: pool.dnn, family: familyIPv4,
+				exhausted: atomic.LoadUint64(&pool.exhausted4)}
+			s.used, s.free, s.reserved = pool.ip4.usage()
+			stats = append(stats, s)
+		}
+
+		if pool.ip6 != nil {
+			s := uePoolStats{pool: name, dnn: pool.dnn, family: familyIPv6,
+				exhausted: atomic.LoadUint64(&pool.exhausted6)}
+			s.used, s.free, s.reserved = pool.ip6.usage()
+			stats = append(stats, s)
+		}
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].pool != stats[j].pool {
+			return stats[i].pool < stats[j].pool
+		}
+
+		return stats[i].family < stats[j].family
+	})
+
+	return stats
+}
@@ -0,0 +1,295 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+)
+
+func newTestIPPools(t *testing.T) *ipPools {
+	pools, err := newUEIPPools(CPIfaceInfo{
+		UEIPPool: "10.250.0.0/30",
+		UEIPPools: []UEIPPoolInfo{
+			{Name: "ims", Dnn: "ims", IPv4Subnet: "10.251.0.0/30", IPv6Subnet: "2001:db8:100::/63"},
+			{Name: "enterprise", Dnn: "enterprise", IPv6Subnet: "2001:db8:200::/56"},
+		},
+	})
+	require.NoError(t, err)
+
+	return pools
+}
+
+func TestNewUEIPPools(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    CPIfaceInfo
+		wantErr bool
+	}{
+		{name: "default pool only", conf: CPIfaceInfo{UEIPPool: "10.250.0.0/24"}},
+		{
+			name: "named pools only",
+			conf: CPIfaceInfo{UEIPPools: []UEIPPoolInfo{
+				{Name: "internet", IPv4Subnet: "10.250.0.0/24"},
+				{Name: "ims", Dnn: "ims", IPv6Subnet: "2001:db8::/48"},
+			}},
+		},
+		{
+			name:    "missing name",
+			conf:    CPIfaceInfo{UEIPPools: []UEIPPoolInfo{{IPv4Subnet: "10.250.0.0/24"}}},
+			wantErr: true,
+		},
+		{
+			name:    "missing subnets",
+			conf:    CPIfaceInfo{UEIPPools: []UEIPPoolInfo{{Name: "ims"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid IPv6 subnet",
+			conf:    CPIfaceInfo{UEIPPools: []UEIPPoolInfo{{Name: "ims", IPv6Subnet: "10.0.0.0/8"}}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate name",
+			conf: CPIfaceInfo{UEIPPool: "10.250.0.0/24", UEIPPools: []UEIPPoolInfo{
+				{Name: defaultPoolName, IPv4Subnet: "10.251.0.0/24"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate DNN",
+			conf: CPIfaceInfo{UEIPPools: []UEIPPoolInfo{
+				{Name: "ims", Dnn: "ims", IPv4Subnet: "10.251.0.0/24"},
+				{Name: "ims2", Dnn: "ims", IPv4Subnet: "10.252.0.0/24"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newUEIPPools(tt.conf)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIPPools_lookup(t *testing.T) {
+	pools := newTestIPPools(t)
+
+	lookup := func(dnn string) string {
+		pool, err := pools.lookup(dnn)
+		require.NoError(t, err)
+
+		return pool.name
+	}
+
+	require.Equal(t, "ims", lookup("ims"))
+	require.Equal(t, defaultPoolName, lookup("internet"), "other DNNs use the default pool")
+	require.Equal(t, defaultPoolName, lookup(""))
+
+	pools.setSliceResources([]UeResource{
+		{name: "enterprise", dnn: "internet"},
+		{name: "unknown", dnn: "ims"},
+	})
+	require.Equal(t, "enterprise", lookup("internet"), "the slice selects the pool of its DNNs")
+	require.Equal(t, "ims", lookup("ims"), "unknown pools of the slice are ignored")
+
+	pools.setSliceResources(nil)
+	require.Equal(t, defaultPoolName, lookup("internet"), "a new slice configuration replaces the previous one")
+
+	t.Run("no default pool", func(t *testing.T) {
+		pools, err := newUEIPPools(CPIfaceInfo{UEIPPools: []UEIPPoolInfo{
+			{Name: "ims", Dnn: "ims", IPv4Subnet: "10.251.0.0/24"},
+		}})
+		require.NoError(t, err)
+
+		_, err = pools.lookup("internet")
+		require.Error(t, err)
+	})
+
+	t.Run("allocation disabled", func(t *testing.T) {
+		var pools *ipPools
+
+		_, err := pools.lookup("internet")
+		require.Error(t, err)
+	})
+}
+
+func TestIPPools_exhaustion(t *testing.T) {
+	pools := newTestIPPools(t)
+
+	ims, err := pools.get("ims")
+	require.NoError(t, err)
+
+	for seid := uint64(1); seid <= 2; seid++ {
+		_, err = ims.allocIPv4(seid, nil)
+		require.NoError(t, err)
+
+		_, err = ims.allocIPv6(seid, nil)
+		require.NoError(t, err)
+	}
+
+	_, err = ims.allocIPv4(3, nil)
+	require.ErrorIs(t, err, errExhausted)
+
+	_, err = ims.allocIPv6(3, nil)
+	require.ErrorIs(t, err, errExhausted)
+
+	// Other pools are unaffected
+	dflt, err := pools.get("")
+	require.NoError(t, err)
+
+	_, err = dflt.allocIPv4(3, nil)
+	require.NoError(t, err)
+
+	_, err = dflt.allocIPv6(3, nil)
+	require.Error(t, err, "the default pool has no IPv6 subnet")
+	require.NotErrorIs(t, err, errExhausted)
+
+	require.Equal(t, []uePoolStats{
+		{pool: defaultPoolName, family: familyIPv4, used: 1, free: 1},
+		{pool: "enterprise", dnn: "enterprise", family: familyIPv6, used: 0, free: 256},
+		{pool: "ims", dnn: "ims", family: familyIPv4, used: 2, free: 0, exhausted: 1},
+		{pool: "ims", dnn: "ims", family: familyIPv6, used: 2, free: 0, exhausted: 1},
+	}, pools.stats())
+}
+
+func TestIPPools_releaseAndReserve(t *testing.T) {
+	pools := newTestIPPools(t)
+
+	const seid = 1
+
+	p := Pdr{FseID: seid}
+	require.NoError(t, p.parseUEAddressIE(ie.NewUEIPAddress(0x30, "", "", 0, 0), pools, "ims", nil))
+	require.Equal(t, "ims", p.UePool)
+
+	pdrs := []Pdr{p, p}
+
+	require.NoError(t, pools.release(seid, pdrs))
+	require.Error(t, pools.release(seid, pdrs), "addresses are already released")
+
+	require.NoError(t, pools.reserve(seid, pdrs))
+	require.NoError(t, pools.reserve(seid, pdrs), "reserving the same addresses again is a no-op")
+	require.Error(t, pools.reserve(seid+1, pdrs), "addresses are in use")
+
+	ims, err := pools.get("ims")
+	require.NoError(t, err)
+
+	ip, err := ims.allocIPv4(seid, nil)
+	require.NoError(t, err)
+	require.True(t, ip.Equal(int2ip(p.UeAddress)))
+
+	prefix, err := ims.allocIPv6(seid, nil)
+	require.NoError(t, err)
+	require.True(t, prefix.Equal(p.UeAddress6))
+
+	p.UePool = "unknown"
+	require.Error(t, pools.reserve(seid, []Pdr{p}))
+}
+
+func TestNewUEAllocations(t *testing.T) {
+	session := &PFCPSession{PacketForwardingRules: PacketForwardingRules{
+		Pdrs: []Pdr{{AllocIPFlag: true, UePool: "ims"}},
+	}}
+
+	pdrs := []Pdr{
+		{PdrID: 1, AllocIPFlag: true, AllocIP6Flag: true, UePool: "ims"},
+		{PdrID: 2, AllocIPFlag: true, UePool: "ims"},
+		{PdrID: 3, UeAddress6: net.ParseIP("2001:db8::")},
+	}
+
+	require.Equal(t, []Pdr{{PdrID: 1, AllocIP6Flag: true, UePool: "ims"}}, newUEAllocations(pdrs, session),
+		"only the IPv6 prefix is new")
+	require.Len(t, newUEAllocations(pdrs, &PFCPSession{}), 2)
+}
+
+func TestIPPools_reservations(t *testing.T) {
+	pools, err := newUEIPPools(CPIfaceInfo{
+		UEIPPool: "10.250.0.0/29",
+		UEIPPools: []UEIPPoolInfo{
+			{Name: "ims", Dnn: "ims", IPv4Subnet: "10.251.0.0/29", IPv6Subnet: "2001:db8:100::/62"},
+		},
+		UEIPReservations: []UEIPReservationInfo{
+			{Key: "imsi-001010000000001", IPv4: "10.250.0.5"},
+			{Key: "imsi-001010000000001", Pool: "ims", IPv4: "10.251.0.5", IPv6: "2001:db8:100:2::"},
+		},
+	})
+	require.NoError(t, err)
+
+	keys := ueKeys(&ie.UserIDFields{IMSI: "001010000000001", MSISDN: "15551234567"})
+	require.Equal(t, []string{"imsi-001010000000001", "msisdn-15551234567"}, keys)
+
+	dflt, err := pools.get("")
+	require.NoError(t, err)
+
+	ip, err := dflt.allocIPv4(1, keys)
+	require.NoError(t, err)
+	require.True(t, ip.Equal(net.ParseIP("10.250.0.5")))
+
+	ims, err := pools.get("ims")
+	require.NoError(t, err)
+
+	ip, err = ims.allocIPv4(1, keys)
+	require.NoError(t, err)
+	require.True(t, ip.Equal(net.ParseIP("10.251.0.5")))
+
+	prefix, err := ims.allocIPv6(1, keys)
+	require.NoError(t, err)
+	require.True(t, prefix.Equal(net.ParseIP("2001:db8:100:2::")))
+
+	ip, err = ims.allocIPv4(2, []string{"imsi-001010000000002"})
+	require.NoError(t, err)
+	require.False(t, ip.Equal(net.ParseIP("10.251.0.5")), "UEs without reservation get other addresses")
+
+	t.Run("invalid reservations", func(t *testing.T) {
+		for _, conf := range []UEIPReservationInfo{
+			{Key: "", IPv4: "10.250.0.2"},
+			{Key: "a"},
+			{Key: "b", Pool: "unknown", IPv4: "10.250.0.2"},
+			{Key: "c", IPv4: "10.251.0.2"},
+			{Key: "d", IPv4: "2001:db8:100::"},
+			{Key: "e", IPv6: "2001:db8:100::"},
+			{Key: "f", Pool: "ims", IPv6: "2001:db8:100:1::1"},
+			{Key: "imsi-001010000000001", IPv4: "10.250.0.2"},
+			{Key: "g", IPv4: "10.250.0.5"},
+		} {
+			require.Error(t, pools.addReservation(conf), conf)
+		}
+
+		require.Error(t, pools.addReservation(UEIPReservationInfo{
+			Key: "h", Pool: "ims", IPv4: "10.251.0.2", IPv6: "2001:db8:100:2::",
+		}), "prefix is reserved")
+		require.NoError(t, pools.addReservation(UEIPReservationInfo{Key: "h", Pool: "ims", IPv4: "10.251.0.2"}),
+			"a failed reservation leaves no address reserved")
+	})
+
+	t.Run("forced release", func(t *testing.T) {
+		require.NoError(t, pools.forceRelease("ims", 1))
+		require.Error(t, pools.forceRelease("ims", 1))
+		require.Error(t, pools.forceRelease("unknown", 1))
+
+		require.Equal(t, map[string]ueReservation{
+			"imsi-001010000000001": {ip4: net.ParseIP("10.251.0.5").To4(), ip6: net.ParseIP("2001:db8:100:2::")},
+			"h":                    {ip4: net.ParseIP("10.251.0.2").To4()},
+		}, ims.allReservations())
+
+		require.NoError(t, ims.unreserve("imsi-001010000000001"))
+		require.Error(t, ims.unreserve("imsi-001010000000001"))
+
+		for _, s := range pools.stats() {
+			if s.pool == "ims" && s.family == familyIPv6 {
+				require.Equal(t, uint64(0), s.reserved)
+				require.Equal(t, uint64(4), s.free)
+			}
+		}
+	})
+}
@@ -4,6 +4,7 @@
 package pfcpiface
 
 import (
+	"net"
 	"sync"
 
 	"go.uber.org/zap"
@@ -14,10 +15,16 @@ type InMemoryStore struct {
 	// sync.Map is optimized for case when multiple goroutines
 	// read, write, and overwrite entries for disjoint sets of keys.
 	sessions sync.Map
+
+	// mu serializes writes, so that indexes stay consistent with sessions.
+	mu      sync.RWMutex
+	indexes *sessionIndexes
 }
 
 func NewInMemoryStore() *InMemoryStore {
-	return &InMemoryStore{}
+	return &InMemoryStore{
+		indexes: newSessionIndexes(),
+	}
 }
 
 func (i *InMemoryStore) GetAllSessions() []PFCPSession {
@@ -42,7 +49,17 @@ func (i *InMemoryStore) PutSession(session PFCPSession) error {
 		return ErrInvalidArgument("session.localSEID", session.localSEID)
 	}
 
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var old *PFCPSession
+	if prev, ok := i.sessions.Load(session.localSEID); ok {
+		prevSession := prev.(PFCPSession)
+		old = &prevSession
+	}
+
 	i.sessions.Store(session.localSEID, session)
+	i.indexes.update(old, &session)
 
 	log.Debugw(
 		"Saved PFCP sessions to local store",
@@ -53,6 +70,14 @@ func (i *InMemoryStore) PutSession(session PFCPSession) error {
 }
 
 func (i *InMemoryStore) DeleteSession(fseid uint64) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if prev, ok := i.sessions.Load(fseid); ok {
+		session := prev.(PFCPSession)
+		i.indexes.remove(&session)
+	}
+
 	i.sessions.Delete(fseid)
 
 	log.Debugw(
@@ -64,6 +89,11 @@ func (i *InMemoryStore) DeleteSession(fseid uint64) error {
 }
 
 func (i *InMemoryStore) DeleteAllSessions() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.indexes = newSessionIndexes()
+
 	i.sessions.Range(func(key, value interface{}) bool {
 		i.sessions.Delete(key)
 		return true
@@ -92,3 +122,28 @@ func (i *InMemoryStore) GetSession(fseid uint64) (PFCPSession, bool) {
 
 	return session, ok
 }
+
+// getSessionBy returns the session whose local SEID is found by lookup in the indexes.
+func (i *InMemoryStore) getSessionBy(lookup func(x *sessionIndexes) (uint64, bool)) (PFCPSession, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	seid, ok := lookup(i.indexes)
+	if !ok {
+		return PFCPSession{}, false
+	}
+
+	return i.GetSession(seid)
+}
+
+func (i *InMemoryStore) GetSessionByUEAddress(ueAddress net.IP) (PFCPSession, bool) {
+	return i.getSessionBy(func(x *sessionIndexes) (uint64, bool) { return x.byUEAddress(ueAddress) })
+}
+
+func (i *InMemoryStore) GetSessionByFTEID(teid uint32, ip net.IP) (PFCPSession, bool) {
+	return i.getSessionBy(func(x *sessionIndexes) (uint64, bool) { return x.byFTEID(teid, ip) })
+}
+
+func (i *InMemoryStore) GetSessionByRemoteFSEID(seid uint64, ip net.IP) (PFCPSession, bool) {
+	return i.getSessionBy(func(x *sessionIndexes) (uint64, bool) { return x.byRemoteFSEID(seid, ip) })
+}
@@ -9,7 +9,7 @@ import (
 
 	"github.com/wmnsk/go-pfcp/message"
 
-	"github.com/omec-project/upf-epc/pfcpiface/metrics"
+	"github.com/ardzoht/omec-upf/pfcpiface/metrics"
 )
 
 var errMsgUnexpectedType = errors.New("unable to parse message as type specified")
@@ -70,6 +70,15 @@ func (pConn *PFCPConn) HandlePFCPMsg(buf []byte) {
 	msgType := msg.MessageTypeName()
 	m := metrics.NewMessage(msgType, "Incoming")
 
+	// A request retransmitted because our response was lost must not be processed again
+	if cached, ok := pConn.responses.get(msg, time.Now()); ok {
+		log.Debugf("Answering retransmitted %v from %v with cached response", msgType, addr)
+		pConn.SaveRetransmittedRequest(pConn.nodeID.remote, msgType)
+		pConn.SendPFCPMsg(cached)
+
+		return
+	}
+
 	switch msg.MessageType() {
 	// Connection related messages
 	case message.MsgTypeHeartbeatRequest:
@@ -81,8 +90,8 @@ func (pConn *PFCPConn) HandlePFCPMsg(buf []byte) {
 		if reply != nil && err == nil && pConn.upf.enableHBTimer {
 			go pConn.startHeartBeatMonitor()
 		}
-		// TODO: Cleanup sessions
-
+	case message.MsgTypeAssociationUpdateRequest:
+		reply, err = pConn.handleAssociationUpdateRequest(msg)
 	case message.MsgTypeAssociationReleaseRequest:
 		reply, err = pConn.handleAssociationReleaseRequest(msg)
 		defer pConn.Shutdown()
@@ -94,12 +103,20 @@ func (pConn *PFCPConn) HandlePFCPMsg(buf []byte) {
 		reply, err = pConn.handleSessionModificationRequest(msg)
 	case message.MsgTypeSessionDeletionRequest:
 		reply, err = pConn.handleSessionDeletionRequest(msg)
+	case message.MsgTypeSessionSetDeletionRequest:
+		reply, err = pConn.handleSessionSetDeletionRequest(msg)
 	case message.MsgTypeSessionReportResponse:
 		err = pConn.handleSessionReportResponse(msg)
+		// Usage reports are retransmitted until they are answered
+		pConn.handleIncomingResponse(msg)
 
 	// Incoming response messages
 	// TODO: Session Report Request
-	case message.MsgTypeAssociationSetupResponse, message.MsgTypeHeartbeatResponse:
+	case message.MsgTypeHeartbeatResponse:
+		pConn.handleHeartbeatResponse(msg)
+		pConn.handleIncomingResponse(msg)
+	case message.MsgTypeAssociationSetupResponse, message.MsgTypeAssociationUpdateResponse,
+		message.MsgTypeNodeReportResponse:
 		pConn.handleIncomingResponse(msg)
 
 	default:
@@ -120,38 +137,11 @@ func (pConn *PFCPConn) HandlePFCPMsg(buf []byte) {
 	pConn.SaveMessages(m)
 
 	if reply != nil {
+		pConn.responses.put(msg, reply, time.Now())
 		pConn.SendPFCPMsg(reply)
 	}
 }
 
-func (pConn *PFCPConn) SendPFCPMsg(msg message.Message) {
-	addr := pConn.RemoteAddr().String()
-	nodeID := pConn.nodeID.remote
-	msgType := msg.MessageTypeName()
-
-	m := metrics.NewMessage(msgType, "Outgoing")
-	defer pConn.SaveMessages(m)
-
-	out := make([]byte, msg.MarshalLen())
-
-	if err := msg.MarshalTo(out); err != nil {
-		m.Finish(nodeID, "Failure")
-		log.Errorf("Failed to marshal %v for %v %v", msgType, addr, err)
-
-		return
-	}
-
-	if _, err := pConn.Write(out); err != nil {
-		m.Finish(nodeID, "Failure")
-		log.Errorf("Failed to transmit %v to %v %v", msgType, addr, err)
-
-		return
-	}
-
-	m.Finish(nodeID, "Success")
-	log.Debugf("Sent %v to %v", msgType, addr)
-}
-
 func (pConn *PFCPConn) sendPFCPRequestMessage(r *Request) (message.Message, bool) {
 	pConn.pendingReqs.Store(r.msg.Sequence(), r)
 
@@ -166,6 +156,7 @@ func (pConn *PFCPConn) sendPFCPRequestMessage(r *Request) (message.Message, bool
 				pConn.SendPFCPMsg(r.msg)
 				retriesLeft--
 			} else {
+				pConn.pendingReqs.Delete(r.msg.Sequence())
 				return nil, true
 			}
 		} else {
@@ -5,6 +5,7 @@ package pfcpiface
 
 import (
 	"errors"
+	"net"
 
 	"github.com/wmnsk/go-pfcp/ie"
 	"github.com/wmnsk/go-pfcp/message"
@@ -13,6 +14,13 @@ import (
 var errFlowDescAbsent = errors.New("flow description not present")
 var errDatapathDown = errors.New("datapath down")
 var errReqRejected = errors.New("request rejected")
+var errUPFDraining = errors.New("UPF is draining")
+
+// Node Report Type flags.
+const (
+	nodeReportTypeUPFR = 0x01 // User Plane Path Failure Report
+	nodeReportTypeUPRR = 0x02 // User Plane Path Recovery Report
+)
 
 func (pConn *PFCPConn) sendAssociationRequest() {
 	// Build request message
@@ -40,6 +48,91 @@ func (pConn *PFCPConn) sendAssociationRequest() {
 	}
 }
 
+// remoteGTPUPeers returns a Remote GTP-U Peer IE for every distinct GTP-U peer the sessions of
+// the peer tunnel packets to, along with the destination interface of the tunnel.
+func (pConn *PFCPConn) remoteGTPUPeers() []*ie.IE {
+	type gtpuPeer struct {
+		v4, v6  string
+		dstIntf uint8
+	}
+
+	seen := make(map[gtpuPeer]bool)
+
+	var peers []*ie.IE
+
+	for _, session := range pConn.store.GetAllSessions() {
+		for _, far := range session.Fars {
+			flags := uint8(0x04) // DI
+			peer := gtpuPeer{dstIntf: far.DstIntf}
+
+			switch {
+			case far.IsIPv6():
+				flags |= 0x01 // V6
+				peer.v6 = far.TunnelIP6Dst.String()
+			case far.TunnelIP4Dst != 0:
+				flags |= 0x02 // V4
+				peer.v4 = int2ip(far.TunnelIP4Dst).String()
+			default:
+				continue
+			}
+
+			if seen[peer] {
+				continue
+			}
+
+			seen[peer] = true
+
+			peers = append(peers, ie.NewRemoteGTPUPeer(flags, peer.v4, peer.v6, peer.dstIntf, ""))
+		}
+	}
+
+	return peers
+}
+
+// sendNodeReportRequest informs the peer that the user plane paths to the remote GTP-U peers of
+// its sessions have been recovered, e.g. after the datapath came back up.
+func (pConn *PFCPConn) sendNodeReportRequest() {
+	if pConn.nodeID.remote == "" {
+		// No association established yet
+		return
+	}
+
+	peers := pConn.remoteGTPUPeers()
+	if len(peers) == 0 {
+		// No user plane path to report
+		return
+	}
+
+	nrreq := message.NewNodeReportRequest(pConn.getSeqNum(),
+		pConn.nodeID.localIE,
+		ie.NewNodeReportType(nodeReportTypeUPRR),
+		ie.NewGroupedIE(ie.UserPlanePathRecoveryReport, peers...),
+	)
+
+	r := newRequest(nrreq)
+
+	reply, timeout := pConn.sendPFCPRequestMessage(r)
+	if timeout {
+		log.Warn("Node Report Request timed out for ", pConn.RemoteAddr())
+		return
+	}
+
+	if reply == nil {
+		return
+	}
+
+	nrres, ok := reply.(*message.NodeReportResponse)
+	if !ok {
+		log.Error("Unexpected reply to Node Report Request: ", reply.MessageTypeName())
+		return
+	}
+
+	cause, err := nrres.Cause.Cause()
+	if err != nil || cause != ie.CauseRequestAccepted {
+		log.Warnf("Node Report Request to %v not accepted, cause: %v %v", pConn.RemoteAddr(), cause, err)
+	}
+}
+
 func (pConn *PFCPConn) getHeartBeatRequest() *Request {
 	seq := pConn.getSeqNum()
 
@@ -70,7 +163,11 @@ func (pConn *PFCPConn) handleHeartbeatRequest(msg message.Message) (message.Mess
 		}
 	}
 
-	// TODO: Check and update remote recovery timestamp
+	if hbreq.RecoveryTimeStamp != nil {
+		if ts, err := hbreq.RecoveryTimeStamp.RecoveryTimeStamp(); err == nil {
+			pConn.handlePeerRecovery(ts)
+		}
+	}
 
 	// Build response message
 	hbres := message.NewHeartbeatResponse(hbreq.SequenceNumber,
@@ -80,6 +177,19 @@ func (pConn *PFCPConn) handleHeartbeatRequest(msg message.Message) (message.Mess
 	return hbres, nil
 }
 
+// handleHeartbeatResponse checks the recovery timestamp of the peer in the response to a
+// Heartbeat Request of the UPF.
+func (pConn *PFCPConn) handleHeartbeatResponse(msg message.Message) {
+	hbres, ok := msg.(*message.HeartbeatResponse)
+	if !ok || hbres.RecoveryTimeStamp == nil {
+		return
+	}
+
+	if ts, err := hbres.RecoveryTimeStamp.RecoveryTimeStamp(); err == nil {
+		pConn.handlePeerRecovery(ts)
+	}
+}
+
 func (pConn *PFCPConn) handleIncomingResponse(msg message.Message) {
 	req, ok := pConn.pendingReqs.Load(msg.Sequence())
 
@@ -100,6 +210,62 @@ func (pConn *PFCPConn) associationIEs() []*ie.IE {
 		flags = uint8(0x61)
 	}
 
+	accessIP6 := ""
+	if upf.AccessIP6 != nil {
+		// add IPV6 flag to advertise the IPv6 address of the access interface.
+		flags |= 0x02
+		accessIP6 = upf.AccessIP6.String()
+	}
+
+	ies := []*ie.IE{
+		ie.NewRecoveryTimeStamp(pConn.ts.local),
+		pConn.nodeID.localIE,
+		// 0x41 = Spare (0) | Assoc Src Inst (1) | Assoc Net Inst (0) | Tied Range (000) | IPV6 (0) | IPV4 (1)
+		//      = 01000001
+		ie.NewUserPlaneIPResourceInformation(flags, 0, upf.AccessIP.String(), accessIP6, networkInstance,
+			ie.SrcInterfaceAccess),
+		// ie.NewUserPlaneIPResourceInformation(0x41, 0, coreIP, "", "", ie.SrcInterfaceCore),
+		pConn.upFunctionFeatures(),
+	}
+
+	for _, ni := range upf.networkInstances {
+		if ni.accessIP != nil || ni.accessIP6 != nil {
+			ies = append(ies, userPlaneIPResource(ni.accessIP, ni.accessIP6, ni.name, ie.SrcInterfaceAccess))
+		}
+
+		if ni.coreIP != nil || ni.coreIP6 != nil {
+			ies = append(ies, userPlaneIPResource(ni.coreIP, ni.coreIP6, ni.name, ie.SrcInterfaceCore))
+		}
+	}
+
+	return ies
+}
+
+// userPlaneIPResource returns the User Plane IP Resource Information IE advertising the given
+// addresses of an interface of a network instance.
+func userPlaneIPResource(ip4, ip6 net.IP, networkInstance string, srcIface uint8) *ie.IE {
+	// Assoc Src Inst (1) | Assoc Net Inst (1)
+	flags := uint8(0x60)
+
+	var ip4Str, ip6Str string
+
+	if ip4 != nil {
+		flags |= 0x01
+		ip4Str = ip4.String()
+	}
+
+	if ip6 != nil {
+		flags |= 0x02
+		ip6Str = ip6.String()
+	}
+
+	return ie.NewUserPlaneIPResourceInformation(flags, 0, ip4Str, ip6Str,
+		string(ie.NewNetworkInstanceFQDN(networkInstance).Payload), srcIface)
+}
+
+// upFunctionFeatures returns the UP Function Features IE advertising the features enabled.
+func (pConn *PFCPConn) upFunctionFeatures() *ie.IE {
+	upf := pConn.upf
 	features := make([]uint8, 4)
 
 	// FTUP support
@@ -113,17 +279,7 @@ func (pConn *PFCPConn) associationIEs() []*ie.IE {
 		setEndMarkerFeature(features...)
 	}
 
-	ies := []*ie.IE{
-		ie.NewRecoveryTimeStamp(pConn.ts.local),
-		pConn.nodeID.localIE,
-		// 0x41 = Spare (0) | Assoc Src Inst (1) | Assoc Net Inst (0) | Tied Range (000) | IPV6 (0) | IPV4 (1)
-		//      = 01000001
-		ie.NewUserPlaneIPResourceInformation(flags, 0, upf.AccessIP.String(), "", networkInstance, ie.SrcInterfaceAccess),
-		// ie.NewUserPlaneIPResourceInformation(0x41, 0, coreIP, "", "", ie.SrcInterfaceCore),
-		ie.NewUPFunctionFeatures(features...),
-	}
-
-	return ies
+	return ie.NewUPFunctionFeatures(features...)
 }
 
 func (pConn *PFCPConn) handleAssociationSetupRequest(msg message.Message) (message.Message, error) {
@@ -154,16 +310,17 @@ func (pConn *PFCPConn) handleAssociationSetupRequest(msg message.Message) (messa
 		return asres, errProcess(errDatapathDown)
 	}
 
+	if upf.isDraining() {
+		asres.Cause = ie.NewCause(ie.CauseRequestRejected)
+		return asres, errProcess(errUPFDraining)
+	}
+
 	if pConn.ts.remote.IsZero() {
-		pConn.ts.remote = ts
 		log.Infof("Association Setup Request from %v with recovery timestamp: %v", addr, ts)
-	} else if ts.After(pConn.ts.remote) {
-		old := pConn.ts.remote
-		pConn.ts.remote = ts
-		log.Warnf("Association Setup Request from %v with newer recovery timestamp: %v older: %v", addr, ts, old)
 	}
 
 	pConn.nodeID.remote = nodeID
+	pConn.handleAssociation(nodeID, ts)
 	asres.Cause = ie.NewCause(ie.CauseRequestAccepted)
 
 	log.Infof("Association setup done between nodes locals: %v remote: %v", pConn.nodeID.local, pConn.nodeID.remote)
@@ -200,20 +357,91 @@ func (pConn *PFCPConn) handleAssociationSetupResponse(msg message.Message) error
 	}
 
 	if pConn.ts.remote.IsZero() {
-		pConn.ts.remote = ts
 		log.Infof("Association Setup Response from %v with recovery timestamp: %v", addr, ts)
-	} else if ts.After(pConn.ts.remote) {
-		old := pConn.ts.remote
-		pConn.ts.remote = ts
-		log.Warnf("Association Setup Response from %v with newer recovery timestamp: %v older: %v", addr, ts, old)
 	}
 
 	pConn.nodeID.remote = nodeID
+	pConn.handleAssociation(nodeID, ts)
 	log.Infof("Association setup done between nodes local: %v remote: %v", pConn.nodeID.local, pConn.nodeID.remote)
 
 	return nil
 }
 
+// sendAssociationUpdateRequest sends an Association Update Request advertising the current UP
+// function features, along with ies, e.g. to request the release of the association.
+func (pConn *PFCPConn) sendAssociationUpdateRequest(ies ...*ie.IE) {
+	if pConn.nodeID.remote == "" {
+		// No association established yet
+		return
+	}
+
+	aureq := message.NewAssociationUpdateRequest(pConn.getSeqNum(),
+		append([]*ie.IE{pConn.nodeID.localIE, pConn.upFunctionFeatures()}, ies...)...,
+	)
+
+	reply, timeout := pConn.sendPFCPRequestMessage(newRequest(aureq))
+	if timeout {
+		log.Warn("Association Update Request timed out for ", pConn.RemoteAddr())
+		return
+	}
+
+	if reply == nil {
+		return
+	}
+
+	aures, ok := reply.(*message.AssociationUpdateResponse)
+	if !ok {
+		log.Error("Unexpected reply to Association Update Request: ", reply.MessageTypeName())
+		return
+	}
+
+	cause, err := aures.Cause.Cause()
+	if err != nil || cause != ie.CauseRequestAccepted {
+		log.Warnf("Association Update Request to %v not accepted, cause: %v %v", pConn.RemoteAddr(), cause, err)
+	}
+}
+
+func (pConn *PFCPConn) handleAssociationUpdateRequest(msg message.Message) (message.Message, error) {
+	aureq, ok := msg.(*message.AssociationUpdateRequest)
+	if !ok {
+		return nil, errUnmarshal(errMsgUnexpectedType)
+	}
+
+	nodeID, err := aureq.NodeID.NodeID()
+	if err != nil {
+		return nil, errUnmarshal(err)
+	}
+
+	// Build response message
+	aures := message.NewAssociationUpdateResponse(aureq.SequenceNumber,
+		pConn.nodeID.localIE,
+		pConn.upFunctionFeatures(),
+	)
+
+	if nodeID != pConn.nodeID.remote {
+		log.Warnf("Association not found for Update request with nodeID: %v, Association NodeID: %v",
+			nodeID, pConn.nodeID.remote)
+
+		aures.Cause = ie.NewCause(ie.CauseNoEstablishedPFCPAssociation)
+
+		return aures, errProcess(ErrAssocNotFound)
+	}
+
+	if aureq.CPFunctionFeatures != nil {
+		features, err := aureq.CPFunctionFeatures.CPFunctionFeatures()
+		if err != nil {
+			aures.Cause = ie.NewCause(ie.CauseRequestRejected)
+			return aures, errUnmarshal(err)
+		}
+
+		log.Infof("Association Update Request from %v with CP function features: %#x", nodeID, features)
+	}
+
+	aures.Cause = ie.NewCause(ie.CauseRequestAccepted)
+
+	return aures, nil
+}
+
 func (pConn *PFCPConn) handleAssociationReleaseRequest(msg message.Message) (message.Message, error) {
 	arreq, ok := msg.(*message.AssociationReleaseRequest)
 	if !ok {
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+	"github.com/wmnsk/go-pfcp/message"
+)
+
+func TestPFCPConn_HandleAssociationUpdateRequest(t *testing.T) {
+	pConn := newTestPFCPConn(t, NewRecordingDatapath())
+
+	update := func(t *testing.T, ies ...*ie.IE) (*message.AssociationUpdateResponse, error) {
+		reply, err := pConn.handleAssociationUpdateRequest(message.NewAssociationUpdateRequest(1, ies...))
+
+		aures, ok := reply.(*message.AssociationUpdateResponse)
+		require.True(t, ok)
+		require.NotNil(t, aures.UPFunctionFeatures)
+
+		return aures, err
+	}
+
+	aures, err := update(t, ie.NewNodeID(testSMFNodeID, "", ""), ie.NewCPFunctionFeatures(0x01))
+	require.NoError(t, err)
+	requireCause(t, ie.CauseRequestAccepted, aures.Cause)
+
+	aures, err = update(t, ie.NewNodeID("10.0.0.99", "", ""))
+	require.Error(t, err)
+	requireCause(t, ie.CauseNoEstablishedPFCPAssociation, aures.Cause)
+}
+
+func TestPFCPConn_associationIEs(t *testing.T) {
+	upf := newTestUpf(NewRecordingDatapath())
+
+	var err error
+
+	upf.networkInstances, err = newNetworkInstances([]NetworkInstanceInfo{
+		{Name: "enterprise", AccessIP: "192.168.10.1", CoreIP6: "2001:db8:11::1"},
+	}, false)
+	require.NoError(t, err)
+
+	pConn := newTestPFCPConnWithUpf(t, upf)
+
+	var resources []*ie.IE
+
+	for _, i := range pConn.associationIEs() {
+		if i.Type == ie.UserPlaneIPResourceInformation {
+			resources = append(resources, i)
+		}
+	}
+
+	enterprise := string(ie.NewNetworkInstanceFQDN("enterprise").Payload)
+
+	// The default instance, then both interfaces of the named one. ASSONI and ASSOSI are set for
+	// the named one, with IPv4 on access and IPv6 on core.
+	require.Len(t, resources, 3)
+	require.Equal(t, ie.NewUserPlaneIPResourceInformation(0x61, 0, "192.168.10.1", "", enterprise,
+		ie.SrcInterfaceAccess), resources[1])
+	require.Equal(t, ie.NewUserPlaneIPResourceInformation(0x62, 0, "", "2001:db8:11::1", enterprise,
+		ie.SrcInterfaceCore), resources[2])
+}
+
+func TestPFCPConn_remoteGTPUPeers(t *testing.T) {
+	pConn := newTestPFCPConn(t, NewRecordingDatapath())
+	require.Empty(t, pConn.remoteGTPUPeers())
+
+	establishTestSession(t, pConn)
+
+	// The gNB the downlink FAR tunnels to, not the UPF itself
+	require.Equal(t, []*ie.IE{ie.NewRemoteGTPUPeer(0x06, "192.168.0.2", "", ie.DstInterfaceAccess, "")},
+		pConn.remoteGTPUPeers())
+}
+
+func TestPFCPConn_PeerRestart(t *testing.T) {
+	dp := NewRecordingDatapath()
+	pConn := newTestPFCPConn(t, dp)
+	start := time.Now().Truncate(time.Second)
+
+	heartbeat := func(ts time.Time) {
+		_, err := pConn.handleHeartbeatRequest(message.NewHeartbeatRequest(1, ie.NewRecoveryTimeStamp(ts), nil))
+		require.NoError(t, err)
+	}
+
+	heartbeat(start)
+	establishTestSession(t, pConn)
+
+	heartbeat(start)
+	heartbeat(start.Add(-time.Hour))
+	require.Len(t, pConn.store.GetAllSessions(), 1, "sessions are kept while the peer doesn't restart")
+
+	heartbeat(start.Add(time.Hour))
+	require.Empty(t, pConn.store.GetAllSessions(), "sessions of a restarted peer are deleted")
+	require.Empty(t, dp.Rules().Pdrs)
+}
+
+func TestPFCPConn_StaleSessions(t *testing.T) {
+	setup := func(t *testing.T, pConn *PFCPConn, ts time.Time) {
+		asreq := message.NewAssociationSetupRequest(1, ie.NewNodeID(testSMFNodeID, "", ""), ie.NewRecoveryTimeStamp(ts))
+
+		reply, err := pConn.handleAssociationSetupRequest(asreq)
+		require.NoError(t, err)
+		requireCause(t, ie.CauseRequestAccepted, reply.(*message.AssociationSetupResponse).Cause)
+	}
+
+	start := time.Now().Truncate(time.Second)
+
+	t.Run("sessions are deleted at once without grace period", func(t *testing.T) {
+		pConn := newTestPFCPConn(t, NewRecordingDatapath())
+		setup(t, pConn, start)
+		establishTestSession(t, pConn)
+
+		setup(t, pConn, start)
+		require.Len(t, pConn.store.GetAllSessions(), 1, "sessions are kept if the peer didn't restart")
+
+		setup(t, pConn, start.Add(time.Hour))
+		require.Empty(t, pConn.store.GetAllSessions())
+	})
+
+	t.Run("sessions reconciled in the grace period are kept", func(t *testing.T) {
+		dp := NewRecordingDatapath()
+		pConn := newTestPFCPConn(t, dp)
+		pConn.upf.staleSessionGrace = 50 * time.Millisecond
+
+		setup(t, pConn, start)
+
+		reconciled := establishTestSession(t, pConn)
+		addTestURR(t, pConn, reconciled, 1000)
+
+		sereq := newTestEstablishmentRequest(pConn)
+		sereq.CPFSEID = ie.NewFSEID(testRemoteSEID+1, net.ParseIP(testSMFNodeID), nil)
+		stale := establishTestSessionWith(t, pConn, sereq)
+
+		setup(t, pConn, start.Add(time.Hour))
+		require.Len(t, pConn.store.GetAllSessions(), 2, "sessions are kept during the grace period")
+
+		reply, err := pConn.handleSessionModificationRequest(
+			message.NewSessionModificationRequest(0, 0, reconciled, 3, 0, ie.NewRemoveURR(ie.NewURRID(1))))
+		require.NoError(t, err)
+		requireCause(t, ie.CauseRequestAccepted, reply.(*message.SessionModificationResponse).Cause)
+
+		require.Eventually(t, func() bool {
+			_, ok := pConn.store.GetSession(stale)
+			return !ok
+		}, time.Second, 10*time.Millisecond)
+
+		_, ok := pConn.store.GetSession(reconciled)
+		require.True(t, ok)
+
+		for _, pdr := range dp.Rules().Pdrs {
+			require.Equal(t, reconciled, pdr.FseID)
+		}
+	})
+}
+
+func TestAssociations(t *testing.T) {
+	a := newAssociations()
+	pConn1, pConn2 := &PFCPConn{}, &PFCPConn{}
+
+	require.Nil(t, a.associate(testSMFNodeID, pConn1))
+	require.Nil(t, a.associate(testSMFNodeID, pConn1), "re-associating the same connection")
+	require.Equal(t, pConn1, a.associate(testSMFNodeID, pConn2))
+
+	// The superseded association doesn't release the new one
+	a.release(testSMFNodeID, pConn1)
+	require.Equal(t, pConn2, a.byNode[testSMFNodeID])
+
+	a.release(testSMFNodeID, pConn2)
+	require.Empty(t, a.byNode)
+}
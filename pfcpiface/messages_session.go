@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/wmnsk/go-pfcp/ie"
 	"github.com/wmnsk/go-pfcp/message"
@@ -20,6 +21,25 @@ var (
 	ErrAllocateSession = errors.New("unable to allocate new PFCP session")
 )
 
+// fseidAddress returns the IPv4 address of an F-SEID, or its IPv6 address if it has none.
+func fseidAddress(fseid *ie.FSEIDFields) net.IP {
+	if fseid.IPv4Address != nil {
+		return fseid.IPv4Address
+	}
+
+	return fseid.IPv6Address
+}
+
+// parseErrorCause returns the PFCP cause for a request whose rules could not be parsed: the UPF
+// has no resources left if it could not allocate a UE address.
+func parseErrorCause(err error) uint8 {
+	if errors.Is(err, errExhausted) {
+		return ie.CauseNoResourcesAvailable
+	}
+
+	return ie.CauseRequestRejected
+}
+
 func (pConn *PFCPConn) handleSessionEstablishmentRequest(msg message.Message) (message.Message, error) {
 	upf := pConn.upf
 
@@ -56,15 +76,14 @@ func (pConn *PFCPConn) handleSessionEstablishmentRequest(msg message.Message) (m
 	remoteSEID := fseid.SEID
 	fseidIP := ip2int(fseid.IPv4Address)
 
-	errProcessReply := func(err error, cause uint8) (message.Message, error) {
+	errProcessReply := func(err error, cause uint8, ies ...*ie.IE) (message.Message, error) {
 		// Build response message
 		seres := message.NewSessionEstablishmentResponse(0, /* MO?? <-- what's this */
 			0,                    /* FO <-- what's this? */
 			remoteSEID,           /* seid */
 			sereq.SequenceNumber, /* seq # */
 			0,                    /* priority */
-			pConn.nodeID.localIE,
-			ie.NewCause(cause),
+			append([]*ie.IE{pConn.nodeID.localIE, ie.NewCause(cause)}, ies...)...,
 		)
 
 		return seres, errProcess(err)
@@ -76,12 +95,52 @@ func (pConn *PFCPConn) handleSessionEstablishmentRequest(msg message.Message) (m
 		return errProcessReply(ErrAssocNotFound, ie.CauseNoEstablishedPFCPAssociation)
 	}
 
+	if upf.isDraining() {
+		log.Warn("Rejecting Session Establishment Request from ", nodeID, ", the UPF is draining")
+		return errProcessReply(errUPFDraining, ie.CauseNoResourcesAvailable)
+	}
+
 	session, ok := pConn.NewPFCPSession(remoteSEID)
 	if !ok {
 		return errProcessReply(ErrAllocateSession,
 			ie.CauseNoResourcesAvailable)
 	}
 
+	// The SEID is given back if the establishment fails before the session is stored
+	defer func() {
+		if _, ok := pConn.store.GetSession(session.localSEID); !ok {
+			pConn.sessionOwners.release(session.localSEID, pConn)
+		}
+	}()
+
+	// The UE IPs and F-TEIDs allocated for the PDRs parsed so far are released if the
+	// establishment fails before the rules are in the datapath
+	established := false
+
+	defer func() {
+		if !established {
+			upf.releaseAllocations(session)
+		}
+	}()
+
+	session.remoteIP = fseidAddress(fseid)
+
+	if sereq.FQCSID != nil {
+		session.cpFQCSID, err = parseFQCSID(sereq.FQCSID)
+		if err != nil {
+			return errUnmarshalReply(err, sereq.FQCSID)
+		}
+	}
+
+	if sereq.UserID != nil {
+		userID, err := sereq.UserID.UserID()
+		if err != nil {
+			return errUnmarshalReply(err, sereq.UserID)
+		}
+
+		session.ueKeys = ueKeys(userID)
+	}
+
 	addPDRs := make([]Pdr, 0, MaxItems)
 	addFARs := make([]Far, 0, MaxItems)
 	addQERs := make([]Qer, 0, MaxItems)
@@ -89,8 +148,11 @@ func (pConn *PFCPConn) handleSessionEstablishmentRequest(msg message.Message) (m
 
 	for _, cPDR := range sereq.CreatePDR {
 		var p Pdr
-		if err := p.parsePDR(cPDR, pConn.appPFDs, upf.ippool, upf, &session); err != nil {
-			return errProcessReply(err, ie.CauseRequestRejected)
+		if err := p.parsePDR(cPDR, pConn.appPFDs, upf.ippools, upf, &session); err != nil {
+			// What was allocated for p is released with the other PDRs
+			session.CreatePDR(p)
+
+			return errProcessReply(err, parseErrorCause(err))
 		}
 
 		p.FseidIP = fseidIP
@@ -146,18 +208,26 @@ func (pConn *PFCPConn) handleSessionEstablishmentRequest(msg message.Message) (m
 		Urrs: addURRs,
 	}
 
-	cause := upf.SendMsgToUPF(UpfMsgTypeAdd, session, updated)
-	if cause == ie.CauseRequestRejected {
+	tx := NewTransaction(upf.Datapath)
+	tx.CreateRules(updated)
+
+	if err := upf.commitTransaction(tx); err != nil {
 		pConn.RemoveSession(session)
-		return errProcessReply(ErrWriteToDatapath,
-			ie.CauseRequestRejected)
+
+		cause, ies := datapathErrorReply(err)
+
+		return errProcessReply(err, cause, ies...)
 	}
 
+	established = true
+
 	err = pConn.store.PutSession(session)
 	if err != nil {
 		log.Errorf("Failed to put PFCP session to store: %v", err)
 	}
 
+	pConn.usage.sync(session.localSEID, session.Urrs, time.Now())
+
 	var localFSEID *ie.IE
 
 	localIP := pConn.LocalAddr().(*net.UDPAddr).IP
@@ -197,20 +267,24 @@ func (pConn *PFCPConn) handleSessionModificationRequest(msg message.Message) (me
 
 	var remoteSEID uint64
 
-	sendError := func(err error) (message.Message, error) {
+	sendErrorWithCause := func(err error, cause uint8, ies ...*ie.IE) (message.Message, error) {
 		log.Error(err)
 
 		smres := message.NewSessionModificationResponse(0, /* MO?? <-- what's this */
-			0,                                    /* FO <-- what's this? */
-			remoteSEID,                           /* seid */
-			smreq.SequenceNumber,                 /* seq # */
-			0,                                    /* priority */
-			ie.NewCause(ie.CauseRequestRejected), /* accept it blindly for the time being */
+			0,                    /* FO <-- what's this? */
+			remoteSEID,           /* seid */
+			smreq.SequenceNumber, /* seq # */
+			0,                    /* priority */
+			append([]*ie.IE{ie.NewCause(cause)}, ies...)...,
 		)
 
 		return smres, err
 	}
 
+	sendError := func(err error) (message.Message, error) {
+		return sendErrorWithCause(err, ie.CauseRequestRejected)
+	}
+
 	localSEID := smreq.SEID()
 
 	session, ok := pConn.store.GetSession(localSEID)
@@ -218,294 +292,274 @@ func (pConn *PFCPConn) handleSessionModificationRequest(msg message.Message) (me
 		return sendError(ErrNotFoundWithParam("PFCP session", "localSEID", localSEID))
 	}
 
+	// All changes are made on a copy of the session and pushed to the datapath as a single
+	// transaction, so that a rejected request leaves both the datapath and the store untouched.
+	updated := session.DeepCopy()
+
+	// PDRs parsed from this request, whose TEIDs and UE IP must be released if it is rejected.
+	parsedPDRs := make([]Pdr, 0, MaxItems)
+
+	releaseAllocations := func() {
+		upf.teidPools.releaseUnused(parsedPDRs, session.Pdrs)
+
+		if err := upf.ippools.release(localSEID, newUEAllocations(parsedPDRs, &session)); err != nil {
+			log.Error("Failed to release UE IP: ", err)
+		}
+	}
+
+	reject := func(err error) (message.Message, error) {
+		releaseAllocations()
+		return sendErrorWithCause(err, parseErrorCause(err))
+	}
+
 	var fseidIP uint32
 
 	if smreq.CPFSEID != nil {
 		fseid, err := smreq.CPFSEID.FSEID()
 		if err == nil {
-			session.remoteSEID = fseid.SEID
+			updated.remoteSEID = fseid.SEID
+			updated.remoteIP = fseidAddress(fseid)
 			fseidIP = ip2int(fseid.IPv4Address)
 
 			log.Debug("Updated FSEID from session modification request")
 		}
 	}
 
-	remoteSEID = session.remoteSEID
-
-	addPDRs := make([]Pdr, 0, MaxItems)
-	addFARs := make([]Far, 0, MaxItems)
-	addQERs := make([]Qer, 0, MaxItems)
-	addURRs := make([]Urr, 0, MaxItems)
+	remoteSEID = updated.remoteSEID
 
 	for _, cPDR := range smreq.CreatePDR {
 		var p Pdr
-		if err := p.parsePDR(cPDR, pConn.appPFDs, upf.ippool, upf, &session); err != nil {
-			return sendError(err)
+
+		err := p.parsePDR(cPDR, pConn.appPFDs, upf.ippools, upf, &updated)
+		parsedPDRs = append(parsedPDRs, p)
+
+		if err != nil {
+			return reject(err)
+		}
+
+		if updated.findPDR(p.PdrID) != nil {
+			return reject(ErrInvalidArgumentWithReason("PDR ID", p.PdrID, "PDR already exists"))
 		}
 
 		p.FseidIP = fseidIP
 
-		session.CreatePDR(p)
-		addPDRs = append(addPDRs, p)
+		updated.CreatePDR(p)
 	}
 
 	for _, cFAR := range smreq.CreateFAR {
 		var f Far
 		if err := f.parseFAR(cFAR, localSEID, upf, create); err != nil {
-			return sendError(err)
+			return reject(err)
+		}
+
+		if updated.findFAR(f.FarID) != nil {
+			return reject(ErrInvalidArgumentWithReason("FAR ID", f.FarID, "FAR already exists"))
 		}
 
 		f.FseidIP = fseidIP
 
-		session.CreateFAR(f)
-		addFARs = append(addFARs, f)
+		updated.CreateFAR(f)
 	}
 
 	for _, cQER := range smreq.CreateQER {
 		var q Qer
 		if err := q.parseQER(cQER, localSEID); err != nil {
-			return sendError(err)
+			return reject(err)
+		}
+
+		if updated.findQER(q.QerID) != nil {
+			return reject(ErrInvalidArgumentWithReason("QER ID", q.QerID, "QER already exists"))
 		}
 
 		q.FseidIP = fseidIP
 
-		session.CreateQER(q)
-		addQERs = append(addQERs, q)
+		updated.CreateQER(q)
 	}
 
 	for _, cURR := range smreq.CreateURR {
 		var u Urr
 		if err := u.parseURR(cURR, localSEID); err != nil {
-			return sendError(err)
+			return reject(err)
 		}
 
-		u.FseidIP = fseidIP
-		session.CreateURR(u)
-		addURRs = append(addURRs, u)
-	}
-
-	updated := PacketForwardingRules{
-		Pdrs: addPDRs,
-		Fars: addFARs,
-		Qers: addQERs,
-		Urrs: addURRs,
-	}
+		if updated.findURR(u.UrrID) != nil {
+			return reject(ErrInvalidArgumentWithReason("URR ID", u.UrrID, "URR already exists"))
+		}
 
-	cause := upf.SendMsgToUPF(UpfMsgTypeAdd, session, updated)
-	if cause == ie.CauseRequestRejected {
-		return sendError(ErrWriteToDatapath)
+		u.FseidIP = fseidIP
+		updated.CreateURR(u)
 	}
 
-	updatePDRs := make([]Pdr, 0, MaxItems)
-	updateFARs := make([]Far, 0, MaxItems)
-	updateQERs := make([]Qer, 0, MaxItems)
-	updateURRs := make([]Urr, 0, MaxItems)
 	endMarkerList := make([]EndMarker, 0, MaxItems)
 
-	// maintain a copy of session before updating for session modification
-	// datapath could leverage the previous session info to update forwarding rules
-	oldSession := PFCPSession{
-		UeAddress:  session.UeAddress,
-		localSEID:  session.localSEID,
-		remoteSEID: session.remoteSEID,
-	}
-
-	oldSession.Pdrs = make([]Pdr, len(session.Pdrs))
-	oldSession.Fars = make([]Far, len(session.Fars))
-	oldSession.Qers = make([]Qer, len(session.Qers))
-	oldSession.Urrs = make([]Urr, len(session.Urrs))
-	copy(oldSession.Pdrs, session.Pdrs)
-	copy(oldSession.Fars, session.Fars)
-	copy(oldSession.Qers, session.Qers)
-	copy(oldSession.Urrs, session.Urrs)
-
 	for _, uPDR := range smreq.UpdatePDR {
-		var (
-			p   Pdr
-			err error
-		)
+		var p Pdr
+
+		err := p.parsePDR(uPDR, pConn.appPFDs, upf.ippools, upf, &updated)
+		parsedPDRs = append(parsedPDRs, p)
 
-		if err = p.parsePDR(uPDR, pConn.appPFDs, upf.ippool, upf, &session); err != nil {
-			return sendError(err)
+		if err != nil {
+			return reject(err)
 		}
 
 		p.FseidIP = fseidIP
 
-		err = session.UpdatePDR(p)
+		err = updated.UpdatePDR(p)
 		if err != nil {
 			log.Error("session PDR update failed ", err)
 			continue
 		}
-
-		updatePDRs = append(updatePDRs, p)
 	}
 
 	for _, uFAR := range smreq.UpdateFAR {
-		var (
-			f   Far
-			err error
-		)
-
-		if err = f.parseFAR(uFAR, localSEID, upf, update); err != nil {
-			return sendError(err)
+		var f Far
+		if err := f.parseFAR(uFAR, localSEID, upf, update); err != nil {
+			return reject(err)
 		}
 
 		f.FseidIP = fseidIP
 
-		err = session.UpdateFAR(&f, &endMarkerList)
+		err := updated.UpdateFAR(&f, &endMarkerList)
 		if err != nil {
-			log.Error("session PDR update failed ", err)
+			log.Error("session FAR update failed ", err)
 			continue
 		}
-
-		updateFARs = append(updateFARs, f)
 	}
 
 	for _, uQER := range smreq.UpdateQER {
-		var (
-			q   Qer
-			err error
-		)
-
-		if err = q.parseQER(uQER, localSEID); err != nil {
-			return sendError(err)
+		var q Qer
+		if err := q.parseQER(uQER, localSEID); err != nil {
+			return reject(err)
 		}
 
 		q.FseidIP = fseidIP
 
-		err = session.UpdateQER(q)
+		err := updated.UpdateQER(q)
 		if err != nil {
 			log.Error("session QER update failed ", err)
 			continue
 		}
-
-		updateQERs = append(updateQERs, q)
 	}
 
 	for _, uURR := range smreq.UpdateURR {
-		var (
-			u   Urr
-			err error
-		)
+		var u Urr
 		if err := u.parseURR(uURR, localSEID); err != nil {
-			return sendError(err)
+			return reject(err)
 		}
 
 		u.FseidIP = fseidIP
-		err = session.UpdateURR(u)
+
+		err := updated.UpdateURR(u)
 		if err != nil {
 			log.Error("session URR update failed ", err)
 			continue
 		}
-
-		updateURRs = append(updateURRs, u)
-	}
-
-	session.MarkSessionQer(session.Qers)
-	// FIXME: since PacketForwardingRules doesn't store pointers,
-	//  we must also mark session QERs in addQERs.
-	//  We need a kind of refactoring to clean it up.
-	session.MarkSessionQer(addQERs)
-
-	updated = PacketForwardingRules{
-		Pdrs: updatePDRs,
-		Fars: updateFARs,
-		Qers: updateQERs,
-		Urrs: updateURRs,
-	}
-
-	// Send the session before updating to datapath
-	cause = upf.SendMsgToUPF(UpfMsgTypeMod, oldSession, updated)
-	if cause == ie.CauseRequestRejected {
-		return sendError(ErrWriteToDatapath)
-	}
-
-	if upf.enableEndMarker {
-		err := upf.SendEndMarkers(&endMarkerList)
-		if err != nil {
-			log.Error("Sending End Markers Failed : ", err)
-		}
 	}
 
-	delPDRs := make([]Pdr, 0, MaxItems)
-	delFARs := make([]Far, 0, MaxItems)
-	delQERs := make([]Qer, 0, MaxItems)
-	delURRs := make([]Urr, 0, MaxItems)
-
 	for _, rPDR := range smreq.RemovePDR {
 		pdrID, err := rPDR.PDRID()
 		if err != nil {
-			return sendError(err)
+			return reject(err)
 		}
 
-		p, err := session.RemovePDR(uint32(pdrID))
-		if err != nil {
-			return sendError(err)
+		if _, err := updated.RemovePDR(uint32(pdrID)); err != nil {
+			return reject(err)
 		}
-		upf.teidAllocator.Free(p.TunnelTEID)
-
-		delPDRs = append(delPDRs, *p)
 	}
 
 	for _, dFAR := range smreq.RemoveFAR {
 		farID, err := dFAR.FARID()
 		if err != nil {
-			return sendError(err)
+			return reject(err)
 		}
 
-		f, err := session.RemoveFAR(farID)
-		if err != nil {
-			return sendError(err)
+		if _, err := updated.RemoveFAR(farID); err != nil {
+			return reject(err)
 		}
-
-		delFARs = append(delFARs, *f)
 	}
 
 	for _, dQER := range smreq.RemoveQER {
 		qerID, err := dQER.QERID()
 		if err != nil {
-			return sendError(err)
+			return reject(err)
 		}
 
-		q, err := session.RemoveQER(qerID)
-		if err != nil {
-			return sendError(err)
+		if _, err := updated.RemoveQER(qerID); err != nil {
+			return reject(err)
 		}
-
-		delQERs = append(delQERs, *q)
 	}
 
+	// URRs whose usage is reported in the response: removed ones, and the ones queried by the SMF
+	removedURRs := make([]Urr, 0, len(smreq.RemoveURR))
+
 	for _, dURR := range smreq.RemoveURR {
 		urrID, err := dURR.URRID()
 		if err != nil {
-			return sendError(err)
+			return reject(err)
 		}
 
-		u, err := session.RemoveURR(urrID)
+		urr, err := updated.RemoveURR(urrID)
 		if err != nil {
-			return sendError(err)
+			return reject(err)
 		}
 
-		delURRs = append(delURRs, *u)
+		removedURRs = append(removedURRs, *urr)
 	}
 
-	deleted := PacketForwardingRules{
-		Pdrs: delPDRs,
-		Fars: delFARs,
-		Qers: delQERs,
-		Urrs: delURRs,
+	queried, err := queriedURRs(smreq, &updated)
+	if err != nil {
+		return reject(err)
 	}
 
-	cause = upf.SendMsgToUPF(UpfMsgTypeDel, session, deleted)
-	if cause == ie.CauseRequestRejected {
-		return sendError(ErrWriteToDatapath)
+	updated.MarkSessionQer(updated.Qers)
+
+	// Counters are read while the removed URRs are still in the datapath
+	usage := pConn.readUsage(localSEID, append(append([]Urr{}, removedURRs...), queried...))
+
+	tx := NewTransaction(upf.Datapath)
+	tx.ApplyDiff(session.PacketForwardingRules, updated.PacketForwardingRules)
+
+	if err := upf.commitTransaction(tx); err != nil {
+		releaseAllocations()
+
+		cause, ies := datapathErrorReply(err)
+
+		return sendErrorWithCause(err, cause, ies...)
 	}
 
-	err := pConn.store.PutSession(session)
+	// The TEIDs of removed and updated PDRs can be reused now that the datapath no longer matches
+	// them, as can the ones allocated for updates of unknown PDRs
+	upf.teidPools.releaseUnused(session.Pdrs, updated.Pdrs)
+	upf.teidPools.releaseUnused(parsedPDRs, updated.Pdrs)
+
+	if upf.enableEndMarker {
+		err := upf.SendEndMarkers(&endMarkerList)
+		if err != nil {
+			log.Error("Sending End Markers Failed : ", err)
+		}
+	}
+
+	err = pConn.store.PutSession(updated)
 	if err != nil {
 		log.Errorf("Failed to put PFCP session to store: %v", err)
 	}
 
+	usageReports := pConn.usageReportsNow(localSEID, removedURRs, usage, usageReportTriggerTERMR)
+	immediateReports := pConn.usageReportsNow(localSEID, queried, usage, usageReportTriggerIMMER)
+
+	if smreq.QueryURRReference != nil {
+		if ref, err := smreq.QueryURRReference.QueryURRReference(); err == nil {
+			for _, r := range immediateReports {
+				r.queryURRReference = &ref
+			}
+		}
+	}
+
+	usageReports = append(usageReports, immediateReports...)
+
+	pConn.usage.sync(localSEID, updated.Urrs, time.Now())
+	pConn.reconcileSession(localSEID)
+
 	log.Debugw("Sending session modification response:",
 		zap.Uint64("Local SEID:", localSEID),
 		zap.Uint64("Remote SEID:", remoteSEID))
@@ -518,9 +572,39 @@ func (pConn *PFCPConn) handleSessionModificationRequest(msg message.Message) (me
 		ie.NewCause(ie.CauseRequestAccepted), /* accept it blindly for the time being */
 	)
 
+	for _, r := range usageReports {
+		smres.UsageReport = append(smres.UsageReport, ie.NewUsageReportWithinSessionModificationResponse(r.IEs()...))
+	}
+
 	return smres, nil
 }
 
+// queriedURRs returns the URRs of session whose usage is queried by smreq, with Query URR IEs or
+// the QAURR flag.
+func queriedURRs(smreq *message.SessionModificationRequest, session *PFCPSession) ([]Urr, error) {
+	if smreq.PFCPSMReqFlags != nil && smreq.PFCPSMReqFlags.HasQAURR() {
+		return append([]Urr{}, session.Urrs...), nil
+	}
+
+	urrs := make([]Urr, 0, len(smreq.QueryURR))
+
+	for _, qURR := range smreq.QueryURR {
+		urrID, err := qURR.URRID()
+		if err != nil {
+			return nil, err
+		}
+
+		urr := session.findURR(urrID)
+		if urr == nil {
+			return nil, ErrNotFoundWithParam("URR", "URR ID", urrID)
+		}
+
+		urrs = append(urrs, *urr)
+	}
+
+	return urrs, nil
+}
+
 func (pConn *PFCPConn) handleSessionDeletionRequest(msg message.Message) (message.Message, error) {
 	upf := pConn.upf
 
@@ -529,18 +613,22 @@ func (pConn *PFCPConn) handleSessionDeletionRequest(msg message.Message) (messag
 		return nil, errUnmarshal(errMsgUnexpectedType)
 	}
 
-	sendError := func(err error) (message.Message, error) {
+	sendErrorWithCause := func(err error, cause uint8, ies ...*ie.IE) (message.Message, error) {
 		smres := message.NewSessionDeletionResponse(0, /* MO?? <-- what's this */
-			0,                                    /* FO <-- what's this? */
-			0,                                    /* seid */
-			sdreq.SequenceNumber,                 /* seq # */
-			0,                                    /* priority */
-			ie.NewCause(ie.CauseRequestRejected), /* accept it blindly for the time being */
+			0,                    /* FO <-- what's this? */
+			0,                    /* seid */
+			sdreq.SequenceNumber, /* seq # */
+			0,                    /* priority */
+			append([]*ie.IE{ie.NewCause(cause)}, ies...)...,
 		)
 
 		return smres, err
 	}
 
+	sendError := func(err error) (message.Message, error) {
+		return sendErrorWithCause(err, ie.CauseRequestRejected)
+	}
+
 	/* retrieve sessionRecord */
 	localSEID := sdreq.SEID()
 
@@ -549,17 +637,25 @@ func (pConn *PFCPConn) handleSessionDeletionRequest(msg message.Message) (messag
 		return sendError(ErrNotFoundWithParam("PFCP session", "localSEID", localSEID))
 	}
 
-	cause := upf.SendMsgToUPF(UpfMsgTypeDel, session, session.PacketForwardingRules)
-	if cause == ie.CauseRequestRejected {
-		return sendError(ErrWriteToDatapath)
+	// Final counters are read while the URRs are still in the datapath
+	usage := pConn.readUsage(localSEID, session.Urrs)
+
+	tx := NewTransaction(upf.Datapath)
+	tx.DeleteRules(session.PacketForwardingRules)
+
+	if err := upf.commitTransaction(tx); err != nil {
+		cause, ies := datapathErrorReply(err)
+		return sendErrorWithCause(err, cause, ies...)
 	}
 
-	if err := releaseAllocatedIPs(upf.ippool, &session); err != nil {
+	usageReports := pConn.usageReportsNow(localSEID, session.Urrs, usage, usageReportTriggerTERMR)
+
+	if err := releaseAllocatedIPs(upf.ippools, &session); err != nil {
 		return sendError(ErrOperationFailedWithReason("session IP dealloc", err.Error()))
 	}
 
 	// Release all TEIDs on the session
-	releaseAllocatedTEIDs(upf.teidAllocator, &session)
+	upf.teidPools.releaseUnused(session.Pdrs, nil)
 
 	/* delete sessionRecord */
 	pConn.RemoveSession(session)
@@ -577,9 +673,68 @@ func (pConn *PFCPConn) handleSessionDeletionRequest(msg message.Message) (messag
 		ie.NewCause(ie.CauseRequestAccepted), /* accept it blindly for the time being */
 	)
 
+	for _, r := range usageReports {
+		smres.UsageReport = append(smres.UsageReport, ie.NewUsageReportWithinSessionDeletionResponse(r.IEs()...))
+	}
+
 	return smres, nil
 }
 
+func (pConn *PFCPConn) handleSessionSetDeletionRequest(msg message.Message) (message.Message, error) {
+	ssdreq, ok := msg.(*message.SessionSetDeletionRequest)
+	if !ok {
+		return nil, errUnmarshal(errMsgUnexpectedType)
+	}
+
+	reply := func(cause uint8, offendingIE *ie.IE) message.Message {
+		return message.NewSessionSetDeletionResponse(ssdreq.SequenceNumber,
+			pConn.nodeID.localIE,
+			ie.NewCause(cause),
+			offendingIE,
+		)
+	}
+
+	if ssdreq.NodeID == nil {
+		return reply(ie.CauseMandatoryIEMissing, nil), errUnmarshal(ErrNotFound("Node ID"))
+	}
+
+	nodeID, err := ssdreq.NodeID.NodeID()
+	if err != nil {
+		return reply(ie.CauseRequestRejected, ssdreq.NodeID), errUnmarshal(err)
+	}
+
+	if nodeID != pConn.nodeID.remote {
+		log.Warnf("Association not found for Session Set Deletion request with nodeID: %v, Association NodeID: %v",
+			nodeID, pConn.nodeID.remote)
+		return reply(ie.CauseNoEstablishedPFCPAssociation, nil), errProcess(ErrAssocNotFound)
+	}
+
+	// Without FQ-CSID, all sessions of the peer are deleted
+	var csid *fqCSID
+
+	if ssdreq.FQCSID != nil {
+		csid, err = parseFQCSID(ssdreq.FQCSID)
+		if err != nil {
+			return reply(ie.CauseRequestRejected, ssdreq.FQCSID), errUnmarshal(err)
+		}
+	}
+
+	deleted := 0
+
+	for _, session := range pConn.store.GetAllSessions() {
+		if csid != nil && !csid.matches(session.cpFQCSID) {
+			continue
+		}
+
+		pConn.purgeSession(session)
+		deleted++
+	}
+
+	log.Infof("Session Set Deletion from %v deleted %v sessions", nodeID, deleted)
+
+	return reply(ie.CauseRequestAccepted, nil), nil
+}
+
 func (pConn *PFCPConn) handleDigestReport(fseid uint64) {
 	session, ok := pConn.store.GetSession(fseid)
 	if !ok {
@@ -635,12 +790,11 @@ func (pConn *PFCPConn) handleDigestReport(fseid uint64) {
 		zap.Uint32("PDR ID", pdrID),
 	)
 
-	pConn.SendPFCPMsg(srreq)
+	// Called on the event loop of the PFCPNode, which a slow peer must not block
+	pConn.trySendPFCPMsg(srreq)
 }
 
 func (pConn *PFCPConn) handleSessionReportResponse(msg message.Message) error {
-	upf := pConn.upf
-
 	srres, ok := msg.(*message.SessionReportResponse)
 	if !ok {
 		return errUnmarshal(errMsgUnexpectedType)
@@ -661,16 +815,9 @@ func (pConn *PFCPConn) handleSessionReportResponse(msg message.Message) error {
 			return errProcess(ErrNotFoundWithParam("PFCP session context", "SEID", seid))
 		}
 
-		log.Warnf("Session context not found, deleting session locally with ID: %s", seid)
-
-		pConn.RemoveSession(sessItem)
+		log.Warnf("Session context not found, deleting session locally with ID: %v", seid)
 
-		cause := upf.SendMsgToUPF(
-			UpfMsgTypeDel, sessItem, PacketForwardingRules{})
-		if cause == ie.CauseRequestRejected {
-			return errProcess(
-				ErrOperationFailedWithParam("delete session from datapath", "seid", seid))
-		}
+		pConn.purgeSession(sessItem)
 
 		return nil
 	}
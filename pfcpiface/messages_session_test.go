@@ -0,0 +1,565 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+
+	"github.com/ardzoht/omec-upf/pfcpiface/metrics"
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+	"github.com/wmnsk/go-pfcp/message"
+)
+
+const (
+	testSMFNodeID  = "10.0.0.1"
+	testRemoteSEID = uint64(0x100)
+)
+
+type noopInstrumentPFCP struct{}
+
+func (noopInstrumentPFCP) SaveMessages(m *metrics.Message) {}
+
+func (noopInstrumentPFCP) SaveSessions(s *metrics.Session) {}
+
+func (noopInstrumentPFCP) SaveUnknownSEIDReport() {}
+
+func (noopInstrumentPFCP) SaveRetransmittedRequest(nodeID, msgType string) {}
+
+func (noopInstrumentPFCP) Stop() error { return nil }
+
+func newTestUpf(dp Datapath) *Upf {
+	upf := &Upf{
+		Datapath: dp,
+		AccessIP: net.ParseIP("192.168.0.1"),
+		CoreIP:   net.ParseIP("192.168.1.1"),
+	}
+
+	// Only the default pools, which cannot fail
+	upf.teidPools, _ = newTEIDPools(CPIfaceInfo{}, upf.AccessIP, nil, upf.CoreIP, nil)
+
+	return upf
+}
+
+// newTestPFCPConn returns a PFCPConn associated with testSMFNodeID, which is not served: its
+// handlers are meant to be called directly.
+func newTestPFCPConn(t *testing.T, dp Datapath) *PFCPConn {
+	return newTestPFCPConnWithUpf(t, newTestUpf(dp))
+}
+
+func newTestPFCPConnWithUpf(t testing.TB, upf *Upf) *PFCPConn {
+	conn, err := net.Dial("udp", "127.0.0.1:"+PFCPPort)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	store, err := upf.newSessionsStore(NewInMemoryStore(), testSMFNodeID)
+	require.NoError(t, err)
+
+	seidRanges := newSEIDRanges()
+	seids, err := seidRanges.allocate()
+	require.NoError(t, err)
+
+	pConn := &PFCPConn{
+		Conn:           conn,
+		rng:            rand.New(rand.NewSource(1)), // #nosec G404
+		maxRetries:     100,
+		store:          store,
+		seids:          seids,
+		sessionOwners:  newSessionOwners(),
+		associations:   newAssociations(),
+		seidRanges:     seidRanges,
+		responses:      newResponseCache(upf.responseCacheTTL()),
+		upf:            upf,
+		InstrumentPFCP: noopInstrumentPFCP{},
+	}
+
+	pConn.usage = newUsageTracker(pConn.handleUsageTimer)
+
+	pConn.setLocalNodeID("127.0.0.1")
+	pConn.nodeID.remote = testSMFNodeID
+
+	return pConn
+}
+
+// establishTestSession establishes the session of newTestEstablishmentRequest. It returns the
+// local SEID.
+func establishTestSession(t *testing.T, pConn *PFCPConn) uint64 {
+	return establishTestSessionWith(t, pConn, newTestEstablishmentRequest(pConn))
+}
+
+// newTestEstablishmentRequest returns the request of a session with an uplink PDR whose TEID is
+// allocated by the UPF, a downlink PDR, their FARs and a QER. The UE IP is allocated by the UPF
+// if it has an IP pool.
+func newTestEstablishmentRequest(pConn *PFCPConn) *message.SessionEstablishmentRequest {
+	ueIPFlags := uint8(0x02) // V4
+	if pConn.upf.ippools != nil {
+		ueIPFlags |= 0x10 // CHV4
+	}
+
+	return message.NewSessionEstablishmentRequest(0, 0, 0, 1, 0,
+		ie.NewNodeID(testSMFNodeID, "", ""),
+		ie.NewFSEID(testRemoteSEID, net.ParseIP(testSMFNodeID), nil),
+		ie.NewCreatePDR(
+			ie.NewPDRID(1),
+			ie.NewPrecedence(100),
+			ie.NewPDI(
+				ie.NewSourceInterface(ie.SrcInterfaceAccess),
+				ie.NewFTEID(0x05, 0, nil, nil, 0), // CH flag, TEID allocated by the UPF
+			),
+			ie.NewOuterHeaderRemoval(0, 0),
+			ie.NewFARID(1),
+			ie.NewQERID(1),
+		),
+		ie.NewCreatePDR(
+			ie.NewPDRID(2),
+			ie.NewPrecedence(100),
+			ie.NewPDI(
+				ie.NewSourceInterface(ie.SrcInterfaceCore),
+				ie.NewUEIPAddress(ueIPFlags, "10.250.0.1", "", 0, 0),
+			),
+			ie.NewFARID(2),
+			ie.NewQERID(1),
+		),
+		ie.NewCreateFAR(
+			ie.NewFARID(1),
+			ie.NewApplyAction(ActionForward),
+			ie.NewForwardingParameters(ie.NewDestinationInterface(ie.DstInterfaceCore)),
+		),
+		ie.NewCreateFAR(
+			ie.NewFARID(2),
+			ie.NewApplyAction(ActionForward),
+			ie.NewForwardingParameters(
+				ie.NewDestinationInterface(ie.DstInterfaceAccess),
+				ie.NewOuterHeaderCreation(0x100, 0x20, "192.168.0.2", "", 0, 0, 0),
+			),
+		),
+		ie.NewCreateQER(
+			ie.NewQERID(1),
+			ie.NewQFI(9),
+			ie.NewGateStatus(0, 0),
+			ie.NewMBR(1000, 2000),
+		),
+	)
+}
+
+// establishTestSessionWith establishes the session of sereq. It returns the local SEID.
+func establishTestSessionWith(t *testing.T, pConn *PFCPConn, sereq *message.SessionEstablishmentRequest) uint64 {
+	reply, err := pConn.handleSessionEstablishmentRequest(sereq)
+	require.NoError(t, err)
+
+	seres, ok := reply.(*message.SessionEstablishmentResponse)
+	require.True(t, ok)
+	require.Equal(t, ie.CauseRequestAccepted, seres.Cause.Payload[0])
+
+	fseid, err := seres.UPFSEID.FSEID()
+	require.NoError(t, err)
+
+	return fseid.SEID
+}
+
+func requireCause(t *testing.T, want uint8, causeIE *ie.IE) {
+	got, err := causeIE.Cause()
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestSessionModification(t *testing.T) {
+	dp := NewRecordingDatapath()
+	pConn := newTestPFCPConn(t, dp)
+	seid := establishTestSession(t, pConn)
+
+	smreq := message.NewSessionModificationRequest(0, 0, seid, 2, 0,
+		ie.NewUpdateFAR(
+			ie.NewFARID(2),
+			ie.NewApplyAction(ActionForward),
+			ie.NewUpdateForwardingParameters(
+				ie.NewDestinationInterface(ie.DstInterfaceAccess),
+				ie.NewOuterHeaderCreation(0x100, 0x30, "192.168.0.3", "", 0, 0, 0),
+			),
+		),
+		ie.NewCreateURR(
+			ie.NewURRID(1),
+			ie.NewMeasurementMethod(0, 1, 0),
+			ie.NewReportingTriggers(0),
+		),
+	)
+
+	reply, err := pConn.handleSessionModificationRequest(smreq)
+	require.NoError(t, err)
+
+	smres, ok := reply.(*message.SessionModificationResponse)
+	require.True(t, ok)
+	requireCause(t, ie.CauseRequestAccepted, smres.Cause)
+
+	session, ok := pConn.store.GetSession(seid)
+	require.True(t, ok)
+	require.Equal(t, uint32(0x30), session.findFAR(2).TunnelTEID)
+	require.Len(t, session.Urrs, 1)
+
+	// The datapath holds exactly the rules of the stored session
+	require.Equal(t, dp.Rules().Fars, session.Fars)
+	require.Equal(t, dp.Rules().Urrs, session.Urrs)
+}
+
+func TestSessionModification_DatapathFailure(t *testing.T) {
+	dp := NewRecordingDatapath()
+	pConn := newTestPFCPConn(t, dp)
+	seid := establishTestSession(t, pConn)
+
+	before, ok := pConn.store.GetSession(seid)
+	require.True(t, ok)
+
+	before = before.DeepCopy()
+	installed := dp.Rules()
+
+	// The FAR is modified and the new PDR is created before the QER fails to be created
+	dp.InjectError(UpfMsgTypeAdd, RuleTypeQER, ie.CauseRuleCreationModificationFailure)
+
+	smreq := message.NewSessionModificationRequest(0, 0, seid, 2, 0,
+		ie.NewCreatePDR(
+			ie.NewPDRID(3),
+			ie.NewPrecedence(50),
+			ie.NewPDI(
+				ie.NewSourceInterface(ie.SrcInterfaceAccess),
+				ie.NewFTEID(0x05, 0, nil, nil, 0),
+			),
+			ie.NewFARID(1),
+			ie.NewQERID(2),
+		),
+		ie.NewUpdateFAR(
+			ie.NewFARID(2),
+			ie.NewApplyAction(ActionForward),
+			ie.NewUpdateForwardingParameters(
+				ie.NewDestinationInterface(ie.DstInterfaceAccess),
+				ie.NewOuterHeaderCreation(0x100, 0x30, "192.168.0.3", "", 0, 0, 0),
+			),
+		),
+		ie.NewCreateQER(
+			ie.NewQERID(2),
+			ie.NewQFI(9),
+			ie.NewGateStatus(0, 0),
+			ie.NewMBR(500, 500),
+		),
+		ie.NewRemoveQER(ie.NewQERID(1)),
+	)
+
+	reply, err := pConn.handleSessionModificationRequest(smreq)
+	require.Error(t, err)
+
+	smres, ok := reply.(*message.SessionModificationResponse)
+	require.True(t, ok)
+	requireCause(t, ie.CauseRuleCreationModificationFailure, smres.Cause)
+
+	offendingIE, err := smres.OffendingIE.OffendingIE()
+	require.NoError(t, err)
+	require.Equal(t, ie.CreateQER, offendingIE)
+
+	failedRuleID, err := smres.FailedRuleID.FailedRuleID()
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), failedRuleID)
+
+	// Both the datapath and the store are left as before the request
+	require.Equal(t, installed, dp.Rules())
+
+	after, ok := pConn.store.GetSession(seid)
+	require.True(t, ok)
+	require.Equal(t, before.PacketForwardingRules, after.PacketForwardingRules)
+
+	// The TEID allocated for the new PDR is released
+	require.Equal(t, uint64(1), pConn.upf.teidPools.byName[teidPoolAccess].ids.Used())
+}
+
+func TestSessionModification_InvalidRequest(t *testing.T) {
+	dp := NewRecordingDatapath()
+	pConn := newTestPFCPConn(t, dp)
+	seid := establishTestSession(t, pConn)
+
+	before, ok := pConn.store.GetSession(seid)
+	require.True(t, ok)
+
+	before = before.DeepCopy()
+
+	dp.Reset()
+
+	smreq := message.NewSessionModificationRequest(0, 0, seid, 2, 0,
+		ie.NewUpdatePDR(
+			ie.NewPDRID(2),
+			ie.NewPrecedence(10),
+			ie.NewPDI(
+				ie.NewSourceInterface(ie.SrcInterfaceCore),
+				ie.NewUEIPAddress(0x2, "10.250.0.1", "", 0, 0),
+			),
+			ie.NewFARID(1),
+		),
+		ie.NewRemoveFAR(ie.NewFARID(99)),
+	)
+
+	reply, err := pConn.handleSessionModificationRequest(smreq)
+	require.Error(t, err)
+
+	smres, ok := reply.(*message.SessionModificationResponse)
+	require.True(t, ok)
+	requireCause(t, ie.CauseRequestRejected, smres.Cause)
+
+	require.Empty(t, dp.Calls(), "nothing must be pushed for an invalid request")
+
+	after, ok := pConn.store.GetSession(seid)
+	require.True(t, ok)
+	require.Equal(t, before.PacketForwardingRules, after.PacketForwardingRules)
+}
+
+func TestSessionModification_UpdatePDRTEID(t *testing.T) {
+	dp := NewRecordingDatapath()
+	upf := newTestUpf(dp)
+
+	var err error
+
+	upf.teidPools, err = newTEIDPools(CPIfaceInfo{TEIDPools: []TEIDPoolInfo{
+		{Name: "n9", Interface: "access", NetworkInstance: "n9", IPv4: "192.168.9.1", TEIDPrefix: 1, TEIDPrefixLen: 4},
+	}}, upf.AccessIP, nil, upf.CoreIP, nil)
+	require.NoError(t, err)
+
+	pConn := newTestPFCPConnWithUpf(t, upf)
+	seid := establishTestSession(t, pConn)
+
+	n3 := upf.teidPools.byName[teidPoolAccess].ids
+	n9 := upf.teidPools.byName["n9"].ids
+
+	modify := func(t *testing.T, pdrID uint16) {
+		smreq := message.NewSessionModificationRequest(0, 0, seid, 2, 0,
+			ie.NewUpdatePDR(
+				ie.NewPDRID(pdrID),
+				ie.NewPrecedence(100),
+				ie.NewPDI(
+					ie.NewFTEID(0x05, 0, nil, nil, 0),
+					ie.NewSourceInterface(ie.SrcInterfaceAccess),
+					ie.NewNetworkInstance("n9"),
+				),
+				ie.NewFARID(1),
+			),
+		)
+
+		reply, err := pConn.handleSessionModificationRequest(smreq)
+		require.NoError(t, err)
+
+		smres, ok := reply.(*message.SessionModificationResponse)
+		require.True(t, ok)
+		requireCause(t, ie.CauseRequestAccepted, smres.Cause)
+	}
+
+	// The F-TEID moves from the N3 pool to the N9 one
+	modify(t, 1)
+
+	session, ok := pConn.store.GetSession(seid)
+	require.True(t, ok)
+
+	pdr := session.findPDR(1)
+	require.Equal(t, "n9", pdr.TEIDPool)
+	require.Equal(t, ip2int(net.ParseIP("192.168.9.1")), pdr.TunnelIP4Dst)
+	require.Equal(t, uint32(1), pdr.TunnelTEID>>28)
+	require.Equal(t, "n9", dp.Rules().Pdrs[0].NetworkInstance, "the datapath gets the network instance")
+
+	require.Zero(t, n3.Used(), "the TEID of the updated PDR is released")
+	require.Equal(t, uint64(1), n9.Used())
+
+	// The F-TEID allocated for an unknown PDR is released
+	modify(t, 9)
+	require.Equal(t, uint64(1), n9.Used())
+
+	_, err = pConn.handleSessionDeletionRequest(message.NewSessionDeletionRequest(0, 0, seid, 3, 0))
+	require.NoError(t, err)
+	require.Zero(t, n9.Used())
+}
+
+func TestSessionReportResponse_SessionContextNotFound(t *testing.T) {
+	dp := NewRecordingDatapath()
+	upf := newTestUpf(dp)
+
+	var err error
+
+	upf.ippools, err = newUEIPPools(CPIfaceInfo{UEIPPool: "10.250.0.0/24"})
+	require.NoError(t, err)
+
+	pConn := newTestPFCPConnWithUpf(t, upf)
+	seid := establishTestSession(t, pConn)
+
+	srres := message.NewSessionReportResponse(0, 0, seid, 1, 0, ie.NewCause(ie.CauseSessionContextNotFound))
+	require.NoError(t, pConn.handleSessionReportResponse(srres))
+
+	// The session is purged as if deleted by its peer
+	_, ok := pConn.store.GetSession(seid)
+	require.False(t, ok)
+	require.Empty(t, dp.Rules().Pdrs)
+	require.Zero(t, upf.teidPools.byName[teidPoolAccess].ids.Used(), "the TEID is released")
+
+	for _, s := range upf.ippools.stats() {
+		require.Zero(t, s.used, "the UE IP is released")
+	}
+}
+
+func TestSessionSetDeletion(t *testing.T) {
+	dp := NewRecordingDatapath()
+	upf := newTestUpf(dp)
+
+	var err error
+
+	upf.ippools, err = newUEIPPools(CPIfaceInfo{UEIPPool: "10.250.0.0/24"})
+	require.NoError(t, err)
+
+	pConn := newTestPFCPConnWithUpf(t, upf)
+
+	establishWithCSID := func(t *testing.T, remoteSEID uint64, csid uint16) uint64 {
+		sereq := newTestEstablishmentRequest(pConn)
+		sereq.CPFSEID = ie.NewFSEID(remoteSEID, net.ParseIP(testSMFNodeID), nil)
+		sereq.FQCSID = ie.NewFQCSID(testSMFNodeID, csid)
+
+		return establishTestSessionWith(t, pConn, sereq)
+	}
+
+	deleteSet := func(t *testing.T, nodeID string, csid *ie.IE) (*message.SessionSetDeletionResponse, error) {
+		reply, err := pConn.handleSessionSetDeletionRequest(
+			message.NewSessionSetDeletionRequest(1, ie.NewNodeID(nodeID, "", ""), csid))
+
+		ssdres, ok := reply.(*message.SessionSetDeletionResponse)
+		require.True(t, ok)
+
+		return ssdres, err
+	}
+
+	seid1 := establishWithCSID(t, testRemoteSEID, 1)
+	seid2 := establishWithCSID(t, testRemoteSEID+1, 2)
+
+	ssdres, err := deleteSet(t, "10.0.0.99", nil)
+	require.Error(t, err, "sessions of other peers can't be deleted")
+	requireCause(t, ie.CauseNoEstablishedPFCPAssociation, ssdres.Cause)
+	require.Len(t, pConn.store.GetAllSessions(), 2)
+
+	ssdres, err = deleteSet(t, testSMFNodeID, ie.NewFQCSID(testSMFNodeID, 1, 3))
+	require.NoError(t, err)
+	requireCause(t, ie.CauseRequestAccepted, ssdres.Cause)
+
+	_, ok := pConn.store.GetSession(seid1)
+	require.False(t, ok)
+
+	_, ok = pConn.store.GetSession(seid2)
+	require.True(t, ok, "sessions of other CSIDs are kept")
+
+	for _, pdr := range dp.Rules().Pdrs {
+		require.Equal(t, seid2, pdr.FseID)
+	}
+
+	// Without FQ-CSID, all sessions of the peer are deleted
+	ssdres, err = deleteSet(t, testSMFNodeID, nil)
+	require.NoError(t, err)
+	requireCause(t, ie.CauseRequestAccepted, ssdres.Cause)
+	require.Empty(t, pConn.store.GetAllSessions())
+	require.Empty(t, dp.Rules().Pdrs)
+	require.Empty(t, upf.ippools.dflt.ip4.inventory, "UE IPs are released")
+}
+
+func TestSessionEstablishment_UEPoolExhausted(t *testing.T) {
+	dp := NewRecordingDatapath()
+	upf := newTestUpf(dp)
+
+	var err error
+
+	upf.ippools, err = newUEIPPools(CPIfaceInfo{UEIPPool: "10.250.0.0/30"})
+	require.NoError(t, err)
+
+	pConn := newTestPFCPConnWithUpf(t, upf)
+
+	newRequest := func(remoteSEID uint64) *message.SessionEstablishmentRequest {
+		sereq := newTestEstablishmentRequest(pConn)
+		sereq.CPFSEID = ie.NewFSEID(remoteSEID, net.ParseIP(testSMFNodeID), nil)
+
+		return sereq
+	}
+
+	establishTestSessionWith(t, pConn, newRequest(testRemoteSEID))
+	establishTestSessionWith(t, pConn, newRequest(testRemoteSEID+1))
+
+	usedTEIDs := upf.teidPools.byName[teidPoolAccess].ids.Used()
+
+	reply, err := pConn.handleSessionEstablishmentRequest(newRequest(testRemoteSEID + 2))
+	require.Error(t, err)
+
+	seres, ok := reply.(*message.SessionEstablishmentResponse)
+	require.True(t, ok)
+	requireCause(t, ie.CauseNoResourcesAvailable, seres.Cause)
+
+	require.Len(t, pConn.store.GetAllSessions(), 2)
+	require.Equal(t, usedTEIDs, upf.teidPools.byName[teidPoolAccess].ids.Used(), "the TEID of the rejected session is released")
+
+	stats := upf.ippools.stats()
+	require.Len(t, stats, 1)
+	require.Equal(t, uint64(1), stats[0].exhausted)
+}
+
+func TestSessionEstablishment_UEIPReservation(t *testing.T) {
+	upf := newTestUpf(NewRecordingDatapath())
+
+	var err error
+
+	upf.ippools, err = newUEIPPools(CPIfaceInfo{
+		UEIPPool:         "10.250.0.0/24",
+		UEIPReservations: []UEIPReservationInfo{{Key: "imsi-001010000000001", IPv4: "10.250.0.100"}},
+	})
+	require.NoError(t, err)
+
+	pConn := newTestPFCPConnWithUpf(t, upf)
+
+	sereq := newTestEstablishmentRequest(pConn)
+	sereq.UserID = ie.NewUserID(0x01, "001010000000001", "", "", "")
+
+	seid := establishTestSessionWith(t, pConn, sereq)
+
+	session, ok := pConn.store.GetSession(seid)
+	require.True(t, ok)
+	require.Equal(t, ip2int(net.ParseIP("10.250.0.100")), session.UeAddress)
+	require.Equal(t, []string{"imsi-001010000000001"}, session.ueKeys)
+}
+
+func TestSessionEstablishment_InvalidRuleReleasesAllocations(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		invalid func(sereq *message.SessionEstablishmentRequest)
+	}{
+		{name: "FAR", invalid: func(sereq *message.SessionEstablishmentRequest) {
+			sereq.CreateFAR = append(sereq.CreateFAR, ie.NewCreateFAR(ie.NewApplyAction(ActionForward)))
+		}},
+		{name: "QER", invalid: func(sereq *message.SessionEstablishmentRequest) {
+			sereq.CreateQER = append(sereq.CreateQER, ie.NewCreateQER(ie.NewQFI(9)))
+		}},
+		{name: "URR", invalid: func(sereq *message.SessionEstablishmentRequest) {
+			sereq.CreateURR = append(sereq.CreateURR, ie.NewCreateURR(ie.NewReportingTriggers(2)))
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			upf := newTestUpf(NewRecordingDatapath())
+
+			var err error
+
+			upf.ippools, err = newUEIPPools(CPIfaceInfo{UEIPPool: "10.250.0.0/24"})
+			require.NoError(t, err)
+
+			pConn := newTestPFCPConnWithUpf(t, upf)
+
+			sereq := newTestEstablishmentRequest(pConn)
+			tt.invalid(sereq)
+
+			_, err = pConn.handleSessionEstablishmentRequest(sereq)
+			require.Error(t, err)
+
+			require.Empty(t, pConn.store.GetAllSessions())
+			require.Zero(t, upf.teidPools.byName[teidPoolAccess].ids.Used(), "the TEID is released")
+
+			for _, s := range upf.ippools.stats() {
+				require.Zero(t, s.used, "the UE IP is released")
+			}
+		})
+	}
+}
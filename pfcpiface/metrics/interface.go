@@ -51,5 +51,9 @@ func (s *Session) Delete() {
 type InstrumentPFCP interface {
 	SaveMessages(m *Message)
 	SaveSessions(s *Session)
+	// SaveUnknownSEIDReport counts a report from the datapath for a session owned by no peer.
+	SaveUnknownSEIDReport()
+	// SaveRetransmittedRequest counts a retransmitted request answered with a cached response.
+	SaveRetransmittedRequest(nodeID, msgType string)
 	Stop() error
 }
@@ -15,6 +15,9 @@ type Service struct {
 
 	sessions        *prometheus.GaugeVec
 	sessionDuration *prometheus.HistogramVec
+
+	unknownSEIDReports    prometheus.Counter
+	retransmittedRequests *prometheus.CounterVec
 }
 
 func NewPrometheusService() (*Service, error) {
@@ -68,12 +71,33 @@ func NewPrometheusService() (*Service, error) {
 		return nil, err
 	}
 
+	unknownSEIDReports := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pfcp_session_reports_unknown_seid_total",
+		Help: "Counter for datapath reports on sessions not owned by any PFCP peer",
+	})
+
+	if err := prometheus.Register(unknownSEIDReports); err != nil {
+		return nil, err
+	}
+
+	retransmittedRequests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pfcp_retransmitted_requests_total",
+		Help: "Counter for retransmitted PFCP requests answered with a cached response",
+	}, []string{"node_id", "message_type"})
+
+	if err := prometheus.Register(retransmittedRequests); err != nil {
+		return nil, err
+	}
+
 	s := &Service{
 		msgCount:    msgCount,
 		msgDuration: msgDuration,
 
 		sessions:        sessions,
 		sessionDuration: sessionDuration,
+
+		unknownSEIDReports:    unknownSEIDReports,
+		retransmittedRequests: retransmittedRequests,
 	}
 
 	return s, nil
@@ -94,11 +118,21 @@ func (s *Service) SaveSessions(sess *Session) {
 	s.sessionDuration.WithLabelValues(sess.NodeID).Observe(sess.Duration)
 }
 
+func (s *Service) SaveUnknownSEIDReport() {
+	s.unknownSEIDReports.Inc()
+}
+
+func (s *Service) SaveRetransmittedRequest(nodeID, msgType string) {
+	s.retransmittedRequests.WithLabelValues(nodeID, msgType).Inc()
+}
+
 func (s *Service) Stop() error {
 	prometheus.Unregister(s.msgCount)
 	prometheus.Unregister(s.msgDuration)
 	prometheus.Unregister(s.sessions)
 	prometheus.Unregister(s.sessionDuration)
+	prometheus.Unregister(s.unknownSEIDReports)
+	prometheus.Unregister(s.retransmittedRequests)
 
 	return nil
 }
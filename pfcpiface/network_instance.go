@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+)
+
+// networkInstance is a named network instance of the UPF: the addresses of its access and core
+// interfaces, nil if none, and the DNNs it serves.
+type networkInstance struct {
+	name      string
+	accessIP  net.IP
+	accessIP6 net.IP
+	coreIP    net.IP
+	coreIP6   net.IP
+	dnns      []string
+}
+
+// newNetworkInstance returns the network instance of conf. Addresses that are not configured
+// are read from the interfaces, unless lookupIfaces is false.
+func newNetworkInstance(conf NetworkInstanceInfo, lookupIfaces bool) (*networkInstance, error) {
+	if conf.Name == "" {
+		return nil, ErrInvalidArgumentWithReason("network instance", conf, "missing name")
+	}
+
+	ni := &networkInstance{name: conf.Name, dnns: conf.Dnns}
+
+	var err error
+
+	if ni.accessIP, ni.accessIP6, err = instanceAddresses(conf.AccessIface, conf.AccessIP, conf.AccessIP6,
+		lookupIfaces); err != nil {
+		return nil, err
+	}
+
+	if ni.coreIP, ni.coreIP6, err = instanceAddresses(conf.CoreIface, conf.CoreIP, conf.CoreIP6,
+		lookupIfaces); err != nil {
+		return nil, err
+	}
+
+	if ni.accessIP == nil && ni.accessIP6 == nil && ni.coreIP == nil && ni.coreIP6 == nil {
+		return nil, ErrInvalidArgumentWithReason("network instance", conf.Name, "no address")
+	}
+
+	return ni, nil
+}
+
+// instanceAddresses returns the IPv4 and IPv6 addresses of an interface of a network instance:
+// the given ones, or else the ones of iface.
+func instanceAddresses(iface IfaceType, ip4Conf, ip6Conf string, lookupIfaces bool) (ip4, ip6 net.IP, err error) {
+	if ip4Conf != "" {
+		if ip4 = net.ParseIP(ip4Conf).To4(); ip4 == nil {
+			return nil, nil, ErrInvalidArgumentWithReason("network instance IPv4", ip4Conf, "invalid IPv4 address")
+		}
+	}
+
+	if ip6Conf != "" {
+		if ip6 = net.ParseIP(ip6Conf); ip6 == nil || !isIPv6(ip6) {
+			return nil, nil, ErrInvalidArgumentWithReason("network instance IPv6", ip6Conf, "invalid IPv6 address")
+		}
+	}
+
+	if iface.IfName == "" || !lookupIfaces {
+		return ip4, ip6, nil
+	}
+
+	if ip4 == nil {
+		if ip4, err = GetUnicastAddressFromInterface(iface.IfName); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if ip6 == nil {
+		if ip6, err = GetUnicastIPv6AddressFromInterface(iface.IfName); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return ip4, ip6, nil
+}
+
+// networkInstances are the network instances of the UPF, in the order of the configuration.
+type networkInstances []*networkInstance
+
+// newNetworkInstances returns the network instances of confs.
+func newNetworkInstances(confs []NetworkInstanceInfo, lookupIfaces bool) (networkInstances, error) {
+	instances := make(networkInstances, 0, len(confs))
+	names := make(map[string]string)
+
+	for _, conf := range confs {
+		ni, err := newNetworkInstance(conf, lookupIfaces)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := names[ni.name]; ok {
+			return nil, ErrInvalidArgumentWithReason("network instance", ni.name, "duplicate name")
+		}
+
+		// The Network Instance IE holds either the name of an instance or one of its DNNs
+		for _, name := range append([]string{ni.name}, ni.dnns...) {
+			if other, ok := names[name]; ok && other != ni.name {
+				return nil, ErrInvalidArgumentWithReason("network instance", ni.name,
+					"name or DNN "+name+" already used by network instance "+other)
+			}
+
+			names[name] = ni.name
+		}
+
+		instances = append(instances, ni)
+	}
+
+	return instances, nil
+}
+
+// lookup returns the network instance named by the Network Instance IE value, by name or by
+// DNN, nil if none.
+func (n networkInstances) lookup(value string) *networkInstance {
+	if value == "" {
+		return nil
+	}
+
+	for _, ni := range n {
+		if ni.name == value {
+			return ni
+		}
+
+		for _, dnn := range ni.dnns {
+			if dnn == value {
+				return ni
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the name of the network instance named by the Network Instance IE value, or
+// value itself if it names none.
+func (n networkInstances) resolve(value string) string {
+	if ni := n.lookup(value); ni != nil {
+		return ni.name
+	}
+
+	return value
+}
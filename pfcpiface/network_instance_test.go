@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNetworkInstances(t *testing.T) {
+	tests := []struct {
+		name    string
+		confs   []NetworkInstanceInfo
+		wantErr bool
+	}{
+		{name: "none"},
+		{
+			name: "instances with addresses",
+			confs: []NetworkInstanceInfo{
+				{Name: "internet", AccessIP: "192.168.0.1", CoreIP: "192.168.1.1", Dnns: []string{"internet"}},
+				{Name: "enterprise", CoreIP6: "2001:db8:11::1", Dnns: []string{"corp", "corp2"}},
+			},
+		},
+		{
+			name:    "missing name",
+			confs:   []NetworkInstanceInfo{{AccessIP: "192.168.0.1"}},
+			wantErr: true,
+		},
+		{
+			name:    "no address",
+			confs:   []NetworkInstanceInfo{{Name: "internet", AccessIface: IfaceType{IfName: "access"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid IPv4 address",
+			confs:   []NetworkInstanceInfo{{Name: "internet", AccessIP: "2001:db8::1"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid IPv6 address",
+			confs:   []NetworkInstanceInfo{{Name: "internet", CoreIP6: "192.168.1.1"}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate name",
+			confs: []NetworkInstanceInfo{
+				{Name: "internet", AccessIP: "192.168.0.1"},
+				{Name: "internet", AccessIP: "192.168.0.2"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DNN of another instance",
+			confs: []NetworkInstanceInfo{
+				{Name: "internet", AccessIP: "192.168.0.1", Dnns: []string{"corp"}},
+				{Name: "enterprise", AccessIP: "192.168.0.2", Dnns: []string{"corp"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newNetworkInstances(tt.confs, false)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNetworkInstances_lookup(t *testing.T) {
+	instances, err := newNetworkInstances([]NetworkInstanceInfo{
+		{Name: "enterprise", AccessIP: "192.168.10.1", Dnns: []string{"corp"}},
+	}, false)
+	require.NoError(t, err)
+
+	require.Equal(t, net.ParseIP("192.168.10.1").To4(), instances.lookup("enterprise").accessIP)
+	require.Equal(t, instances.lookup("enterprise"), instances.lookup("corp"))
+	require.Nil(t, instances.lookup("internet"))
+	require.Nil(t, instances.lookup(""))
+
+	require.Equal(t, "enterprise", instances.resolve("corp"))
+	require.Equal(t, "internet", instances.resolve("internet"))
+
+	var none networkInstances
+	require.Equal(t, "internet", none.resolve("internet"))
+}
@@ -8,11 +8,13 @@ import (
 	"errors"
 	"net"
 	"sync"
+	"time"
 
 	reuse "github.com/libp2p/go-reuseport"
+	"github.com/wmnsk/go-pfcp/ie"
 	"go.uber.org/zap"
 
-	"github.com/omec-project/upf-epc/pfcpiface/metrics"
+	"github.com/ardzoht/omec-upf/pfcpiface/metrics"
 )
 
 // PFCPNode represents a PFCP endpoint of the UPF.
@@ -27,10 +29,20 @@ type PFCPNode struct {
 	pConnDone chan string
 	// map of existing connections
 	pConns sync.Map
+	// owners of the sessions of all connections
+	sessionOwners *sessionOwners
+	// associations of all connections, by node ID
+	associations *associations
+	// sessions of all connections, each PFCPConn holding the partition of its peer
+	sessions *InMemoryStore
+	// ranges of the local SEIDs of the connections
+	seidRanges *seidRanges
 	// upf
 	upf *Upf
 	// metrics for PFCP messages and sessions
 	metrics metrics.InstrumentPFCP
+	// last datapath state reported on upf.DatapathStateChan
+	datapathState DatapathState
 }
 
 // NewPFCPNode create a new PFCPNode listening on local address.
@@ -48,13 +60,17 @@ func NewPFCPNode(upf *Upf) *PFCPNode {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &PFCPNode{
-		ctx:        ctx,
-		cancel:     cancel,
-		PacketConn: conn,
-		done:       make(chan struct{}),
-		pConnDone:  make(chan string, 100),
-		upf:        upf,
-		metrics:    metrics,
+		ctx:           ctx,
+		cancel:        cancel,
+		PacketConn:    conn,
+		done:          make(chan struct{}),
+		pConnDone:     make(chan string, 100),
+		sessionOwners: newSessionOwners(),
+		associations:  newAssociations(),
+		sessions:      NewInMemoryStore(),
+		seidRanges:    newSEIDRanges(),
+		upf:           upf,
+		metrics:       metrics,
 	}
 }
 
@@ -116,17 +132,20 @@ func (node *PFCPNode) handleNewPeers() {
 func (node *PFCPNode) Serve() {
 	go node.handleNewPeers()
 
+	if node.upf.ddnNotifier != nil {
+		go node.upf.ddnNotifier.run(node.ctx)
+	}
+
 	shutdown := false
 
 	for !shutdown {
 		select {
 		case fseid := <-node.upf.ReportNotifyChan:
-			// TODO: Logic to distinguish PFCPConn based on SEID
-			node.pConns.Range(func(key, value interface{}) bool {
-				pConn := value.(*PFCPConn)
-				pConn.handleDigestReport(fseid)
-				return false
-			})
+			node.handleSessionReport(fseid)
+		case event := <-node.upf.UsageReportChan:
+			node.handleUsageEvent(event)
+		case state := <-node.upf.DatapathStateChan:
+			node.handleDatapathState(state)
 		case rAddr := <-node.pConnDone:
 			node.pConns.Delete(rAddr)
 			log.Info("Removed connection to ", rAddr)
@@ -176,6 +195,87 @@ func (node *PFCPNode) Serve() {
 	close(node.done)
 }
 
+// handleSessionReport forwards a report of the datapath on the session with local SEID fseid
+// to the PFCPConn owning the session.
+func (node *PFCPNode) handleSessionReport(fseid uint64) {
+	pConn, ok := node.sessionOwners.owner(fseid)
+	if !ok {
+		log.Warn("Dropping report for unknown F-SEID: ", fseid)
+		node.metrics.SaveUnknownSEIDReport()
+
+		return
+	}
+
+	pConn.handleDigestReport(fseid)
+}
+
+// handleUsageEvent forwards URR counters reported by the datapath to the PFCPConn owning the
+// session of the URR.
+func (node *PFCPNode) handleUsageEvent(event UsageEvent) {
+	pConn, ok := node.sessionOwners.owner(event.FseID)
+	if !ok {
+		log.Warn("Dropping usage counters for unknown F-SEID: ", event.FseID)
+		node.metrics.SaveUnknownSEIDReport()
+
+		return
+	}
+
+	pConn.handleUsageEvent(event)
+}
+
+// handleDatapathState tracks datapath liveness. New associations are rejected while the
+// datapath is down (see handleAssociationSetupRequest); once it recovers, all existing peers
+// are notified with a PFCP Node Report.
+func (node *PFCPNode) handleDatapathState(state DatapathState) {
+	prev := node.datapathState
+	node.datapathState = state
+
+	log.Infof("Datapath state changed from %v to %v", prev, state)
+
+	if prev != DatapathStateDown || state != DatapathStateUp {
+		return
+	}
+
+	node.pConns.Range(func(key, value interface{}) bool {
+		pConn := value.(*PFCPConn)
+		go pConn.sendNodeReportRequest()
+
+		return true
+	})
+}
+
+// Drain stops the UPF from accepting new associations and sessions, and requests the peers of
+// the existing associations to release them, within gracefulReleasePeriod if not zero. The
+// sessions of the associations are kept until the peers delete them.
+func (node *PFCPNode) Drain(gracefulReleasePeriod time.Duration) {
+	log.Infof("Draining the UPF, graceful release period: %v", gracefulReleasePeriod)
+
+	node.upf.setDraining(true)
+
+	ies := []*ie.IE{ie.NewPFCPAssociationReleaseRequest(1, 0)}
+	if gracefulReleasePeriod > 0 {
+		ies = append(ies, ie.NewGracefulReleasePeriod(gracefulReleasePeriod))
+	}
+
+	node.pConns.Range(func(key, value interface{}) bool {
+		pConn := value.(*PFCPConn)
+		go pConn.sendAssociationUpdateRequest(ies...)
+
+		return true
+	})
+}
+
+// Resume accepts new associations and sessions again, once the UPF was drained.
+func (node *PFCPNode) Resume() {
+	log.Info("Resuming the UPF")
+	node.upf.setDraining(false)
+}
+
+// drainStatus returns whether the UPF is draining, and the number of sessions left.
+func (node *PFCPNode) drainStatus() (draining bool, sessions int) {
+	return node.upf.isDraining(), len(node.sessions.GetAllSessions())
+}
+
 func (node *PFCPNode) Stop() {
 	node.cancel()
 
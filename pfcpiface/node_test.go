@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+	"github.com/wmnsk/go-pfcp/message"
+)
+
+type countingInstrumentPFCP struct {
+	noopInstrumentPFCP
+	unknownSEIDReports int
+}
+
+func (c *countingInstrumentPFCP) SaveUnknownSEIDReport() {
+	c.unknownSEIDReports++
+}
+
+// newTestPeer returns a PFCPConn of node whose messages are received on the returned socket.
+func newTestPeer(t *testing.T, node *PFCPNode) (*PFCPConn, net.PacketConn) {
+	smf, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = smf.Close() })
+
+	conn, err := net.Dial("udp", smf.LocalAddr().String())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	pConn := newTestPFCPConn(t, NewRecordingDatapath())
+	pConn.Conn = conn
+	pConn.sessionOwners = node.sessionOwners
+
+	if node.sessions != nil {
+		pConn.store = newPeerSessions(node.sessions, nil)
+	}
+
+	if node.seidRanges != nil {
+		pConn.seidRanges = node.seidRanges
+		pConn.seids, err = node.seidRanges.allocate()
+		require.NoError(t, err)
+	}
+
+	return pConn, smf
+}
+
+// establishBufferingTestSession establishes a test session whose downlink traffic is buffered
+// and notified, so that the datapath reports on it.
+func establishBufferingTestSession(t *testing.T, pConn *PFCPConn) uint64 {
+	seid := establishTestSession(t, pConn)
+
+	session, ok := pConn.store.GetSession(seid)
+	require.True(t, ok)
+
+	for i := range session.Fars {
+		if session.Fars[i].FarID == 2 {
+			session.Fars[i].ApplyAction = ActionBuffer | ActionNotify
+		}
+	}
+
+	require.NoError(t, pConn.store.PutSession(session))
+
+	return seid
+}
+
+// readPFCPMessage returns the message received on smf, nil if none is.
+func readPFCPMessage(t *testing.T, smf net.PacketConn) message.Message {
+	require.NoError(t, smf.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+
+	buf := make([]byte, 1500)
+
+	n, _, err := smf.ReadFrom(buf)
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return nil
+	}
+
+	require.NoError(t, err)
+
+	msg, err := message.Parse(buf[:n])
+	require.NoError(t, err)
+
+	return msg
+}
+
+// readSessionReport returns the Session Report Request received on smf, nil if none is.
+func readSessionReport(t *testing.T, smf net.PacketConn) *message.SessionReportRequest {
+	msg := readPFCPMessage(t, smf)
+	if msg == nil {
+		return nil
+	}
+
+	srreq, ok := msg.(*message.SessionReportRequest)
+	require.True(t, ok, "unexpected message %v", msg.MessageTypeName())
+
+	return srreq
+}
+
+func TestPFCPNode_SessionReportRouting(t *testing.T) {
+	m := &countingInstrumentPFCP{}
+	node := &PFCPNode{
+		sessionOwners: newSessionOwners(),
+		seidRanges:    newSEIDRanges(),
+		metrics:       m,
+	}
+
+	pConn1, smf1 := newTestPeer(t, node)
+	pConn2, smf2 := newTestPeer(t, node)
+
+	seid1 := establishBufferingTestSession(t, pConn1)
+	seid2 := establishBufferingTestSession(t, pConn2)
+	require.NotEqual(t, seid1, seid2, "local SEIDs must be unique across peers")
+	require.True(t, pConn1.seids.contains(seid1))
+	require.True(t, pConn2.seids.contains(seid2))
+
+	node.handleSessionReport(seid2)
+
+	srreq := readSessionReport(t, smf2)
+	require.NotNil(t, srreq)
+	require.Equal(t, testRemoteSEID, srreq.SEID())
+	require.Nil(t, readSessionReport(t, smf1), "report must only reach the owner of the session")
+
+	node.handleSessionReport(seid1)
+
+	require.NotNil(t, readSessionReport(t, smf1))
+	require.Nil(t, readSessionReport(t, smf2))
+
+	// Reports on unknown sessions are counted and dropped
+	node.handleSessionReport(0xdead)
+	require.Equal(t, 1, m.unknownSEIDReports)
+
+	sdreq := message.NewSessionDeletionRequest(0, 0, seid1, 2, 0)
+	_, err := pConn1.handleSessionDeletionRequest(sdreq)
+	require.NoError(t, err)
+
+	node.handleSessionReport(seid1)
+	require.Equal(t, 2, m.unknownSEIDReports)
+	require.Nil(t, readSessionReport(t, smf1))
+}
+
+func TestPFCPNode_Drain(t *testing.T) {
+	node := &PFCPNode{
+		sessionOwners: newSessionOwners(),
+		sessions:      NewInMemoryStore(),
+		metrics:       &countingInstrumentPFCP{},
+	}
+	pConn, smf := newTestPeer(t, node)
+	pConn.upf.respTimeout = 50 * time.Millisecond
+	pConn.upf.maxReqRetries = 0
+	node.upf = pConn.upf
+	node.pConns.Store(smf.LocalAddr().String(), pConn)
+
+	seid := establishTestSession(t, pConn)
+
+	node.Drain(time.Minute)
+
+	aureq, ok := readPFCPMessage(t, smf).(*message.AssociationUpdateRequest)
+	require.True(t, ok, "peers are requested to release their association")
+
+	release, err := aureq.PFCPAssociationReleaseRequest.PFCPAssociationReleaseRequest()
+	require.NoError(t, err)
+	require.Equal(t, uint8(0x01), release&0x01, "SARR is set")
+
+	period, err := aureq.GracefulReleasePeriod.GracefulReleasePeriod()
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, period)
+	require.NotNil(t, aureq.UPFunctionFeatures)
+
+	draining, sessions := node.drainStatus()
+	require.True(t, draining)
+	require.Equal(t, 1, sessions)
+
+	// New sessions are rejected, existing ones are kept until deleted
+	reply, err := pConn.handleSessionEstablishmentRequest(newTestEstablishmentRequest(pConn))
+	require.Error(t, err)
+	requireCause(t, ie.CauseNoResourcesAvailable, reply.(*message.SessionEstablishmentResponse).Cause)
+
+	_, err = pConn.handleSessionDeletionRequest(message.NewSessionDeletionRequest(0, 0, seid, 2, 0))
+	require.NoError(t, err)
+
+	_, sessions = node.drainStatus()
+	require.Zero(t, sessions)
+
+	node.Resume()
+
+	draining, _ = node.drainStatus()
+	require.False(t, draining)
+	establishTestSession(t, pConn)
+}
@@ -4,7 +4,9 @@
 package pfcpiface
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,24 +15,40 @@ type downlinkDataNotifier struct {
 
 	notificationInterval time.Duration
 
-	// state keeps track of F-SEIDs and corresponding notification time in future
-	state sync.Map
+	// state keeps track of F-SEIDs and the time of their last notification
+	state map[uint64]time.Time
+	mu    sync.Mutex
+
+	// emitted and suppressed count notifications, they are accessed atomically
+	emitted    uint64
+	suppressed uint64
 }
 
 func NewDownlinkDataNotifier(notifyChan chan<- uint64, notificationInterval time.Duration) *downlinkDataNotifier {
 	return &downlinkDataNotifier{
 		notifyChan:           notifyChan,
 		notificationInterval: notificationInterval,
+		state:                make(map[uint64]time.Time),
 	}
 }
 
 // Notify checks if DDN should be generated and sends event to notifyChan.
 func (n *downlinkDataNotifier) Notify(fseid uint64) {
 	if !n.shouldNotify(fseid) {
+		atomic.AddUint64(&n.suppressed, 1)
 		return
 	}
 
-	n.notifyChan <- fseid
+	// non-blocking write to channel, the datapath must not wait for the PFCP agent
+	select {
+	case n.notifyChan <- fseid:
+		atomic.AddUint64(&n.emitted, 1)
+	default:
+		log.Warn("Downlink data notification channel full, dropping notification for F-SEID ", fseid)
+		atomic.AddUint64(&n.suppressed, 1)
+		// Let the next event of the session notify again
+		n.Forget(fseid)
+	}
 }
 
 // shouldNotify checks if DDN can be generated.
@@ -38,19 +56,80 @@ func (n *downlinkDataNotifier) Notify(fseid uint64) {
 // 1) notification timer has expired, or
 // 2) notification for unknown F-SEID is received
 func (n *downlinkDataNotifier) shouldNotify(fseid uint64) bool {
-	entry, ok := n.state.Load(fseid)
-	if !ok {
-		n.state.Store(fseid, time.Now())
-		// TODO: add goroutine that will remove stale entries
-		return true
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	lastTimestamp, ok := n.state[fseid]
+	if ok && time.Since(lastTimestamp) < n.notificationInterval {
+		return false
 	}
 
-	lastTimestamp := entry.(time.Time)
+	n.state[fseid] = time.Now()
+
+	return true
+}
+
+// Forget removes the state of fseid, e.g. once its session is deleted.
+func (n *downlinkDataNotifier) Forget(fseid uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	delete(n.state, fseid)
+}
+
+// expireStale removes the state of F-SEIDs whose notification timer has expired. It makes no
+// difference to shouldNotify, but bounds the state to the sessions recently notified.
+func (n *downlinkDataNotifier) expireStale() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 
-	if time.Since(lastTimestamp) >= n.notificationInterval {
-		n.state.Store(fseid, time.Now())
-		return true
+	for fseid, lastTimestamp := range n.state {
+		if time.Since(lastTimestamp) >= n.notificationInterval {
+			delete(n.state, fseid)
+		}
+	}
+}
+
+// run expires stale state every notification interval, until ctx is done.
+func (n *downlinkDataNotifier) run(ctx context.Context) {
+	if n.notificationInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(n.notificationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.expireStale()
+		}
+	}
+}
+
+// counters returns the number of notifications emitted and suppressed so far.
+func (n *downlinkDataNotifier) counters() (emitted, suppressed uint64) {
+	return atomic.LoadUint64(&n.emitted), atomic.LoadUint64(&n.suppressed)
+}
+
+// NotifyDownlinkData reports downlink data buffered by the datapath for the session with local
+// SEID fseid. Datapaths call it for every such event: notifications are rate limited per session
+// and forwarded to the PFCP peer owning the session.
+func (u *Upf) NotifyDownlinkData(fseid uint64) {
+	if u.ddnNotifier == nil {
+		return
+	}
+
+	u.ddnNotifier.Notify(fseid)
+}
+
+// forgetDownlinkData removes the notification state of a deleted session.
+func (u *Upf) forgetDownlinkData(fseid uint64) {
+	if u.ddnNotifier == nil {
+		return
 	}
 
-	return false
+	u.ddnNotifier.Forget(fseid)
 }
@@ -4,10 +4,12 @@
 package pfcpiface
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/message"
 )
 
 func Test_downlinkDataNotifier_Notify(t *testing.T) {
@@ -87,3 +89,71 @@ func Test_downlinkDataNotifier_shouldNotify(t *testing.T) {
 		}
 	})
 }
+
+func Test_downlinkDataNotifier_counters(t *testing.T) {
+	ch := make(chan uint64, 1)
+	n := NewDownlinkDataNotifier(ch, time.Minute)
+
+	n.Notify(1)
+	n.Notify(1)
+
+	emitted, suppressed := n.counters()
+	require.Equal(t, uint64(1), emitted)
+	require.Equal(t, uint64(1), suppressed)
+
+	// A full channel doesn't block, and the next event of the session notifies again
+	n.Notify(2)
+
+	emitted, suppressed = n.counters()
+	require.Equal(t, uint64(1), emitted)
+	require.Equal(t, uint64(2), suppressed)
+
+	<-ch
+	n.Notify(2)
+	require.Equal(t, uint64(2), <-ch)
+}
+
+func Test_downlinkDataNotifier_state(t *testing.T) {
+	ch := make(chan uint64, 1024)
+	n := NewDownlinkDataNotifier(ch, 100*time.Millisecond)
+
+	n.Notify(1)
+	n.Notify(2)
+	n.Forget(1)
+	require.NotContains(t, n.state, uint64(1))
+	require.True(t, n.shouldNotify(1), "forgotten F-SEID must be notified")
+
+	n.expireStale()
+	require.Len(t, n.state, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go n.run(ctx)
+
+	require.Eventually(t, func() bool {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		return len(n.state) == 0
+	}, time.Second, 10*time.Millisecond, "stale entries must expire")
+}
+
+func TestUpf_NotifyDownlinkData(t *testing.T) {
+	upf := newTestUpf(NewRecordingDatapath())
+	upf.ReportNotifyChan = make(chan uint64, 1024)
+	upf.ddnNotifier = NewDownlinkDataNotifier(upf.ReportNotifyChan, time.Minute)
+
+	pConn := newTestPFCPConnWithUpf(t, upf)
+	seid := establishTestSession(t, pConn)
+
+	upf.NotifyDownlinkData(seid)
+	upf.NotifyDownlinkData(seid)
+	require.Len(t, upf.ReportNotifyChan, 1)
+	require.Equal(t, seid, <-upf.ReportNotifyChan)
+
+	sdreq := message.NewSessionDeletionRequest(0, 0, seid, 2, 0)
+	_, err := pConn.handleSessionDeletionRequest(sdreq)
+	require.NoError(t, err)
+	require.Empty(t, upf.ddnNotifier.state, "state must be removed with the session")
+}
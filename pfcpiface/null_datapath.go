@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"context"
+	"net"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	mustRegisterDatapath("null", func(conf *Conf) (Datapath, error) {
+		return &NullDatapath{}, nil
+	})
+}
+
+// NullDatapath accepts every request without programming anything. It allows running the PFCP
+// agent without a dataplane, e.g. to test the N4 interface against an SMF.
+type NullDatapath struct{}
+
+func (n *NullDatapath) Exit() {}
+
+func (n *NullDatapath) SetUpfInfo(u *Upf, conf *Conf) {
+	log.Info("Using null datapath, no rules will be programmed")
+}
+
+func (n *NullDatapath) AddSliceInfo(sliceInfo *SliceInfo) error {
+	return nil
+}
+
+func (n *NullDatapath) SendEndMarkers(endMarkerList *[]EndMarker) error {
+	return nil
+}
+
+func (n *NullDatapath) CreatePDR(ctx context.Context, pdr Pdr) error {
+	return nil
+}
+
+func (n *NullDatapath) ModifyPDR(ctx context.Context, pdr Pdr) error {
+	return nil
+}
+
+func (n *NullDatapath) DeletePDR(ctx context.Context, pdr Pdr) error {
+	return nil
+}
+
+func (n *NullDatapath) CreateFAR(ctx context.Context, far Far) error {
+	return nil
+}
+
+func (n *NullDatapath) ModifyFAR(ctx context.Context, far Far) error {
+	return nil
+}
+
+func (n *NullDatapath) DeleteFAR(ctx context.Context, far Far) error {
+	return nil
+}
+
+func (n *NullDatapath) CreateQER(ctx context.Context, qer Qer) error {
+	return nil
+}
+
+func (n *NullDatapath) ModifyQER(ctx context.Context, qer Qer) error {
+	return nil
+}
+
+func (n *NullDatapath) DeleteQER(ctx context.Context, qer Qer) error {
+	return nil
+}
+
+func (n *NullDatapath) CreateURR(ctx context.Context, urr Urr) error {
+	return nil
+}
+
+func (n *NullDatapath) ModifyURR(ctx context.Context, urr Urr) error {
+	return nil
+}
+
+func (n *NullDatapath) DeleteURR(ctx context.Context, urr Urr) error {
+	return nil
+}
+
+func (n *NullDatapath) IsConnected(accessIP *net.IP) bool {
+	return true
+}
+
+func (n *NullDatapath) PortStats(uc *UpfCollector, ch chan<- prometheus.Metric) {}
+
+func (n *NullDatapath) SessionStats(pc *PfcpNodeCollector, ch chan<- prometheus.Metric) error {
+	return nil
+}
+
+func (n *NullDatapath) SummaryLatencyJitter(uc *UpfCollector, ch chan<- prometheus.Metric) {}
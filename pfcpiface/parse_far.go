@@ -5,6 +5,7 @@ package pfcpiface
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/wmnsk/go-pfcp/ie"
 )
@@ -42,14 +43,27 @@ type Far struct {
 	TunnelIP4Dst  uint32
 	TunnelTEID    uint32
 	TunnelPort    uint16
+
+	// TunnelIP6Src and TunnelIP6Dst are the IPv6 tunnel endpoints, nil for an IPv4 tunnel
+	TunnelIP6Src net.IP
+	TunnelIP6Dst net.IP
+
+	// NetworkInstance is the network instance of the Forwarding Parameters: the name of a network
+	// instance of the UPF, or else the Network Instance IE as is
+	NetworkInstance string
 }
 
 func (f Far) String() string {
 	return fmt.Sprintf("FAR(id=%v, F-SEID=%v, F-SEID IPv4=%v, dstInterface=%v, tunnelType=%v, "+
-		"tunnelIPv4Src=%v, tunnelIPv4Dst=%v, tunnelTEID=%v, tunnelSrcPort=%v, "+
-		"sendEndMarker=%v, drops=%v, forwards=%v, buffers=%v)", f.FarID, f.FseID, int2ip(f.FseidIP), f.DstIntf,
-		f.TunnelType, int2ip(f.TunnelIP4Src), int2ip(f.TunnelIP4Dst), f.TunnelTEID, f.TunnelPort, f.SendEndMarker,
-		f.Drops(), f.Forwards(), f.Buffers())
+		"tunnelIPv4Src=%v, tunnelIPv4Dst=%v, tunnelIPv6Src=%v, tunnelIPv6Dst=%v, tunnelTEID=%v, tunnelSrcPort=%v, "+
+		"sendEndMarker=%v, drops=%v, forwards=%v, buffers=%v, networkInstance=%v)", f.FarID, f.FseID, int2ip(f.FseidIP), f.DstIntf,
+		f.TunnelType, int2ip(f.TunnelIP4Src), int2ip(f.TunnelIP4Dst), f.TunnelIP6Src, f.TunnelIP6Dst, f.TunnelTEID,
+		f.TunnelPort, f.SendEndMarker, f.Drops(), f.Forwards(), f.Buffers(), f.NetworkInstance)
+}
+
+// IsIPv6 checks if the FAR encapsulates packets in an IPv6 tunnel.
+func (f *Far) IsIPv6() bool {
+	return f.TunnelIP6Dst != nil
 }
 
 func (f *Far) Drops() bool {
@@ -119,6 +133,7 @@ func (f *Far) parseFAR(farIE *ie.IE, fseid uint64, upf *Upf, op operation) error
 
 			f.TunnelTEID = ohcFields.TEID
 			f.TunnelIP4Dst = ip2int(ohcFields.IPv4Address)
+			f.TunnelIP6Dst = ohcFields.IPv6Address
 			f.TunnelType = uint8(1) // FIXME: what does it mean?
 			f.TunnelPort = tunnelGTPUPort
 		case ie.DestinationInterface:
@@ -129,14 +144,15 @@ func (f *Far) parseFAR(farIE *ie.IE, fseid uint64, upf *Upf, op operation) error
 				log.Warnf("Unable to parse DestinationInterface field %v", err)
 				continue
 			}
-
-			switch f.DstIntf {
-			case ie.DstInterfaceAccess:
-				f.TunnelIP4Src = ip2int(upf.AccessIP)
-			case ie.DstInterfaceCore:
-				f.TunnelIP4Src = ip2int(upf.CoreIP)
+		case ie.NetworkInstance:
+			networkInstance, err := fwdIE.NetworkInstanceHeuristic()
+			if err != nil {
+				log.Warnf("Unable to parse NetworkInstance field %v", err)
+				continue
 			}
 
+			f.NetworkInstance = upf.networkInstances.resolve(networkInstance)
+
 		case ie.PFCPSMReqFlags:
 			fields = Set(fields, FwdIEPfcpSMReqFlags)
 
@@ -152,5 +168,29 @@ func (f *Far) parseFAR(farIE *ie.IE, fseid uint64, upf *Upf, op operation) error
 		}
 	}
 
+	// The Network Instance may follow the Destination Interface
+	if fields&FwdIEDestinationIntf != 0 {
+		f.setTunnelSrc(upf)
+	}
+
 	return nil
 }
+
+// setTunnelSrc sets the source of the tunnel of f to the addresses of its destination
+// interface, in its network instance if the UPF has it.
+func (f *Far) setTunnelSrc(upf *Upf) {
+	accessIP, accessIP6, coreIP, coreIP6 := upf.AccessIP, upf.AccessIP6, upf.CoreIP, upf.CoreIP6
+
+	if ni := upf.networkInstances.lookup(f.NetworkInstance); ni != nil {
+		accessIP, accessIP6, coreIP, coreIP6 = ni.accessIP, ni.accessIP6, ni.coreIP, ni.coreIP6
+	}
+
+	switch f.DstIntf {
+	case ie.DstInterfaceAccess:
+		f.TunnelIP4Src = ip2int(accessIP)
+		f.TunnelIP6Src = accessIP6
+	case ie.DstInterfaceCore:
+		f.TunnelIP4Src = ip2int(coreIP)
+		f.TunnelIP6Src = coreIP6
+	}
+}
@@ -87,6 +87,77 @@ func TestParseFAR(t *testing.T) {
 	}
 }
 
+func TestParseFAR_IPv6(t *testing.T) {
+	upf := &Upf{
+		AccessIP:  net.ParseIP("192.168.0.1"),
+		AccessIP6: net.ParseIP("2001:db8::1"),
+	}
+
+	input := ie.NewCreateFAR(
+		ie.NewFARID(1),
+		ie.NewApplyAction(ActionForward),
+		ie.NewForwardingParameters(
+			ie.NewDestinationInterface(ie.DstInterfaceAccess),
+			// GTP-U/UDP/IPv6
+			ie.NewOuterHeaderCreation(0x200, 100, "", "2001:db8::2", 0, 0, 0),
+		),
+	)
+
+	far := &Far{}
+	require.NoError(t, far.parseFAR(input, 100, upf, create))
+
+	require.Equal(t, &Far{
+		FarID:        1,
+		FseID:        100,
+		ApplyAction:  ActionForward,
+		DstIntf:      ie.DstInterfaceAccess,
+		TunnelType:   1,
+		TunnelTEID:   100,
+		TunnelPort:   tunnelGTPUPort,
+		TunnelIP4Src: ip2int(upf.AccessIP),
+		TunnelIP6Src: upf.AccessIP6,
+		TunnelIP6Dst: net.ParseIP("2001:db8::2"),
+	}, far)
+	require.True(t, far.IsIPv6())
+}
+
+func TestParseFAR_NetworkInstance(t *testing.T) {
+	upf := &Upf{AccessIP: net.ParseIP("192.168.0.1"), CoreIP: net.ParseIP("192.168.1.1")}
+
+	var err error
+
+	upf.networkInstances, err = newNetworkInstances([]NetworkInstanceInfo{
+		{Name: "enterprise", CoreIP: "192.168.11.1", CoreIP6: "2001:db8:11::1"},
+	}, false)
+	require.NoError(t, err)
+
+	parse := func(t *testing.T, networkInstance string) *Far {
+		far := &Far{}
+		require.NoError(t, far.parseFAR(ie.NewCreateFAR(
+			ie.NewFARID(1),
+			ie.NewApplyAction(ActionForward),
+			ie.NewForwardingParameters(
+				ie.NewDestinationInterface(ie.DstInterfaceCore),
+				ie.NewOuterHeaderCreation(0x100, 100, "192.168.11.2", "", 0, 0, 0),
+				// The Network Instance follows the Destination Interface
+				ie.NewNetworkInstance(networkInstance),
+			),
+		), 100, upf, create))
+
+		return far
+	}
+
+	far := parse(t, "enterprise")
+	require.Equal(t, "enterprise", far.NetworkInstance)
+	require.Equal(t, ip2int(net.ParseIP("192.168.11.1")), far.TunnelIP4Src)
+	require.Equal(t, net.ParseIP("2001:db8:11::1"), far.TunnelIP6Src)
+
+	far = parse(t, "internet")
+	require.Equal(t, "internet", far.NetworkInstance)
+	require.Equal(t, ip2int(upf.CoreIP), far.TunnelIP4Src, "unknown network instances use the core interface")
+	require.Nil(t, far.TunnelIP6Src)
+}
+
 func TestParseFARShouldError(t *testing.T) {
 	createOp, updateOp := create, update
 
@@ -5,10 +5,12 @@
 package pfcpiface
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"net"
+	"strings"
 
 	"github.com/wmnsk/go-pfcp/ie"
 	"go.uber.org/zap"
@@ -64,6 +66,22 @@ func (pr PortRange) String() string {
 	return fmt.Sprintf("{%v-%v}", pr.low, pr.high)
 }
 
+// MarshalJSON encodes the range as [low, high], as its bounds are not exported.
+func (pr PortRange) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]uint16{pr.low, pr.high})
+}
+
+func (pr *PortRange) UnmarshalJSON(data []byte) error {
+	var bounds [2]uint16
+	if err := json.Unmarshal(data, &bounds); err != nil {
+		return err
+	}
+
+	pr.low, pr.high = bounds[0], bounds[1]
+
+	return nil
+}
+
 // Width returns the number of ports covered by this portRange.
 func (pr PortRange) Width() uint16 {
 	// Need to handle the zero value.
@@ -275,6 +293,13 @@ type ApplicationFilter struct {
 	SrcIPMask uint32
 	DstIPMask uint32
 	ProtoMask uint8
+
+	// SrcIP6 and DstIP6 are the IPv6 prefixes matched by IPv6 packets, a prefix length of 0
+	// matches any address.
+	SrcIP6          net.IP
+	DstIP6          net.IP
+	SrcIP6PrefixLen uint8
+	DstIP6PrefixLen uint8
 }
 
 type Pdr struct {
@@ -287,6 +312,12 @@ type Pdr struct {
 	TunnelIP4DstMask uint32
 	TunnelTEIDMask   uint32
 
+	// TunnelIP6Dst is the IPv6 address of the F-TEID and UeAddress6 the IPv6 prefix of the UE,
+	// nil if the PDR has none.
+	TunnelIP6Dst        net.IP
+	UeAddress6          net.IP
+	UeAddress6PrefixLen uint8
+
 	AppFilter ApplicationFilter
 
 	Precedence    uint32
@@ -298,18 +329,57 @@ type Pdr struct {
 	QerIDList     []uint32
 	NeedDecap     uint8
 	AllocIPFlag   bool
+	AllocIP6Flag  bool
 	AllocTEIDFlag bool
+	// TEIDPool is the pool of the TEID allocated by the UPF
+	TEIDPool string
+	// NetworkInstance is the network instance of the PDI: the name of a network instance of the
+	// UPF, or else the Network Instance IE as is, e.g. a DNN
+	NetworkInstance string
+	// UePool is the pool of the UE addresses allocated by the UPF
+	UePool string
 
 	ChooseID     uint8
 	ChooseIDFlag bool
 }
 
+// needAllocIP checks if the UP function must allocate the IPv4 address of the UE: if asked to
+// with the CHV4 flag, or if no UE address is given at all.
 func needAllocIP(ueIPaddr *ie.UEIPAddressFields) bool {
-	if has2ndBit(ueIPaddr.Flags) && !has5thBit(ueIPaddr.Flags) {
-		return false
+	if has5thBit(ueIPaddr.Flags) {
+		return true
 	}
 
-	return true
+	return !has2ndBit(ueIPaddr.Flags) && !has1stBit(ueIPaddr.Flags) && !has6thBit(ueIPaddr.Flags)
+}
+
+// needAllocIPv6 checks if the UP function must allocate the IPv6 prefix of the UE (CHV6 flag).
+func needAllocIPv6(ueIPaddr *ie.UEIPAddressFields) bool {
+	return has6thBit(ueIPaddr.Flags)
+}
+
+// ueIPv6PrefixLen returns the length of the IPv6 prefix of the UE: /64, unless shortened by
+// IPv6 prefix delegation bits (IPv6D flag) or given explicitly (IP6PL flag).
+func ueIPv6PrefixLen(ueIPaddr *ie.UEIPAddressFields) (uint8, error) {
+	prefixLen := uint8(64)
+
+	switch {
+	case has7thBit(ueIPaddr.Flags):
+		prefixLen = ueIPaddr.IPv6PrefixLength
+	case has4thBit(ueIPaddr.Flags):
+		if ueIPaddr.IPv6PrefixDelegationBits > prefixLen {
+			return 0, ErrInvalidArgumentWithReason("IPv6 prefix delegation bits",
+				ueIPaddr.IPv6PrefixDelegationBits, "longer than the /64 prefix")
+		}
+
+		prefixLen -= ueIPaddr.IPv6PrefixDelegationBits
+	}
+
+	if prefixLen == 0 || prefixLen > 128 {
+		return 0, ErrInvalidArgument("IPv6 prefix length", prefixLen)
+	}
+
+	return prefixLen, nil
 }
 
 func sameTeidAllocPerSession(teid *ie.FTEIDFields) bool {
@@ -320,43 +390,136 @@ func needTeidAlloc(teid *ie.FTEIDFields) bool {
 	return teid.HasCh()
 }
 
-func allocateTEID(p *Pdr, upf *Upf) error {
-	var err error
-	p.TunnelTEID, err = upf.teidAllocator.Allocate()
+// allocateTEID allocates the F-TEID of p from the TEID pool of its source interface and network
+// instance, with the IPv4 and/or IPv6 address of the pool as requested by the V4 and V6 flags of
+// fteid. An IPv4 address is chosen if none is requested.
+func allocateTEID(p *Pdr, upf *Upf, fteid *ie.FTEIDFields, networkInstance string) error {
+	wantIPv6 := fteid.HasIPv6()
+	wantIPv4 := fteid.HasIPv4() || !wantIPv6
+
+	pool, err := upf.teidPools.lookup(p.SrcIface, networkInstance)
 	if err != nil {
 		return err
 	}
 
-	switch p.SrcIface {
-	case access:
-		p.TunnelIP4Dst = ip2int(upf.AccessIP)
-	case core:
-		p.TunnelIP4Dst = ip2int(upf.CoreIP)
+	ip4, ip6 := pool.ip4, pool.ip6
+
+	if wantIPv4 && ip4 == nil {
+		return ErrOperationFailedWithReason("F-TEID allocation", "no IPv4 address in TEID pool "+pool.name)
+	}
+
+	if wantIPv6 && ip6 == nil {
+		return ErrOperationFailedWithReason("F-TEID allocation", "no IPv6 address in TEID pool "+pool.name)
+	}
+
+	p.TunnelTEID, err = pool.ids.Allocate()
+	if err != nil {
+		return ErrExhausted("TEID pool " + pool.name)
 	}
+
+	p.TEIDPool = pool.name
+
 	p.TunnelTEIDMask = 0xFFFFFFFF
-	p.TunnelIP4DstMask = 0xFFFFFFFF
+
+	if wantIPv4 {
+		p.TunnelIP4Dst = ip2int(ip4)
+		p.TunnelIP4DstMask = 0xFFFFFFFF
+	}
+
+	if wantIPv6 {
+		p.TunnelIP6Dst = ip6
+	}
+
 	return nil
 }
 
 func (af ApplicationFilter) String() string {
-	return fmt.Sprintf("ApplicationFilter(srcIP=%v/%x, dstIP=%v/%x, proto=%v/%x, srcPort=%v, dstPort=%v)",
-		int2ip(af.SrcIP), af.SrcIPMask, int2ip(af.DstIP), af.DstIPMask, af.Proto,
-		af.ProtoMask, af.SrcPortRange, af.DstPortRange)
+	return fmt.Sprintf("ApplicationFilter(srcIP=%v/%x, dstIP=%v/%x, srcIPv6=%v/%v, dstIPv6=%v/%v, "+
+		"proto=%v/%x, srcPort=%v, dstPort=%v)",
+		int2ip(af.SrcIP), af.SrcIPMask, int2ip(af.DstIP), af.DstIPMask, af.SrcIP6, af.SrcIP6PrefixLen,
+		af.DstIP6, af.DstIP6PrefixLen, af.Proto, af.ProtoMask, af.SrcPortRange, af.DstPortRange)
+}
+
+// splitIPNet returns ipnet as an IPv4 address and mask, or as an IPv6 prefix.
+func splitIPNet(ipnet *net.IPNet) (ip4, mask4 uint32, ip6 net.IP, prefixLen6 uint8) {
+	if !isIPv6(ipnet.IP) {
+		return ip2int(ipnet.IP), ipMask2int(ipnet.Mask), nil, 0
+	}
+
+	ones, _ := ipnet.Mask.Size()
+
+	return 0, 0, ipnet.IP, uint8(ones)
+}
+
+// setSrc sets the source network matched by the filter for packets of the family of ipnet.
+func (af *ApplicationFilter) setSrc(ipnet *net.IPNet) {
+	ip4, mask4, ip6, prefixLen6 := splitIPNet(ipnet)
+	if ip6 == nil {
+		af.SrcIP, af.SrcIPMask = ip4, mask4
+		return
+	}
+
+	af.SrcIP6, af.SrcIP6PrefixLen = ip6, prefixLen6
+}
+
+// setDst sets the destination network matched by the filter for packets of the family of ipnet.
+func (af *ApplicationFilter) setDst(ipnet *net.IPNet) {
+	ip4, mask4, ip6, prefixLen6 := splitIPNet(ipnet)
+	if ip6 == nil {
+		af.DstIP, af.DstIPMask = ip4, mask4
+		return
+	}
+
+	af.DstIP6, af.DstIP6PrefixLen = ip6, prefixLen6
+}
+
+// IsIPv6 checks if the filter matches on IPv6 addresses.
+func (af ApplicationFilter) IsIPv6() bool {
+	return af.SrcIP6PrefixLen != 0 || af.DstIP6PrefixLen != 0
 }
 
 func (p Pdr) String() string {
-	return fmt.Sprintf("PDR(id=%v, F-SEID=%v, srcIface=%v, tunnelIPv4Dst=%v/%x, "+
-		"tunnelTEID=%v/%x, ueAddress=%v, applicationFilter=%v, precedence=%v, F-SEID IP=%v, "+
-		"counterID=%v, farID=%v, qerIDs=%v, needDecap=%v, allocIPFlag=%v, allocTEIDFlag=%v, chooseID=%v)",
-		p.PdrID, p.FseID, p.SrcIface, int2ip(p.TunnelIP4Dst), p.TunnelIP4DstMask,
-		p.TunnelTEID, p.TunnelTEIDMask, int2ip(p.UeAddress), p.AppFilter, p.Precedence,
-		p.FseidIP, p.CtrID, p.FarID, p.QerIDList, p.NeedDecap, p.AllocIPFlag, p.AllocTEIDFlag, p.ChooseID)
+	return fmt.Sprintf("PDR(id=%v, F-SEID=%v, srcIface=%v, tunnelIPv4Dst=%v/%x, tunnelIPv6Dst=%v, "+
+		"tunnelTEID=%v/%x, ueAddress=%v, ueAddressIPv6=%v/%v, applicationFilter=%v, precedence=%v, "+
+		"F-SEID IP=%v, counterID=%v, farID=%v, qerIDs=%v, needDecap=%v, allocIPFlag=%v, allocIP6Flag=%v, "+
+		"allocTEIDFlag=%v, uePool=%v, chooseID=%v, networkInstance=%v)",
+		p.PdrID, p.FseID, p.SrcIface, int2ip(p.TunnelIP4Dst), p.TunnelIP4DstMask, p.TunnelIP6Dst,
+		p.TunnelTEID, p.TunnelTEIDMask, int2ip(p.UeAddress), p.UeAddress6, p.UeAddress6PrefixLen,
+		p.AppFilter, p.Precedence, p.FseidIP, p.CtrID, p.FarID, p.QerIDList, p.NeedDecap, p.AllocIPFlag,
+		p.AllocIP6Flag, p.AllocTEIDFlag, p.UePool, p.ChooseID, p.NetworkInstance)
 }
 
 func (p Pdr) IsAppFilterEmpty() bool {
 	return p.AppFilter.Proto == 0 &&
-		((p.IsUplink() && p.AppFilter.DstIP == 0 && p.AppFilter.DstPortRange.isWildcardMatch()) ||
-			(p.IsDownlink() && p.AppFilter.SrcIP == 0 && p.AppFilter.SrcPortRange.isWildcardMatch()))
+		((p.IsUplink() && p.AppFilter.DstIP == 0 && p.AppFilter.DstIP6PrefixLen == 0 &&
+			p.AppFilter.DstPortRange.isWildcardMatch()) ||
+			(p.IsDownlink() && p.AppFilter.SrcIP == 0 && p.AppFilter.SrcIP6PrefixLen == 0 &&
+				p.AppFilter.SrcPortRange.isWildcardMatch()))
+}
+
+// IsIPv6 checks if the PDR matches on IPv6 addresses: of the UE, the F-TEID or in its filter.
+func (p Pdr) IsIPv6() bool {
+	return p.UeAddress6 != nil || p.TunnelIP6Dst != nil || p.AppFilter.IsIPv6()
+}
+
+// ueNet6 returns the IPv6 prefix of the UE, nil if it has none.
+func (p Pdr) ueNet6() *net.IPNet {
+	if p.UeAddress6 == nil {
+		return nil
+	}
+
+	return &net.IPNet{IP: p.UeAddress6, Mask: net.CIDRMask(int(p.UeAddress6PrefixLen), 8*net.IPv6len)}
+}
+
+// assignedAddress returns the network of the UE that "assigned" stands for in flowDesc: its
+// IPv6 prefix if the flow description is an IPv6 one or the UE has no IPv4 address, its IPv4
+// address otherwise.
+func (p Pdr) assignedAddress(flowDesc string) string {
+	if p.UeAddress6 != nil && (p.UeAddress == 0 || strings.Contains(flowDesc, ":")) {
+		return p.ueNet6().String()
+	}
+
+	return int2ip(p.UeAddress).String()
 }
 
 func (p Pdr) IsUplink() bool {
@@ -367,7 +530,9 @@ func (p Pdr) IsDownlink() bool {
 	return p.SrcIface == core
 }
 
-func (p *Pdr) parseUEAddressIE(ueAddrIE *ie.IE, ippool *IPPool) error {
+// parseUEAddressIE parses the UE IP Address IE, allocating the UE addresses requested from the
+// pool of the DNN dnn: the addresses reserved for the UE identified by ueKeys, if any.
+func (p *Pdr) parseUEAddressIE(ueAddrIE *ie.IE, ippools *ipPools, dnn string, ueKeys []string) error {
 	var ueIP4 net.IP
 
 	ueIPaddr, err := ueAddrIE.UEIPAddress()
@@ -375,19 +540,52 @@ func (p *Pdr) parseUEAddressIE(ueAddrIE *ie.IE, ippool *IPPool) error {
 		return err
 	}
 
+	if needAllocIPv6(ueIPaddr) {
+		log.Infof("UPF should alloc UE IPv6 prefix for SEID %v. CHV6 flag set", p.FseID)
+
+		pool, err := ippools.lookup(dnn)
+		if err != nil {
+			return err
+		}
+
+		p.UeAddress6, err = pool.allocIPv6(p.FseID, ueKeys)
+		if err != nil {
+			log.Error("failed to allocate UE IPv6 prefix")
+			return err
+		}
+
+		p.UeAddress6PrefixLen = uePrefixLen
+		p.AllocIP6Flag = true
+		p.UePool = pool.name
+	} else if has1stBit(ueIPaddr.Flags) {
+		if err = p.parseUEIPv6Address(ueIPaddr); err != nil {
+			return err
+		}
+	}
+
+	// An IPv6 only UE
+	if p.UeAddress6 != nil && !has2ndBit(ueIPaddr.Flags) && !has5thBit(ueIPaddr.Flags) {
+		return nil
+	}
+
 	if needAllocIP(ueIPaddr) {
-		/* alloc IPV6 if CHV6 is enabled : TBD */
 		log.Infof("UPF should alloc UE IP for SEID %v. CHV4 flag set", p.FseID)
 
-		ueIP4, err = ippool.LookupOrAllocIP(p.FseID)
+		pool, err := ippools.lookup(dnn)
+		if err != nil {
+			return err
+		}
+
+		ueIP4, err = pool.allocIPv4(p.FseID, ueKeys)
 		if err != nil {
 			log.Error("failed to allocate UE IP")
 			return err
 		}
 
-		log.Debugf("Found or allocated new IP %v from pool %v", ueIP4, ippool)
+		log.Debugf("Found or allocated new IP %v from pool %v", ueIP4, pool.name)
 
 		p.AllocIPFlag = true
+		p.UePool = pool.name
 	} else {
 		ueIP4 = ueIPaddr.IPv4Address
 	}
@@ -403,6 +601,23 @@ func (p *Pdr) parseUEAddressIE(ueAddrIE *ie.IE, ippool *IPPool) error {
 	return nil
 }
 
+func (p *Pdr) parseUEIPv6Address(ueIPaddr *ie.UEIPAddressFields) error {
+	if !isIPv6(ueIPaddr.IPv6Address) {
+		return ErrOperationFailedWithParam("parse UE Address IE",
+			"IPv6 address", ueIPaddr.IPv6Address)
+	}
+
+	prefixLen, err := ueIPv6PrefixLen(ueIPaddr)
+	if err != nil {
+		return err
+	}
+
+	p.UeAddress6 = ueIPaddr.IPv6Address.Mask(net.CIDRMask(int(prefixLen), 8*net.IPv6len))
+	p.UeAddress6PrefixLen = prefixLen
+
+	return nil
+}
+
 func (p *Pdr) parseSourceInterfaceIE(srcIfaceIE *ie.IE) error {
 	srcIface, err := srcIfaceIE.SourceInterface()
 	if err != nil {
@@ -423,7 +638,9 @@ func (p *Pdr) parseSourceInterfaceIE(srcIfaceIE *ie.IE) error {
 	return nil
 }
 
-func (p *Pdr) parseFTEID(teidIE *ie.IE, upf *Upf, session *PFCPSession) error {
+// parseFTEID parses the F-TEID of p. The UPF allocates it if asked to, from the TEID pool of the
+// source interface of p and of networkInstance.
+func (p *Pdr) parseFTEID(teidIE *ie.IE, upf *Upf, session *PFCPSession, networkInstance string) error {
 	fteid, err := teidIE.FTEID()
 	if err != nil {
 		return err
@@ -431,13 +648,19 @@ func (p *Pdr) parseFTEID(teidIE *ie.IE, upf *Upf, session *PFCPSession) error {
 
 	if !needTeidAlloc(fteid) {
 		teid := fteid.TEID
-		tunnelIPv4Address := fteid.IPv4Address
 
 		if teid != 0 {
 			p.TunnelTEID = teid
 			p.TunnelTEIDMask = 0xFFFFFFFF
-			p.TunnelIP4Dst = ip2int(tunnelIPv4Address)
-			p.TunnelIP4DstMask = 0xFFFFFFFF
+
+			if fteid.HasIPv4() {
+				p.TunnelIP4Dst = ip2int(fteid.IPv4Address)
+				p.TunnelIP4DstMask = 0xFFFFFFFF
+			}
+
+			if fteid.HasIPv6() {
+				p.TunnelIP6Dst = fteid.IPv6Address
+			}
 		}
 		return nil
 	}
@@ -450,18 +673,23 @@ func (p *Pdr) parseFTEID(teidIE *ie.IE, upf *Upf, session *PFCPSession) error {
 			// Set this PDR to the choosed one on the session
 			p.ChooseIDFlag = true
 			p.ChooseID = fteid.ChooseID
-			err := allocateTEID(p, upf)
+			err := allocateTEID(p, upf, fteid, networkInstance)
 			if err != nil {
 				return err
 			}
 		} else {
+			// This PDR shares the F-TEID, and can be chosen in turn if the other one is removed
+			p.ChooseIDFlag = true
+			p.ChooseID = fteid.ChooseID
+			p.TEIDPool = choosedPdr.TEIDPool
 			p.TunnelTEID = choosedPdr.TunnelTEID
 			p.TunnelTEIDMask = choosedPdr.TunnelTEIDMask
 			p.TunnelIP4Dst = choosedPdr.TunnelIP4Dst
 			p.TunnelIP4DstMask = choosedPdr.TunnelIP4DstMask
+			p.TunnelIP6Dst = choosedPdr.TunnelIP6Dst
 		}
 	} else {
-		err := allocateTEID(p, upf)
+		err := allocateTEID(p, upf, fteid, networkInstance)
 		if err != nil {
 			return err
 		}
@@ -492,7 +720,7 @@ func (p *Pdr) parseApplicationID(ie *ie.IE, appPFDs map[string]appPFD) error {
 			zap.String("Flow Description", flowDesc),
 		)
 
-		ipf, err := parseFlowDesc(flowDesc, int2ip(p.UeAddress).String())
+		ipf, err := parseFlowDesc(flowDesc, p.assignedAddress(flowDesc))
 		if err != nil {
 			return errBadFilterDesc
 		}
@@ -510,10 +738,8 @@ func (p *Pdr) parseApplicationID(ie *ie.IE, appPFDs map[string]appPFD) error {
 				p.AppFilter.ProtoMask = math.MaxUint8
 			}
 			// TODO: Verify assumption that flow description in case of PFD is to be taken as-is
-			p.AppFilter.DstIP = ip2int(ipf.dst.IPNet.IP)
-			p.AppFilter.DstIPMask = ipMask2int(ipf.dst.IPNet.Mask)
-			p.AppFilter.SrcIP = ip2int(ipf.src.IPNet.IP)
-			p.AppFilter.SrcIPMask = ipMask2int(ipf.src.IPNet.Mask)
+			p.AppFilter.setDst(ipf.dst.IPNet)
+			p.AppFilter.setSrc(ipf.src.IPNet)
 			p.AppFilter.DstPortRange = ipf.dst.ports
 			p.AppFilter.SrcPortRange = ipf.src.ports
 
@@ -540,6 +766,10 @@ func (p *Pdr) parseSDFFilter(ie *ie.IE, session *PFCPSession) error {
 			p.AppFilter.DstIPMask = sdfFilter.SrcIPMask
 			p.AppFilter.SrcIP = sdfFilter.DstIP
 			p.AppFilter.SrcIPMask = sdfFilter.DstIPMask
+			p.AppFilter.DstIP6 = sdfFilter.SrcIP6
+			p.AppFilter.DstIP6PrefixLen = sdfFilter.SrcIP6PrefixLen
+			p.AppFilter.SrcIP6 = sdfFilter.DstIP6
+			p.AppFilter.SrcIP6PrefixLen = sdfFilter.DstIP6PrefixLen
 			p.AppFilter.DstPortRange = sdfFilter.SrcPortRange
 			p.AppFilter.SrcPortRange = sdfFilter.DstPortRange
 
@@ -562,7 +792,7 @@ func (p *Pdr) parseSDFFilter(ie *ie.IE, session *PFCPSession) error {
 		zap.String("Flow Description", flowDesc),
 	)
 
-	ipf, err := parseFlowDesc(flowDesc, int2ip(p.UeAddress).String())
+	ipf, err := parseFlowDesc(flowDesc, p.assignedAddress(flowDesc))
 	if err != nil {
 		return errBadFilterDesc
 	}
@@ -573,10 +803,8 @@ func (p *Pdr) parseSDFFilter(ie *ie.IE, session *PFCPSession) error {
 	}
 
 	if p.SrcIface == core {
-		p.AppFilter.DstIP = ip2int(ipf.dst.IPNet.IP)
-		p.AppFilter.DstIPMask = ipMask2int(ipf.dst.IPNet.Mask)
-		p.AppFilter.SrcIP = ip2int(ipf.src.IPNet.IP)
-		p.AppFilter.SrcIPMask = ipMask2int(ipf.src.IPNet.Mask)
+		p.AppFilter.setDst(ipf.dst.IPNet)
+		p.AppFilter.setSrc(ipf.src.IPNet)
 		p.AppFilter.DstPortRange = ipf.dst.ports
 		p.AppFilter.SrcPortRange = ipf.src.ports
 
@@ -587,10 +815,8 @@ func (p *Pdr) parseSDFFilter(ie *ie.IE, session *PFCPSession) error {
 			p.AppFilter.DstPortRange = newWildcardPortRange()
 		}
 	} else if p.SrcIface == access {
-		p.AppFilter.SrcIP = ip2int(ipf.dst.IPNet.IP)
-		p.AppFilter.SrcIPMask = ipMask2int(ipf.dst.IPNet.Mask)
-		p.AppFilter.DstIP = ip2int(ipf.src.IPNet.IP)
-		p.AppFilter.DstIPMask = ipMask2int(ipf.src.IPNet.Mask)
+		p.AppFilter.setSrc(ipf.dst.IPNet)
+		p.AppFilter.setDst(ipf.src.IPNet)
 		// Ports are flipped for access PDRs
 		p.AppFilter.DstPortRange = ipf.src.ports
 		p.AppFilter.SrcPortRange = ipf.dst.ports
@@ -606,21 +832,33 @@ func (p *Pdr) parseSDFFilter(ie *ie.IE, session *PFCPSession) error {
 	return nil
 }
 
-func (p *Pdr) parsePDI(pdiIEs []*ie.IE, appPFDs map[string]appPFD, ippool *IPPool, upf *Upf, session *PFCPSession) error {
+func (p *Pdr) parsePDI(pdiIEs []*ie.IE, appPFDs map[string]appPFD, ippools *ipPools, upf *Upf, session *PFCPSession) error {
+	// The Network Instance selects the pool of the UE addresses, and with the Source Interface
+	// the pool of the TEIDs
+	var dnn string
+
 	for _, pdiIE := range pdiIEs {
 		switch pdiIE.Type {
-		case ie.UEIPAddress:
-			if err := p.parseUEAddressIE(pdiIE, ippool); err != nil {
-				log.Errorf("Failed to parse UE Address IE: %v", err)
-				return err
-			}
+		case ie.NetworkInstance:
+			dnn, _ = pdiIE.NetworkInstanceHeuristic()
+			p.NetworkInstance = upf.networkInstances.resolve(dnn)
 		case ie.SourceInterface:
 			if err := p.parseSourceInterfaceIE(pdiIE); err != nil {
 				log.Errorf("Failed to parse Source Interface IE: %v", err)
 				return err
 			}
+		}
+	}
+
+	for _, pdiIE := range pdiIEs {
+		switch pdiIE.Type {
+		case ie.UEIPAddress:
+			if err := p.parseUEAddressIE(pdiIE, ippools, dnn, session.ueKeys); err != nil {
+				log.Errorf("Failed to parse UE Address IE: %v", err)
+				return err
+			}
 		case ie.FTEID:
-			if err := p.parseFTEID(pdiIE, upf, session); err != nil {
+			if err := p.parseFTEID(pdiIE, upf, session, p.NetworkInstance); err != nil {
 				log.Errorf("Failed to parse F-TEID IE: %v", err)
 				return err
 			}
@@ -637,6 +875,14 @@ func (p *Pdr) parsePDI(pdiIEs []*ie.IE, appPFDs map[string]appPFD, ippool *IPPoo
 		p.AppFilter.SrcIPMask = math.MaxUint32 // /32
 	}
 
+	if ueNet6 := p.ueNet6(); ueNet6 != nil {
+		if p.IsDownlink() {
+			p.AppFilter.setDst(ueNet6)
+		} else if p.IsUplink() {
+			p.AppFilter.setSrc(ueNet6)
+		}
+	}
+
 	// make another iteration because Application ID and SDF Filter depend on UE IP Address IE
 	for _, ie2 := range pdiIEs {
 		switch ie2.Type {
@@ -656,7 +902,7 @@ func (p *Pdr) parsePDI(pdiIEs []*ie.IE, appPFDs map[string]appPFD, ippool *IPPoo
 	return nil
 }
 
-func (p *Pdr) parsePDR(ie1 *ie.IE, appPFDs map[string]appPFD, ippool *IPPool, upf *Upf, session *PFCPSession) error {
+func (p *Pdr) parsePDR(ie1 *ie.IE, appPFDs map[string]appPFD, ippools *ipPools, upf *Upf, session *PFCPSession) error {
 	/* reset outerHeaderRemoval to begin with */
 	outerHeaderRemoval := uint8(0)
 	p.QerIDList = make([]uint32, 0)
@@ -685,7 +931,7 @@ func (p *Pdr) parsePDR(ie1 *ie.IE, appPFDs map[string]appPFD, ippool *IPPool, up
 		outerHeaderRemoval = 1
 	}
 
-	err = p.parsePDI(pdi, appPFDs, ippool, upf, session)
+	err = p.parsePDI(pdi, appPFDs, ippools, upf, session)
 	if err != nil && !errors.Is(err, errBadFilterDesc) {
 		return err
 	}
@@ -120,11 +120,11 @@ func Test_parsePDR(t *testing.T) {
 				flowDescs: nil,
 			}
 			mockPDR := &Pdr{}
-			mockIPPool, _ := NewIPPool("10.0.0.0")
+			mockIPPools, _ := newUEIPPools(CPIfaceInfo{UEIPPool: "10.0.0.0"})
 
 			session := &PFCPSession{localSEID: FSEID}
 
-			err := mockPDR.parsePDR(scenario.input, mockMapPFD, mockIPPool, upf, session)
+			err := mockPDR.parsePDR(scenario.input, mockMapPFD, mockIPPools, upf, session)
 			require.NoError(t, err)
 
 			assert.Equal(t, mockPDR, scenario.expected)
@@ -162,11 +162,11 @@ func TestParsePDRShouldError(t *testing.T) {
 				flowDescs: nil,
 			}
 			mockPDR := &Pdr{}
-			mockIPPool, _ := NewIPPool("10.0.0.0")
+			mockIPPools, _ := newUEIPPools(CPIfaceInfo{UEIPPool: "10.0.0.0"})
 
 			session := &PFCPSession{localSEID: FSEID}
 
-			err := mockPDR.parsePDR(scenario.input, mockMapPFD, mockIPPool, upf, session)
+			err := mockPDR.parsePDR(scenario.input, mockMapPFD, mockIPPools, upf, session)
 			require.Error(t, err)
 
 			assert.Equal(t, scenario.expected, mockPDR)
@@ -553,6 +553,7 @@ func Test_portRange_Width(t *testing.T) {
 
 func Test_pdr_parseSDFFilter(t *testing.T) {
 	ueAddress := "17.0.0.1"
+	uePrefix := net.ParseIP("2001:db8:1:2::")
 	session := &PFCPSession{localSEID: 1}
 
 	newFilter := func(flowDesc string) *ie.IE {
@@ -634,6 +635,40 @@ func Test_pdr_parseSDFFilter(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:      "downlink IPv6 SDF filter",
+			sdfIE:     newFilter("permit out 6 from 2001:db8:ff::/48 443 to assigned"),
+			direction: core,
+			wantAppFilter: ApplicationFilter{
+				FilterID:        1,
+				SrcIP6:          net.ParseIP("2001:db8:ff::"),
+				SrcIP6PrefixLen: 48,
+				DstIP6:          uePrefix,
+				DstIP6PrefixLen: 64,
+				SrcPortRange:    newExactMatchPortRange(443),
+				DstPortRange:    newWildcardPortRange(),
+				Proto:           6,
+				ProtoMask:       math.MaxUint8,
+			},
+			wantErr: false,
+		},
+		{
+			name:      "uplink IPv6 SDF filter",
+			sdfIE:     newFilter("permit out 6 from 2001:db8:ff::1 443 to assigned"),
+			direction: access,
+			wantAppFilter: ApplicationFilter{
+				FilterID:        1,
+				SrcIP6:          uePrefix,
+				SrcIP6PrefixLen: 64,
+				DstIP6:          net.ParseIP("2001:db8:ff::1"),
+				DstIP6PrefixLen: 128,
+				SrcPortRange:    newWildcardPortRange(),
+				DstPortRange:    newExactMatchPortRange(443),
+				Proto:           6,
+				ProtoMask:       math.MaxUint8,
+			},
+			wantErr: false,
+		},
 		{
 			name:    "wrong IE type passed",
 			sdfIE:   ie.NewQERID(0),
@@ -648,8 +683,10 @@ func Test_pdr_parseSDFFilter(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := &Pdr{
-				UeAddress: ip2int(net.ParseIP("17.0.0.1")),
-				SrcIface:  tt.direction,
+				UeAddress:           ip2int(net.ParseIP("17.0.0.1")),
+				UeAddress6:          uePrefix,
+				UeAddress6PrefixLen: 64,
+				SrcIface:            tt.direction,
 			}
 			if err := p.parseSDFFilter(tt.sdfIE, session); (err != nil) != tt.wantErr {
 				t.Errorf("parseSDFFilter() error = %v, wantErr %v", err, tt.wantErr)
@@ -664,11 +701,21 @@ func Test_pdr_parseSDFFilter(t *testing.T) {
 
 func Test_pdr_parsePDI(t *testing.T) {
 	ueAddress := "17.0.0.1"
+	ueAddress6 := "2001:db8:1:2::5"
+	uePrefix := net.ParseIP("2001:db8:1:2::")
+
+	pools, err := newUEIPPools(CPIfaceInfo{
+		UEIPPool: "10.250.0.0/24",
+		UEIPPools: []UEIPPoolInfo{
+			{Name: "ims", Dnn: "ims", IPv4Subnet: "10.251.0.0/24", IPv6Subnet: "2001:db8:100::/56"},
+		},
+	})
+	require.NoError(t, err)
 
 	type args struct {
 		pdiIEs  []*ie.IE
 		appPFDs map[string]appPFD
-		ippool  *IPPool
+		ippools *ipPools
 	}
 
 	tests := []struct {
@@ -716,6 +763,124 @@ func Test_pdr_parsePDI(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "downlink PDR - dual-stack UE",
+			args: args{
+				pdiIEs: []*ie.IE{
+					ie.NewUEIPAddress(0x3, ueAddress, ueAddress6, 0, 0),
+					ie.NewSourceInterface(ie.SrcInterfaceCore),
+				},
+			},
+			wantPDR: Pdr{
+				SrcIface:            core,
+				SrcIfaceMask:        math.MaxUint8,
+				UeAddress:           ip2int(net.ParseIP(ueAddress)),
+				UeAddress6:          uePrefix,
+				UeAddress6PrefixLen: 64,
+				AppFilter: ApplicationFilter{
+					DstIP:           ip2int(net.ParseIP(ueAddress)),
+					DstIPMask:       math.MaxUint32,
+					DstIP6:          uePrefix,
+					DstIP6PrefixLen: 64,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "uplink PDR - IPv6 UE with delegated prefix",
+			args: args{
+				pdiIEs: []*ie.IE{
+					ie.NewUEIPAddress(0x9, "", "2001:db8:1::", 8, 0),
+					ie.NewSourceInterface(ie.SrcInterfaceAccess),
+				},
+			},
+			wantPDR: Pdr{
+				SrcIface:            access,
+				SrcIfaceMask:        math.MaxUint8,
+				UeAddress6:          net.ParseIP("2001:db8:1::"),
+				UeAddress6PrefixLen: 56,
+				AppFilter: ApplicationFilter{
+					SrcIP6:          net.ParseIP("2001:db8:1::"),
+					SrcIP6PrefixLen: 56,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "UE IPv6 prefix allocation disabled",
+			args: args{
+				pdiIEs: []*ie.IE{
+					ie.NewUEIPAddress(0x20, "", "", 0, 0),
+					ie.NewSourceInterface(ie.SrcInterfaceCore),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "UE IPv6 prefix allocation without IPv6 subnet",
+			args: args{
+				pdiIEs: []*ie.IE{
+					ie.NewUEIPAddress(0x20, "", "", 0, 0),
+					ie.NewSourceInterface(ie.SrcInterfaceCore),
+					ie.NewNetworkInstance("internet"),
+				},
+				ippools: pools,
+			},
+			wantErr: true,
+		},
+		{
+			name: "dual-stack UE address allocation from the pool of the DNN",
+			args: args{
+				pdiIEs: []*ie.IE{
+					ie.NewUEIPAddress(0x30, "", "", 0, 0),
+					ie.NewSourceInterface(ie.SrcInterfaceCore),
+					ie.NewNetworkInstance("ims"),
+				},
+				ippools: pools,
+			},
+			wantPDR: Pdr{
+				SrcIface:            core,
+				SrcIfaceMask:        math.MaxUint8,
+				UeAddress:           ip2int(net.ParseIP("10.251.0.1")),
+				UeAddress6:          net.ParseIP("2001:db8:100::"),
+				UeAddress6PrefixLen: 64,
+				AllocIPFlag:         true,
+				AllocIP6Flag:        true,
+				UePool:              "ims",
+				NetworkInstance:     "ims",
+				AppFilter: ApplicationFilter{
+					DstIP:           ip2int(net.ParseIP("10.251.0.1")),
+					DstIPMask:       math.MaxUint32,
+					DstIP6:          net.ParseIP("2001:db8:100::"),
+					DstIP6PrefixLen: 64,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "UE IP allocation from the default pool",
+			args: args{
+				pdiIEs: []*ie.IE{
+					ie.NewUEIPAddress(0x10, "", "", 0, 0),
+					ie.NewSourceInterface(ie.SrcInterfaceCore),
+					ie.NewNetworkInstance("internet"),
+				},
+				ippools: pools,
+			},
+			wantPDR: Pdr{
+				SrcIface:        core,
+				SrcIfaceMask:    math.MaxUint8,
+				UeAddress:       ip2int(net.ParseIP("10.250.0.1")),
+				AllocIPFlag:     true,
+				UePool:          defaultPoolName,
+				NetworkInstance: "internet",
+				AppFilter: ApplicationFilter{
+					DstIP:     ip2int(net.ParseIP("10.250.0.1")),
+					DstIPMask: math.MaxUint32,
+				},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -724,7 +889,7 @@ func Test_pdr_parsePDI(t *testing.T) {
 			upf := &Upf{}
 			session := &PFCPSession{localSEID: 1}
 
-			if err := p.parsePDI(tt.args.pdiIEs, tt.args.appPFDs, tt.args.ippool, upf, session); (err != nil) != tt.wantErr {
+			if err := p.parsePDI(tt.args.pdiIEs, tt.args.appPFDs, tt.args.ippools, upf, session); (err != nil) != tt.wantErr {
 				t.Errorf("parsePDI() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
@@ -734,3 +899,72 @@ func Test_pdr_parsePDI(t *testing.T) {
 		})
 	}
 }
+
+func Test_pdr_parsePDI_NetworkInstance(t *testing.T) {
+	upf := &Upf{AccessIP: net.ParseIP("192.168.0.1"), CoreIP: net.ParseIP("192.168.1.1")}
+
+	var err error
+
+	upf.networkInstances, err = newNetworkInstances([]NetworkInstanceInfo{
+		{Name: "enterprise", AccessIP: "192.168.10.1", Dnns: []string{"corp"}},
+	}, false)
+	require.NoError(t, err)
+
+	upf.teidPools, err = newTEIDPools(CPIfaceInfo{}, upf.AccessIP, nil, upf.CoreIP, nil)
+	require.NoError(t, err)
+	require.NoError(t, upf.teidPools.addNetworkInstances(upf.networkInstances))
+
+	parse := func(t *testing.T, networkInstance string) Pdr {
+		var p Pdr
+
+		// The Source Interface follows the F-TEID
+		require.NoError(t, p.parsePDI([]*ie.IE{
+			ie.NewFTEID(0x05, 0, nil, nil, 0),
+			ie.NewNetworkInstance(networkInstance),
+			ie.NewSourceInterface(ie.SrcInterfaceAccess),
+		}, nil, nil, upf, &PFCPSession{localSEID: 1}))
+
+		return p
+	}
+
+	p := parse(t, "corp")
+	require.Equal(t, "enterprise", p.NetworkInstance, "the DNN names its network instance")
+	require.Equal(t, "enterprise-access", p.TEIDPool)
+	require.Equal(t, ip2int(net.ParseIP("192.168.10.1")), p.TunnelIP4Dst)
+
+	p = parse(t, "internet")
+	require.Equal(t, "internet", p.NetworkInstance)
+	require.Equal(t, teidPoolAccess, p.TEIDPool)
+	require.Equal(t, ip2int(upf.AccessIP), p.TunnelIP4Dst)
+}
+
+func Test_pdr_parseFTEID_IPv6(t *testing.T) {
+	n3Address6 := net.ParseIP("2001:db8::1")
+	upf := &Upf{AccessIP: net.ParseIP("192.168.0.1"), AccessIP6: n3Address6}
+
+	var err error
+
+	upf.teidPools, err = newTEIDPools(CPIfaceInfo{}, upf.AccessIP, upf.AccessIP6, nil, nil)
+	require.NoError(t, err)
+
+	session := &PFCPSession{localSEID: 1}
+
+	// F-TEID given by the CP function
+	p := Pdr{SrcIface: access}
+	require.NoError(t, p.parseFTEID(ie.NewFTEID(0x02, 1234, nil, net.ParseIP("2001:db8::2"), 0), upf, session, ""))
+	require.Equal(t, uint32(1234), p.TunnelTEID)
+	require.Zero(t, p.TunnelIP4DstMask)
+	require.Equal(t, net.ParseIP("2001:db8::2"), p.TunnelIP6Dst)
+
+	// F-TEID allocated by the UP function, with both addresses of the interface
+	p = Pdr{SrcIface: access}
+	require.NoError(t, p.parseFTEID(ie.NewFTEID(0x07, 0, nil, nil, 0), upf, session, ""))
+	require.True(t, p.AllocTEIDFlag)
+	require.Equal(t, ip2int(upf.AccessIP), p.TunnelIP4Dst)
+	require.Equal(t, n3Address6, p.TunnelIP6Dst)
+	require.True(t, p.IsIPv6())
+
+	// No IPv6 address on the core interface
+	p = Pdr{SrcIface: core}
+	require.Error(t, p.parseFTEID(ie.NewFTEID(0x06, 0, nil, nil, 0), upf, session, ""))
+}
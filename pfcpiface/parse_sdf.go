@@ -29,7 +29,11 @@ func (ep *endpoint) parseNet(ipnet string) error {
 
 	switch len(ipNetFields) {
 	case 1:
-		ipnet = ipNetFields[0] + "/32"
+		if strings.Contains(ipnet, ":") {
+			ipnet += "/128"
+		} else {
+			ipnet += "/32"
+		}
 	case 2:
 	default:
 		return ErrInvalidArgument("network string", len(ipNetFields))
@@ -50,6 +50,10 @@ func Test_endpoint_parseNet(t *testing.T) {
 			args:    "2001:db8:a0b:12f0::1/32",
 			want:    endpoint{IPNet: mustParseCIDRNet("2001:db8:a0b:12f0::1/32")},
 			wantErr: false},
+		{name: "single IPv6 without net",
+			args:    "2001:db8:a0b:12f0::1",
+			want:    endpoint{IPNet: mustParseCIDRNet("2001:db8:a0b:12f0::1/128")},
+			wantErr: false},
 		{name: "invalid empty arg",
 			args:    "",
 			wantErr: true},
@@ -2,6 +2,7 @@ package pfcpiface
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/wmnsk/go-pfcp/ie"
 )
@@ -17,6 +18,13 @@ type Urr struct {
 	LocalThreshold uint64
 	VolThreshold   VolumeData
 	VolQuota       VolumeData
+	// time based measurement, zero values are not provisioned
+	MeasurementPeriod       time.Duration
+	TimeThreshold           time.Duration
+	TimeQuota               time.Duration
+	QuotaHoldingTime        time.Duration
+	InactivityDetectionTime time.Duration
+	MonitoringTime          time.Time
 	// local session ID
 	FseID uint64
 }
@@ -41,11 +49,35 @@ func (r *ReportTrigger) isVOLQUSet() bool {
 	return has1stBit(u8)
 }
 
+func (r *ReportTrigger) isPERIOSet() bool {
+	return r.Flags&usageReportTriggerPERIO != 0
+}
+
+func (r *ReportTrigger) isTIMTHSet() bool {
+	return r.Flags&usageReportTriggerTIMTH != 0
+}
+
+func (r *ReportTrigger) isQUHTISet() bool {
+	return r.Flags&usageReportTriggerQUHTI != 0
+}
+
+func (r *ReportTrigger) isTIMQUSet() bool {
+	return r.Flags&usageReportTriggerTIMQU != 0
+}
+
+// measuresDuration checks if the DURAT flag of the Measurement Method is set.
+func (u *Urr) measuresDuration() bool {
+	return has1stBit(u.MeasureMethod)
+}
+
 func (u *Urr) String() string {
 	return fmt.Sprintf("URR(id=%v, ctrID=%v, pdrID=%v, F-SEID IPv4=%v, measureMethod=%v, "+
-		"reportOpen=%v, trigger=%v, localThreshold=%v, volThreshold=%v, volQuota=%v)",
+		"reportOpen=%v, trigger=%v, localThreshold=%v, volThreshold=%v, volQuota=%v, "+
+		"measurementPeriod=%v, timeThreshold=%v, timeQuota=%v, quotaHoldingTime=%v, "+
+		"inactivityDetectionTime=%v, monitoringTime=%v)",
 		u.UrrID, u.CtrID, u.PdrID, u.FseidIP, u.MeasureMethod, u.ReportOpen, u.Trigger,
-		u.LocalThreshold, u.VolThreshold, u.VolQuota)
+		u.LocalThreshold, u.VolThreshold, u.VolQuota, u.MeasurementPeriod, u.TimeThreshold,
+		u.TimeQuota, u.QuotaHoldingTime, u.InactivityDetectionTime, u.MonitoringTime)
 }
 
 func (u *Urr) parseURR(ie1 *ie.IE, seid uint64) error {
@@ -88,6 +120,31 @@ func (u *Urr) parseURR(ie1 *ie.IE, seid uint64) error {
 		volumeQuota.DownlinkVol = volQuotaField.DownlinkVolume
 	}
 
+	if period, err := ie1.MeasurementPeriod(); err == nil {
+		u.MeasurementPeriod = period
+	}
+
+	if threshold, err := ie1.TimeThreshold(); err == nil {
+		u.TimeThreshold = time.Duration(threshold) * time.Second
+	}
+
+	if quota, err := ie1.TimeQuota(); err == nil {
+		u.TimeQuota = quota
+	}
+
+	if holdingTime, err := ie1.QuotaHoldingTime(); err == nil {
+		u.QuotaHoldingTime = holdingTime
+	}
+
+	if inactivityTime, err := ie1.InactivityDetectionTime(); err == nil {
+		u.InactivityDetectionTime = time.Duration(inactivityTime) * time.Second
+	}
+
+	if monitoringTime, err := ie1.MonitoringTime(); err == nil {
+		// UTC, so that URRs compare equal once recovered from the session store
+		u.MonitoringTime = monitoringTime.UTC()
+	}
+
 	u.UrrID = uint32(urrID)
 	u.MeasureMethod = measureMethod
 	u.Trigger = reportTrigger
@@ -6,8 +6,6 @@ package pfcpiface
 import (
 	"testing"
 
-	pfcpsimLib "github.com/omec-project/pfcpsim/pkg/pfcpsim/session"
-
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/wmnsk/go-pfcp/ie"
@@ -19,44 +17,43 @@ type urrTestCase struct {
 	description string
 }
 
+// newTestURRIEs returns the IEs of a URR measuring volume, with a volume threshold and a volume
+// quota.
+func newTestURRIEs() []*ie.IE {
+	return []*ie.IE{
+		ie.NewURRID(999),
+		ie.NewMeasurementMethod(0, 1, 0),
+		ie.NewVolumeThreshold(7, 1000, 200, 800),
+		ie.NewVolumeQuota(3, 700, 300, 400),
+		ie.NewReportingTriggers(2),
+	}
+}
+
 func TestParseURR(t *testing.T) {
 	FSEID := uint64(100)
 
 	for _, scenario := range []urrTestCase{
 		{
-			input: pfcpsimLib.NewURRBuilder().
-				WithID(999).
-				WithMethod(pfcpsimLib.IEMethod(create)).
-				WithMeasurementMethodVolume(1).
-				WithVolThresholdFlags(7).
-				WithVolThresholdTotalVol(1000).
-				WithVolThresholdUplinkVol(200).
-				WithVolThresholdDownlinkVol(800).
-				WithVolQuotaFlags(3).
-				WithVolQuotaTotalVol(700).
-				WithVolQuotaUplinkVol(300).
-				WithVolQuotaDownlinkVol(400).
-				WithTriggers(2).
-				Build(),
+			input: ie.NewCreateURR(newTestURRIEs()...),
 			expected: &Urr{
-				UrrID: 999,
+				UrrID:         999,
 				MeasureMethod: 2,
-				ReportOpen: true,
+				ReportOpen:    true,
 				Trigger: ReportTrigger{
 					Flags: 2,
 				},
 				LocalThreshold: 1000,
 				VolThreshold: VolumeData{
-					Flags: 7,
-					TotalVol: 1000,
-					UplinkVol: 200,
+					Flags:       7,
+					TotalVol:    1000,
+					UplinkVol:   200,
 					DownlinkVol: 800,
 				},
 				VolQuota: VolumeData{
 					// only TotalVol and UplinkVol are set
-					Flags: 3,
-					TotalVol: 700,
-					UplinkVol: 300,
+					Flags:       3,
+					TotalVol:    700,
+					UplinkVol:   300,
 					DownlinkVol: 0,
 				},
 				FseID: FSEID,
@@ -64,39 +61,26 @@ func TestParseURR(t *testing.T) {
 			description: "Valid Create URR input",
 		},
 		{
-			input: pfcpsimLib.NewURRBuilder().
-				WithID(999).
-				WithMethod(pfcpsimLib.IEMethod(update)).
-				WithMeasurementMethodVolume(1).
-				WithVolThresholdFlags(7).
-				WithVolThresholdTotalVol(1000).
-				WithVolThresholdUplinkVol(200).
-				WithVolThresholdDownlinkVol(800).
-				WithVolQuotaFlags(3).
-				WithVolQuotaTotalVol(700).
-				WithVolQuotaUplinkVol(300).
-				WithVolQuotaDownlinkVol(400).
-				WithTriggers(2).
-				Build(),
+			input: ie.NewUpdateURR(newTestURRIEs()...),
 			expected: &Urr{
-				UrrID: 999,
+				UrrID:         999,
 				MeasureMethod: 2,
-				ReportOpen: true,
+				ReportOpen:    true,
 				Trigger: ReportTrigger{
 					Flags: 2,
 				},
 				LocalThreshold: 1000,
 				VolThreshold: VolumeData{
-					Flags: 7,
-					TotalVol: 1000,
-					UplinkVol: 200,
+					Flags:       7,
+					TotalVol:    1000,
+					UplinkVol:   200,
 					DownlinkVol: 800,
 				},
 				VolQuota: VolumeData{
 					// only TotalVol and UplinkVol are set
-					Flags: 3,
-					TotalVol: 700,
-					UplinkVol: 300,
+					Flags:       3,
+					TotalVol:    700,
+					UplinkVol:   300,
 					DownlinkVol: 0,
 				},
 				FseID: FSEID,
@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"sync"
+)
+
+// peerSessions is the SessionsStore of a PFCPConn: the partition of the node-wide store of the
+// PFCPNode holding the sessions established by its peer. Sessions of other peers are not
+// visible through it. With persistent sessions, the partition is also written to the store
+// of the peer, from which it is loaded.
+type peerSessions struct {
+	node *InMemoryStore
+	// persist is the persistent store of the sessions of the peer, nil if there is none
+	persist SessionsStore
+
+	mu    sync.RWMutex
+	seids map[uint64]struct{}
+}
+
+// newPeerSessions returns the partition of node holding the sessions of a peer, loaded from
+// persist if not nil.
+func newPeerSessions(node *InMemoryStore, persist SessionsStore) *peerSessions {
+	p := &peerSessions{
+		node:    node,
+		persist: persist,
+		seids:   make(map[uint64]struct{}),
+	}
+
+	if persist == nil {
+		return p
+	}
+
+	for _, session := range persist.GetAllSessions() {
+		if _, ok := node.GetSession(session.localSEID); ok {
+			log.Warnf("Session %v is held by another PFCP peer, not loading it", session.localSEID)
+			continue
+		}
+
+		if err := node.PutSession(session); err != nil {
+			log.Errorf("Failed to load PFCP session %v: %v", session.localSEID, err)
+			continue
+		}
+
+		p.seids[session.localSEID] = struct{}{}
+	}
+
+	return p
+}
+
+func (p *peerSessions) owns(seid uint64) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	_, ok := p.seids[seid]
+
+	return ok
+}
+
+func (p *peerSessions) PutSession(session PFCPSession) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.seids[session.localSEID]; !ok {
+		if _, ok := p.node.GetSession(session.localSEID); ok {
+			return ErrInvalidArgumentWithReason("session.localSEID", session.localSEID,
+				"held by another PFCP peer")
+		}
+	}
+
+	if p.persist != nil {
+		if err := p.persist.PutSession(session); err != nil {
+			return err
+		}
+	}
+
+	if err := p.node.PutSession(session); err != nil {
+		return err
+	}
+
+	p.seids[session.localSEID] = struct{}{}
+
+	return nil
+}
+
+func (p *peerSessions) GetSession(fseid uint64) (PFCPSession, bool) {
+	if !p.owns(fseid) {
+		return PFCPSession{}, false
+	}
+
+	return p.node.GetSession(fseid)
+}
+
+// filter returns session if it belongs to the partition.
+func (p *peerSessions) filter(session PFCPSession, ok bool) (PFCPSession, bool) {
+	if !ok || !p.owns(session.localSEID) {
+		return PFCPSession{}, false
+	}
+
+	return session, true
+}
+
+func (p *peerSessions) GetSessionByUEAddress(ueAddress net.IP) (PFCPSession, bool) {
+	return p.filter(p.node.GetSessionByUEAddress(ueAddress))
+}
+
+func (p *peerSessions) GetSessionByFTEID(teid uint32, ip net.IP) (PFCPSession, bool) {
+	return p.filter(p.node.GetSessionByFTEID(teid, ip))
+}
+
+func (p *peerSessions) GetSessionByRemoteFSEID(seid uint64, ip net.IP) (PFCPSession, bool) {
+	return p.filter(p.node.GetSessionByRemoteFSEID(seid, ip))
+}
+
+func (p *peerSessions) GetAllSessions() []PFCPSession {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	sessions := make([]PFCPSession, 0, len(p.seids))
+
+	for seid := range p.seids {
+		if session, ok := p.node.GetSession(seid); ok {
+			sessions = append(sessions, session)
+		}
+	}
+
+	return sessions
+}
+
+func (p *peerSessions) DeleteSession(fseid uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.seids[fseid]; !ok {
+		return nil
+	}
+
+	if p.persist != nil {
+		if err := p.persist.DeleteSession(fseid); err != nil {
+			return err
+		}
+	}
+
+	delete(p.seids, fseid)
+
+	return p.node.DeleteSession(fseid)
+}
+
+func (p *peerSessions) DeleteAllSessions() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.persist != nil && !p.persist.DeleteAllSessions() {
+		return false
+	}
+
+	p.forget()
+
+	return true
+}
+
+// forget removes the sessions of the partition from the node-wide store only. Must be called
+// with mu held.
+func (p *peerSessions) forget() {
+	for seid := range p.seids {
+		if err := p.node.DeleteSession(seid); err != nil {
+			log.Errorf("Failed to delete PFCP session from store: %v", err)
+		}
+
+		delete(p.seids, seid)
+	}
+}
+
+// Close releases the partition: the sessions left in it are removed from the node-wide store,
+// but are kept in the persistent store of the peer.
+func (p *peerSessions) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.forget()
+
+	if fs, ok := p.persist.(*FileStore); ok {
+		return fs.Close()
+	}
+
+	return nil
+}
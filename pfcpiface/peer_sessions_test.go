@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerSessions(t *testing.T) {
+	node := NewInMemoryStore()
+	peer1 := newPeerSessions(node, nil)
+	peer2 := newPeerSessions(node, nil)
+
+	s1 := newIndexedTestSession(1, "10.250.0.1", 0x10)
+	s2 := newIndexedTestSession(2, "10.250.0.2", 0x20)
+
+	require.NoError(t, peer1.PutSession(s1))
+	require.NoError(t, peer2.PutSession(s2))
+	require.Error(t, peer2.PutSession(s1), "SEIDs are unique across peers")
+
+	// Sessions of a peer are found node-wide, but not through other peers
+	require.Len(t, node.GetAllSessions(), 2)
+
+	got, ok := peer1.GetSession(1)
+	require.True(t, ok)
+	require.Equal(t, s1, got)
+
+	_, ok = peer1.GetSession(2)
+	require.False(t, ok)
+
+	_, ok = peer1.GetSessionByUEAddress(net.ParseIP("10.250.0.2"))
+	require.False(t, ok)
+
+	require.Equal(t, []PFCPSession{s2}, peer2.GetAllSessions())
+
+	require.NoError(t, peer1.DeleteSession(2), "sessions of other peers are not deleted")
+	_, ok = node.GetSession(2)
+	require.True(t, ok)
+
+	require.True(t, peer2.DeleteAllSessions())
+	require.Equal(t, []PFCPSession{s1}, node.GetAllSessions())
+}
+
+func TestPeerSessions_Persistent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions-test.log")
+
+	persist, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	node := NewInMemoryStore()
+	peer := newPeerSessions(node, persist)
+
+	s1 := newIndexedTestSession(1, "10.250.0.1", 0x10)
+	require.NoError(t, peer.PutSession(s1))
+
+	// Closing the partition keeps the persisted sessions of the peer
+	require.NoError(t, peer.Close())
+	require.Empty(t, node.GetAllSessions())
+
+	persist, err = NewFileStore(path)
+	require.NoError(t, err)
+
+	peer = newPeerSessions(node, persist)
+	require.Equal(t, []PFCPSession{s1}, peer.GetAllSessions())
+	require.Equal(t, []PFCPSession{s1}, node.GetAllSessions())
+
+	require.NoError(t, peer.Close())
+}
+
+func TestSEIDRanges(t *testing.T) {
+	ranges := newSEIDRanges()
+
+	r1, err := ranges.allocate()
+	require.NoError(t, err)
+
+	r2, err := ranges.allocate()
+	require.NoError(t, err)
+	require.NotEqual(t, r1, r2)
+
+	seid := r1.seid(0)
+	require.NotZero(t, seid)
+	require.True(t, r1.contains(seid))
+	require.False(t, r2.contains(seid))
+	require.True(t, r1.contains(r1.seid(^uint64(0))))
+
+	ranges.release(r1)
+
+	r3, err := ranges.allocate()
+	require.NoError(t, err)
+	require.NotEqual(t, r2, r3)
+}
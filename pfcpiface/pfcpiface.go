@@ -37,6 +37,9 @@ type PFCPIface struct {
 }
 
 func NewPFCPIface(conf Conf, dp Datapath) *PFCPIface {
+	// The logger is used by the datapath and the UPF during their setup.
+	Zap_init()
+
 	pfcpIface := &PFCPIface{
 		conf: conf,
 	}
@@ -52,8 +55,6 @@ func NewPFCPIface(conf Conf, dp Datapath) *PFCPIface {
 
 	pfcpIface.Upf = NewUPF(&conf, pfcpIface.Dp)
 
-	Zap_init()
-
 	return pfcpIface
 }
 
@@ -65,6 +66,8 @@ func (p *PFCPIface) mustInit() {
 	httpMux := http.NewServeMux()
 
 	setupConfigHandler(httpMux, p.Upf)
+	setupDrainHandler(httpMux, p.node)
+	setupUEPoolHandler(httpMux, p.Upf, p.node)
 
 	var err error
 
@@ -86,6 +89,11 @@ func (p *PFCPIface) Run() {
 		}
 	}
 
+	// Sessions are recovered before serving, so that none of their TEIDs and UE IPs is handed out.
+	if err := p.Upf.recoverSessions(); err != nil {
+		log.Fatal("session recovery failed ", err)
+	}
+
 	p.mustInit()
 
 	go func() {
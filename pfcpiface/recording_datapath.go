@@ -0,0 +1,277 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wmnsk/go-pfcp/ie"
+)
+
+func init() {
+	mustRegisterDatapath("recording", func(conf *Conf) (Datapath, error) {
+		return NewRecordingDatapath(), nil
+	})
+}
+
+// RecordedCall is a rule operation captured by RecordingDatapath.
+type RecordedCall struct {
+	Method   UpfMsgType
+	RuleType RuleType
+	SEID     uint64
+	RuleID   uint32
+	// Rule is the Pdr, Far, Qer or Urr passed to the datapath.
+	Rule interface{}
+}
+
+type recordedRuleKey struct {
+	ruleType RuleType
+	seid     uint64
+	id       uint32
+}
+
+type recordedFault struct {
+	method   UpfMsgType
+	ruleType RuleType
+}
+
+// RecordingDatapath records every rule operation instead of programming a dataplane, and keeps
+// track of the rules that would be installed, so that tests can assert on what the PFCP agent
+// pushed. Like a real dataplane, it rejects creating an existing rule and modifying or deleting
+// a missing one.
+type RecordingDatapath struct {
+	mu             sync.Mutex
+	calls          []RecordedCall
+	rules          map[recordedRuleKey]interface{}
+	injectedErrors map[recordedFault]uint8
+	usage          map[recordedRuleKey]UsageCounters
+	connected      bool
+}
+
+func NewRecordingDatapath() *RecordingDatapath {
+	return &RecordingDatapath{
+		rules:          make(map[recordedRuleKey]interface{}),
+		injectedErrors: make(map[recordedFault]uint8),
+		usage:          make(map[recordedRuleKey]UsageCounters),
+		connected:      true,
+	}
+}
+
+// Calls returns a copy of the calls recorded so far.
+func (r *RecordingDatapath) Calls() []RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]RecordedCall{}, r.calls...)
+}
+
+// Reset forgets all recorded calls. Installed rules are kept.
+func (r *RecordingDatapath) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
+
+// Rules returns the rules currently installed, ordered by SEID and rule ID.
+func (r *RecordingDatapath) Rules() PacketForwardingRules {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]recordedRuleKey, 0, len(r.rules))
+	for k := range r.rules {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].seid != keys[j].seid {
+			return keys[i].seid < keys[j].seid
+		}
+
+		return keys[i].id < keys[j].id
+	})
+
+	var rules PacketForwardingRules
+
+	for _, k := range keys {
+		switch rule := r.rules[k].(type) {
+		case Pdr:
+			rules.Pdrs = append(rules.Pdrs, rule)
+		case Far:
+			rules.Fars = append(rules.Fars, rule)
+		case Qer:
+			rules.Qers = append(rules.Qers, rule)
+		case Urr:
+			rules.Urrs = append(rules.Urrs, rule)
+		}
+	}
+
+	return rules
+}
+
+// InjectError makes the next operation of the given method on a rule of the given type fail
+// with cause.
+func (r *RecordingDatapath) InjectError(method UpfMsgType, ruleType RuleType, cause uint8) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.injectedErrors[recordedFault{method: method, ruleType: ruleType}] = cause
+}
+
+// SetConnected sets the value returned by IsConnected.
+func (r *RecordingDatapath) SetConnected(connected bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.connected = connected
+}
+
+// SetUsage sets the counters returned by ReadUsage for URR urrID of session seid.
+func (r *RecordingDatapath) SetUsage(seid uint64, urrID uint32, counters UsageCounters) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.usage[recordedRuleKey{ruleType: RuleTypeURR, seid: seid, id: urrID}] = counters
+}
+
+// ReadUsage returns the counters set with SetUsage for an installed URR.
+func (r *RecordingDatapath) ReadUsage(ctx context.Context, urr Urr) (UsageCounters, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := recordedRuleKey{ruleType: RuleTypeURR, seid: urr.FseID, id: urr.UrrID}
+	if _, ok := r.rules[key]; !ok {
+		return UsageCounters{}, &DatapathError{Cause: ie.CauseSessionContextNotFound, Err: ErrNotFound("URR")}
+	}
+
+	return r.usage[key], nil
+}
+
+func (r *RecordingDatapath) record(method UpfMsgType, ruleType RuleType, seid uint64, id uint32,
+	rule interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, RecordedCall{
+		Method:   method,
+		RuleType: ruleType,
+		SEID:     seid,
+		RuleID:   id,
+		Rule:     rule,
+	})
+
+	fault := recordedFault{method: method, ruleType: ruleType}
+	if cause, ok := r.injectedErrors[fault]; ok {
+		delete(r.injectedErrors, fault)
+		return &DatapathError{Cause: cause, Err: ErrOperationFailedWithParam(method, "rule", ruleType)}
+	}
+
+	key := recordedRuleKey{ruleType: ruleType, seid: seid, id: id}
+	_, exists := r.rules[key]
+
+	switch method {
+	case UpfMsgTypeAdd:
+		if exists {
+			return &DatapathError{
+				Cause: ie.CauseRuleCreationModificationFailure,
+				Err:   ErrInvalidArgumentWithReason(ruleType.String(), id, "rule already exists"),
+			}
+		}
+
+		r.rules[key] = rule
+	case UpfMsgTypeMod:
+		if !exists {
+			return &DatapathError{Cause: ie.CauseSessionContextNotFound, Err: ErrNotFound(ruleType.String())}
+		}
+
+		r.rules[key] = rule
+	case UpfMsgTypeDel:
+		if !exists {
+			return &DatapathError{Cause: ie.CauseSessionContextNotFound, Err: ErrNotFound(ruleType.String())}
+		}
+
+		delete(r.rules, key)
+	}
+
+	return nil
+}
+
+func (r *RecordingDatapath) Exit() {}
+
+func (r *RecordingDatapath) SetUpfInfo(u *Upf, conf *Conf) {}
+
+func (r *RecordingDatapath) AddSliceInfo(sliceInfo *SliceInfo) error {
+	return nil
+}
+
+func (r *RecordingDatapath) SendEndMarkers(endMarkerList *[]EndMarker) error {
+	return nil
+}
+
+func (r *RecordingDatapath) CreatePDR(ctx context.Context, pdr Pdr) error {
+	return r.record(UpfMsgTypeAdd, RuleTypePDR, pdr.FseID, pdr.PdrID, pdr)
+}
+
+func (r *RecordingDatapath) ModifyPDR(ctx context.Context, pdr Pdr) error {
+	return r.record(UpfMsgTypeMod, RuleTypePDR, pdr.FseID, pdr.PdrID, pdr)
+}
+
+func (r *RecordingDatapath) DeletePDR(ctx context.Context, pdr Pdr) error {
+	return r.record(UpfMsgTypeDel, RuleTypePDR, pdr.FseID, pdr.PdrID, pdr)
+}
+
+func (r *RecordingDatapath) CreateFAR(ctx context.Context, far Far) error {
+	return r.record(UpfMsgTypeAdd, RuleTypeFAR, far.FseID, far.FarID, far)
+}
+
+func (r *RecordingDatapath) ModifyFAR(ctx context.Context, far Far) error {
+	return r.record(UpfMsgTypeMod, RuleTypeFAR, far.FseID, far.FarID, far)
+}
+
+func (r *RecordingDatapath) DeleteFAR(ctx context.Context, far Far) error {
+	return r.record(UpfMsgTypeDel, RuleTypeFAR, far.FseID, far.FarID, far)
+}
+
+func (r *RecordingDatapath) CreateQER(ctx context.Context, qer Qer) error {
+	return r.record(UpfMsgTypeAdd, RuleTypeQER, qer.FseID, qer.QerID, qer)
+}
+
+func (r *RecordingDatapath) ModifyQER(ctx context.Context, qer Qer) error {
+	return r.record(UpfMsgTypeMod, RuleTypeQER, qer.FseID, qer.QerID, qer)
+}
+
+func (r *RecordingDatapath) DeleteQER(ctx context.Context, qer Qer) error {
+	return r.record(UpfMsgTypeDel, RuleTypeQER, qer.FseID, qer.QerID, qer)
+}
+
+func (r *RecordingDatapath) CreateURR(ctx context.Context, urr Urr) error {
+	return r.record(UpfMsgTypeAdd, RuleTypeURR, urr.FseID, urr.UrrID, urr)
+}
+
+func (r *RecordingDatapath) ModifyURR(ctx context.Context, urr Urr) error {
+	return r.record(UpfMsgTypeMod, RuleTypeURR, urr.FseID, urr.UrrID, urr)
+}
+
+func (r *RecordingDatapath) DeleteURR(ctx context.Context, urr Urr) error {
+	return r.record(UpfMsgTypeDel, RuleTypeURR, urr.FseID, urr.UrrID, urr)
+}
+
+func (r *RecordingDatapath) IsConnected(accessIP *net.IP) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.connected
+}
+
+func (r *RecordingDatapath) PortStats(uc *UpfCollector, ch chan<- prometheus.Metric) {}
+
+func (r *RecordingDatapath) SessionStats(pc *PfcpNodeCollector, ch chan<- prometheus.Metric) error {
+	return nil
+}
+
+func (r *RecordingDatapath) SummaryLatencyJitter(uc *UpfCollector, ch chan<- prometheus.Metric) {}
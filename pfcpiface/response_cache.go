@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-pfcp/message"
+)
+
+// responseKey identifies a request of the peer.
+type responseKey struct {
+	seq     uint32
+	msgType uint8
+}
+
+type cachedResponse struct {
+	key     responseKey
+	reply   message.Message
+	expires time.Time
+}
+
+// responseCache keeps the responses sent to the recent requests of a peer, so that the requests
+// it retransmits because a response was lost are answered again without being processed twice.
+type responseCache struct {
+	mu  sync.Mutex
+	ttl time.Duration
+	// entries are ordered by expiry, as all of them live for ttl
+	entries []*cachedResponse
+	byKey   map[responseKey]*cachedResponse
+}
+
+// newResponseCache returns a cache keeping responses for ttl. Nothing is cached if ttl is zero.
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:   ttl,
+		byKey: make(map[responseKey]*cachedResponse),
+	}
+}
+
+// responseCacheTTL returns how long a peer may retransmit a request, assuming it uses the same
+// T1 and N1 timers as the UPF: the first transmission and N1 retransmissions, T1 apart.
+func (u *Upf) responseCacheTTL() time.Duration {
+	return time.Duration(u.maxReqRetries+1) * u.respTimeout
+}
+
+func newResponseKey(req message.Message) responseKey {
+	return responseKey{seq: req.Sequence(), msgType: req.MessageType()}
+}
+
+// expire drops the entries expired at now. Must be called with mu held.
+func (c *responseCache) expire(now time.Time) {
+	n := 0
+	for ; n < len(c.entries) && !now.Before(c.entries[n].expires); n++ {
+		if c.byKey[c.entries[n].key] == c.entries[n] {
+			delete(c.byKey, c.entries[n].key)
+		}
+
+		c.entries[n] = nil
+	}
+
+	c.entries = c.entries[n:]
+}
+
+// get returns the response sent to req, if req is a retransmission of a recent request.
+func (c *responseCache) get(req message.Message, now time.Time) (message.Message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(now)
+
+	entry, ok := c.byKey[newResponseKey(req)]
+	if !ok {
+		return nil, false
+	}
+
+	return entry.reply, true
+}
+
+// put records reply as the response sent to req.
+func (c *responseCache) put(req, reply message.Message, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(now)
+
+	entry := &cachedResponse{
+		key:     newResponseKey(req),
+		reply:   reply,
+		expires: now.Add(c.ttl),
+	}
+
+	c.entries = append(c.entries, entry)
+	c.byKey[entry.key] = entry
+}
+
+// len returns the number of responses cached.
+func (c *responseCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.byKey)
+}
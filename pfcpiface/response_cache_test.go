@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+	"github.com/wmnsk/go-pfcp/message"
+)
+
+type retransmissionCountingInstrumentPFCP struct {
+	noopInstrumentPFCP
+	retransmittedRequests map[string]int
+}
+
+func (c *retransmissionCountingInstrumentPFCP) SaveRetransmittedRequest(nodeID, msgType string) {
+	c.retransmittedRequests[msgType]++
+}
+
+func TestResponseCache(t *testing.T) {
+	c := newResponseCache(3 * time.Second)
+	now := time.Now()
+
+	req := message.NewHeartbeatRequest(7, ie.NewRecoveryTimeStamp(now), nil)
+	reply := message.NewHeartbeatResponse(7, ie.NewRecoveryTimeStamp(now))
+
+	_, ok := c.get(req, now)
+	require.False(t, ok)
+
+	c.put(req, reply, now)
+
+	cached, ok := c.get(req, now.Add(time.Second))
+	require.True(t, ok)
+	require.Equal(t, reply, cached)
+
+	// Requests of another type or sequence number are not retransmissions
+	_, ok = c.get(message.NewHeartbeatRequest(8, ie.NewRecoveryTimeStamp(now), nil), now)
+	require.False(t, ok)
+	_, ok = c.get(message.NewAssociationSetupRequest(7), now)
+	require.False(t, ok)
+
+	c.put(message.NewHeartbeatRequest(8, nil, nil), message.NewHeartbeatResponse(8, nil), now.Add(2*time.Second))
+	require.Equal(t, 2, c.len())
+
+	_, ok = c.get(req, now.Add(3*time.Second))
+	require.False(t, ok)
+	require.Equal(t, 1, c.len())
+
+	// Nothing is cached without a TTL
+	c = newResponseCache(0)
+	c.put(req, reply, now)
+	_, ok = c.get(req, now)
+	require.False(t, ok)
+}
+
+func TestPFCPConn_RetransmittedRequest(t *testing.T) {
+	smf, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = smf.Close() })
+
+	conn, err := net.Dial("udp", smf.LocalAddr().String())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	upf := newTestUpf(NewRecordingDatapath())
+	upf.respTimeout = time.Second
+	upf.maxReqRetries = 2
+
+	instrument := &retransmissionCountingInstrumentPFCP{retransmittedRequests: make(map[string]int)}
+
+	pConn := newTestPFCPConnWithUpf(t, upf)
+	pConn.Conn = conn
+	pConn.InstrumentPFCP = instrument
+
+	req := newTestEstablishmentRequest(pConn)
+	req.SetSequenceNumber(42)
+
+	buf := make([]byte, req.MarshalLen())
+	require.NoError(t, req.MarshalTo(buf))
+
+	pConn.HandlePFCPMsg(buf)
+
+	first := readPFCPMessage(t, smf)
+	require.NotNil(t, first)
+	require.Equal(t, message.MsgTypeSessionEstablishmentResponse, first.MessageType())
+
+	// The retransmission is answered with the same response, without a second session
+	pConn.HandlePFCPMsg(buf)
+
+	second := readPFCPMessage(t, smf)
+	require.NotNil(t, second)
+	require.Equal(t, first, second)
+
+	require.Len(t, pConn.store.GetAllSessions(), 1)
+	require.Equal(t, 1, instrument.retransmittedRequests[req.MessageTypeName()])
+
+	// A new request with another sequence number is processed
+	req.SetSequenceNumber(43)
+	require.NoError(t, req.MarshalTo(buf))
+
+	pConn.HandlePFCPMsg(buf)
+
+	third := readPFCPMessage(t, smf)
+	require.NotNil(t, third)
+	require.Equal(t, uint32(43), third.Sequence())
+	require.Equal(t, 1, instrument.retransmittedRequests[req.MessageTypeName()])
+}
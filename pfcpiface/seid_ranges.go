@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import "math"
+
+// seidRangeBits is the number of high bits of a local SEID identifying its range.
+const seidRangeBits = 16
+
+const seidRangeMask = uint64(math.MaxUint64) >> seidRangeBits
+
+// seidRange is a partition of the local SEID space, from which a single PFCPConn allocates
+// the SEIDs of its sessions.
+type seidRange struct {
+	id uint32
+}
+
+// seid returns the SEID of the range whose low bits are those of n. It is never 0.
+func (r seidRange) seid(n uint64) uint64 {
+	return uint64(r.id)<<(64-seidRangeBits) | n&seidRangeMask
+}
+
+// contains checks if seid belongs to the range.
+func (r seidRange) contains(seid uint64) bool {
+	return uint32(seid>>(64-seidRangeBits)) == r.id
+}
+
+// seidRanges hands out non-overlapping SEID ranges to the PFCPConns of a PFCPNode, so that
+// the local SEIDs of all its associations are unique.
+type seidRanges struct {
+	ids *IDAllocator
+}
+
+func newSEIDRanges() *seidRanges {
+	// Range 0 is left out, so that no SEID of a range is 0
+	return &seidRanges{ids: NewIDAllocator(1, 1<<seidRangeBits-1)}
+}
+
+// allocate returns a range not used by any other PFCPConn.
+func (r *seidRanges) allocate() (seidRange, error) {
+	id, err := r.ids.Allocate()
+	if err != nil {
+		return seidRange{}, ErrOperationFailedWithReason("SEID range allocation", err.Error())
+	}
+
+	return seidRange{id: id}, nil
+}
+
+// release returns rng to the ranges available.
+func (r *seidRanges) release(rng seidRange) {
+	r.ids.Free(rng.id)
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"github.com/wmnsk/go-pfcp/message"
+
+	"github.com/ardzoht/omec-upf/pfcpiface/metrics"
+)
+
+// outboundQueueLen is the number of messages queued for a peer before senders wait.
+const outboundQueueLen = 256
+
+type outboundMsg struct {
+	msgType string
+	buf     []byte
+	m       *metrics.Message
+}
+
+// outbound is the queue of the messages to send to the peer of a PFCPConn, all written by a
+// single sender goroutine, so that a slow peer blocks none of the goroutines producing them.
+type outbound struct {
+	queue chan *outboundMsg
+	// done is closed once the sender has flushed the queue on shutdown
+	done chan struct{}
+}
+
+func newOutbound() *outbound {
+	return &outbound{
+		queue: make(chan *outboundMsg, outboundQueueLen),
+		done:  make(chan struct{}),
+	}
+}
+
+// depth returns the number of messages waiting to be sent.
+func (o *outbound) depth() int {
+	return len(o.queue)
+}
+
+// SendPFCPMsg sends msg to the peer. It waits for room in the outbound queue, which applies
+// back-pressure on the handlers of a peer which does not keep up with its responses.
+func (pConn *PFCPConn) SendPFCPMsg(msg message.Message) {
+	pConn.sendPFCPMsg(msg, true)
+}
+
+// trySendPFCPMsg sends msg to the peer unless its outbound queue is full, in which case msg is
+// dropped. It is meant for callers which must never block, like the event loop of the PFCPNode.
+func (pConn *PFCPConn) trySendPFCPMsg(msg message.Message) bool {
+	return pConn.sendPFCPMsg(msg, false)
+}
+
+func (pConn *PFCPConn) sendPFCPMsg(msg message.Message, wait bool) bool {
+	addr := pConn.RemoteAddr().String()
+	nodeID := pConn.nodeID.remote
+	msgType := msg.MessageTypeName()
+
+	m := metrics.NewMessage(msgType, "Outgoing")
+
+	out := make([]byte, msg.MarshalLen())
+
+	if err := msg.MarshalTo(out); err != nil {
+		m.Finish(nodeID, "Failure")
+		pConn.SaveMessages(m)
+		log.Errorf("Failed to marshal %v for %v %v", msgType, addr, err)
+
+		return false
+	}
+
+	o := &outboundMsg{msgType: msgType, buf: out, m: m}
+
+	// Without a sender, e.g. before the PFCPConn is served, messages are written inline
+	if pConn.outbound == nil {
+		pConn.write(o)
+		return true
+	}
+
+	if !wait {
+		select {
+		case pConn.outbound.queue <- o:
+			return true
+		default:
+			m.Finish(nodeID, "Dropped")
+			pConn.SaveMessages(m)
+			log.Warnf("Outbound queue to %v full, dropping %v", addr, msgType)
+
+			return false
+		}
+	}
+
+	select {
+	case pConn.outbound.queue <- o:
+		return true
+	case <-pConn.shutdown:
+		m.Finish(nodeID, "Failure")
+		pConn.SaveMessages(m)
+		log.Warnf("Not sending %v to %v, connection is shut down", msgType, addr)
+
+		return false
+	}
+}
+
+// write transmits o to the peer.
+func (pConn *PFCPConn) write(o *outboundMsg) {
+	addr := pConn.RemoteAddr().String()
+	nodeID := pConn.nodeID.remote
+
+	defer pConn.SaveMessages(o.m)
+
+	if _, err := pConn.Write(o.buf); err != nil {
+		o.m.Finish(nodeID, "Failure")
+		log.Errorf("Failed to transmit %v to %v %v", o.msgType, addr, err)
+
+		return
+	}
+
+	o.m.Finish(nodeID, "Success")
+	log.Debugf("Sent %v to %v", o.msgType, addr)
+}
+
+// sendLoop writes the queued messages until pConn is shut down, and then the messages left in
+// the queue, like the response to an Association Release Request.
+func (pConn *PFCPConn) sendLoop() {
+	defer close(pConn.outbound.done)
+
+	for {
+		select {
+		case o := <-pConn.outbound.queue:
+			pConn.write(o)
+		case <-pConn.shutdown:
+			for {
+				select {
+				case o := <-pConn.outbound.queue:
+					pConn.write(o)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/message"
+
+	"github.com/ardzoht/omec-upf/pfcpiface/metrics"
+)
+
+type resultCountingInstrumentPFCP struct {
+	noopInstrumentPFCP
+	results chan string
+}
+
+func (c *resultCountingInstrumentPFCP) SaveMessages(m *metrics.Message) {
+	c.results <- m.Result
+}
+
+// newTestSender returns a PFCPConn with an outbound queue whose messages are received on the
+// returned socket. Its sender goroutine is not started.
+func newTestSender(t *testing.T) (*PFCPConn, net.PacketConn) {
+	smf, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = smf.Close() })
+
+	conn, err := net.Dial("udp", smf.LocalAddr().String())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	pConn := newTestPFCPConn(t, NewRecordingDatapath())
+	pConn.Conn = conn
+	pConn.outbound = newOutbound()
+	pConn.shutdown = make(chan struct{})
+
+	return pConn, smf
+}
+
+func TestPFCPConn_SendLoop(t *testing.T) {
+	pConn, smf := newTestSender(t)
+
+	go pConn.sendLoop()
+
+	for seq := uint32(1); seq <= 3; seq++ {
+		pConn.SendPFCPMsg(message.NewHeartbeatRequest(seq, nil, nil))
+	}
+
+	for seq := uint32(1); seq <= 3; seq++ {
+		msg := readPFCPMessage(t, smf)
+		require.NotNil(t, msg)
+		require.Equal(t, seq, msg.Sequence())
+	}
+
+	close(pConn.shutdown)
+	<-pConn.outbound.done
+}
+
+func TestPFCPConn_SendLoopFlushesOnShutdown(t *testing.T) {
+	pConn, smf := newTestSender(t)
+
+	pConn.SendPFCPMsg(message.NewAssociationReleaseResponse(1, nil, nil))
+	close(pConn.shutdown)
+
+	pConn.sendLoop()
+
+	msg := readPFCPMessage(t, smf)
+	require.NotNil(t, msg)
+	require.Equal(t, message.MsgTypeAssociationReleaseResponse, msg.MessageType())
+
+	select {
+	case <-pConn.outbound.done:
+	default:
+		require.Fail(t, "sender not done")
+	}
+}
+
+func TestPFCPConn_OutboundQueueFull(t *testing.T) {
+	pConn, _ := newTestSender(t)
+
+	instrument := &resultCountingInstrumentPFCP{results: make(chan string, 1)}
+	pConn.InstrumentPFCP = instrument
+
+	for i := 0; i < outboundQueueLen; i++ {
+		require.True(t, pConn.trySendPFCPMsg(message.NewHeartbeatRequest(uint32(i), nil, nil)))
+	}
+
+	require.Equal(t, outboundQueueLen, pConn.outbound.depth())
+
+	// Callers which must not block drop the message
+	require.False(t, pConn.trySendPFCPMsg(message.NewHeartbeatRequest(0, nil, nil)))
+	require.Equal(t, "Dropped", <-instrument.results)
+
+	// Others wait for room in the queue, until the connection is shut down
+	sent := make(chan bool)
+
+	go func() {
+		sent <- pConn.sendPFCPMsg(message.NewHeartbeatRequest(0, nil, nil), true)
+	}()
+
+	select {
+	case <-sent:
+		require.Fail(t, "message queued in a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(pConn.shutdown)
+	require.False(t, <-sent)
+	require.Equal(t, "Failure", <-instrument.results)
+}
@@ -27,6 +27,11 @@ func addEndMarkerForGtp(farItem Far, endMarkerList *[]EndMarker) {
 		PeerIP:   int2ip(farItem.TunnelIP4Dst),
 		PeerPort: farItem.TunnelPort,
 	}
+
+	if farItem.IsIPv6() {
+		newEndMarker.PeerIP = farItem.TunnelIP6Dst
+	}
+
 	*endMarkerList = append(*endMarkerList, newEndMarker)
 }
 
@@ -101,6 +106,17 @@ func (s *PFCPSession) UpdateFAR(f *Far, endMarkerList *[]EndMarker) error {
 	return ErrNotFound("FAR")
 }
 
+// findFAR returns the FAR with the given ID, or nil if not found.
+func (s *PFCPSession) findFAR(id uint32) *Far {
+	for idx := range s.Fars {
+		if s.Fars[idx].FarID == id {
+			return &s.Fars[idx]
+		}
+	}
+
+	return nil
+}
+
 // RemoveFAR removes far from existing list of FARs in the session.
 func (s *PFCPSession) RemoveFAR(id uint32) (*Far, error) {
 	for idx, v := range s.Fars {
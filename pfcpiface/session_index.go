@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+)
+
+type fteidKey struct {
+	teid uint32
+	ip   string
+}
+
+type fseidKey struct {
+	seid uint64
+	ip   string
+}
+
+// sessionIndexes maps secondary keys of PFCP sessions to their local SEID: UE addresses and
+// IPv6 prefixes, the F-TEIDs of their PDRs and their remote (CP) F-SEID. It is not safe for
+// concurrent use, stores guard it with the lock serializing their writes.
+type sessionIndexes struct {
+	ueAddresses  map[string]uint64
+	fteids       map[fteidKey]uint64
+	remoteFSEIDs map[fseidKey]uint64
+	// prefixLens6 counts the UE IPv6 prefixes indexed by prefix length
+	prefixLens6 map[uint8]int
+}
+
+func newSessionIndexes() *sessionIndexes {
+	return &sessionIndexes{
+		ueAddresses:  make(map[string]uint64),
+		fteids:       make(map[fteidKey]uint64),
+		remoteFSEIDs: make(map[fseidKey]uint64),
+		prefixLens6:  make(map[uint8]int),
+	}
+}
+
+// ipKey returns a map key for ip, identical for the 4 and 16 bytes forms of an IPv4 address.
+func ipKey(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return string(ip4)
+	}
+
+	return string(ip.To16())
+}
+
+// prefixKey returns the map key of the IPv6 prefix of ip of length prefixLen. It is longer than
+// the key of any address, and ends with the prefix length.
+func prefixKey(ip net.IP, prefixLen uint8) string {
+	prefix := ip.Mask(net.CIDRMask(int(prefixLen), 8*net.IPv6len))
+
+	return string(append(prefix, prefixLen))
+}
+
+// prefixLenOf returns the prefix length of key, false if key is not a prefix key.
+func prefixLenOf(key string) (uint8, bool) {
+	if len(key) != net.IPv6len+1 {
+		return 0, false
+	}
+
+	return key[net.IPv6len], true
+}
+
+func ueAddressesOf(session *PFCPSession) []string {
+	addrs := make([]string, 0, len(session.Pdrs)+1)
+
+	if session.UeAddress != 0 {
+		addrs = append(addrs, ipKey(int2ip(session.UeAddress)))
+	}
+
+	// The IPv6 prefix of the session is the one of its downlink PDRs
+	for _, pdr := range session.Pdrs {
+		if pdr.UeAddress != 0 {
+			addrs = append(addrs, ipKey(int2ip(pdr.UeAddress)))
+		}
+
+		if pdr.UeAddress6 != nil {
+			addrs = append(addrs, prefixKey(pdr.UeAddress6, pdr.UeAddress6PrefixLen))
+		}
+	}
+
+	return addrs
+}
+
+func fteidsOf(session *PFCPSession) []fteidKey {
+	fteids := make([]fteidKey, 0, len(session.Pdrs))
+
+	for _, pdr := range session.Pdrs {
+		if pdr.TunnelTEID == 0 {
+			continue
+		}
+
+		if pdr.TunnelIP4Dst != 0 || pdr.TunnelIP6Dst == nil {
+			fteids = append(fteids, fteidKey{teid: pdr.TunnelTEID, ip: ipKey(int2ip(pdr.TunnelIP4Dst))})
+		}
+
+		if pdr.TunnelIP6Dst != nil {
+			fteids = append(fteids, fteidKey{teid: pdr.TunnelTEID, ip: ipKey(pdr.TunnelIP6Dst)})
+		}
+	}
+
+	return fteids
+}
+
+func (x *sessionIndexes) addUEAddress(addr string, seid uint64) {
+	if _, ok := x.ueAddresses[addr]; !ok {
+		if prefixLen, ok := prefixLenOf(addr); ok {
+			x.prefixLens6[prefixLen]++
+		}
+	}
+
+	x.ueAddresses[addr] = seid
+}
+
+func (x *sessionIndexes) removeUEAddress(addr string) {
+	if prefixLen, ok := prefixLenOf(addr); ok {
+		x.prefixLens6[prefixLen]--
+		if x.prefixLens6[prefixLen] == 0 {
+			delete(x.prefixLens6, prefixLen)
+		}
+	}
+
+	delete(x.ueAddresses, addr)
+}
+
+func (x *sessionIndexes) add(session *PFCPSession) {
+	seid := session.localSEID
+
+	for _, addr := range ueAddressesOf(session) {
+		x.addUEAddress(addr, seid)
+	}
+
+	for _, fteid := range fteidsOf(session) {
+		x.fteids[fteid] = seid
+	}
+
+	if session.remoteSEID != 0 {
+		x.remoteFSEIDs[fseidKey{seid: session.remoteSEID, ip: ipKey(session.remoteIP)}] = seid
+	}
+}
+
+// remove removes the keys of session, unless they were taken over by another session.
+func (x *sessionIndexes) remove(session *PFCPSession) {
+	seid := session.localSEID
+
+	for _, addr := range ueAddressesOf(session) {
+		if seid2, ok := x.ueAddresses[addr]; ok && seid2 == seid {
+			x.removeUEAddress(addr)
+		}
+	}
+
+	for _, fteid := range fteidsOf(session) {
+		if x.fteids[fteid] == seid {
+			delete(x.fteids, fteid)
+		}
+	}
+
+	key := fseidKey{seid: session.remoteSEID, ip: ipKey(session.remoteIP)}
+	if x.remoteFSEIDs[key] == seid {
+		delete(x.remoteFSEIDs, key)
+	}
+}
+
+// update replaces the keys of old, if any, with the keys of session.
+func (x *sessionIndexes) update(old *PFCPSession, session *PFCPSession) {
+	if old != nil {
+		x.remove(old)
+	}
+
+	x.add(session)
+}
+
+// byUEAddress returns the session of an UE address, an IPv6 address being looked up in the
+// longest UE prefix containing it.
+func (x *sessionIndexes) byUEAddress(ueAddress net.IP) (uint64, bool) {
+	if seid, ok := x.ueAddresses[ipKey(ueAddress)]; ok || !isIPv6(ueAddress) {
+		return seid, ok
+	}
+
+	var (
+		seid    uint64
+		found   bool
+		longest uint8
+	)
+
+	for prefixLen := range x.prefixLens6 {
+		if found && prefixLen <= longest {
+			continue
+		}
+
+		if s, ok := x.ueAddresses[prefixKey(ueAddress, prefixLen)]; ok {
+			seid, found, longest = s, true, prefixLen
+		}
+	}
+
+	return seid, found
+}
+
+func (x *sessionIndexes) byFTEID(teid uint32, ip net.IP) (uint64, bool) {
+	seid, ok := x.fteids[fteidKey{teid: teid, ip: ipKey(ip)}]
+	return seid, ok
+}
+
+func (x *sessionIndexes) byRemoteFSEID(seid uint64, ip net.IP) (uint64, bool) {
+	localSEID, ok := x.remoteFSEIDs[fseidKey{seid: seid, ip: ipKey(ip)}]
+	return localSEID, ok
+}
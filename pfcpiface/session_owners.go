@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"sync"
+)
+
+// sessionOwners maps the local SEID of every session of a PFCPNode to the PFCPConn owning it.
+// It is shared by all PFCPConns of the node, so that local SEIDs are unique across peers and
+// events reported by the datapath for a session reach the peer that established it.
+type sessionOwners struct {
+	owners sync.Map // local SEID -> *PFCPConn
+}
+
+func newSessionOwners() *sessionOwners {
+	return &sessionOwners{}
+}
+
+// claim records pConn as the owner of seid. It returns false if seid is owned by another
+// PFCPConn.
+func (o *sessionOwners) claim(seid uint64, pConn *PFCPConn) bool {
+	owner, loaded := o.owners.LoadOrStore(seid, pConn)
+
+	return !loaded || owner.(*PFCPConn) == pConn
+}
+
+// release forgets the owner of seid, if it is pConn.
+func (o *sessionOwners) release(seid uint64, pConn *PFCPConn) {
+	owner, ok := o.owners.Load(seid)
+	if ok && owner.(*PFCPConn) == pConn {
+		o.owners.Delete(seid)
+	}
+}
+
+// owner returns the PFCPConn owning seid.
+func (o *sessionOwners) owner(seid uint64) (*PFCPConn, bool) {
+	owner, ok := o.owners.Load(seid)
+	if !ok {
+		return nil, false
+	}
+
+	return owner.(*PFCPConn), true
+}
@@ -11,31 +11,30 @@ import (
 )
 
 // Release allocated IPs.
-func releaseAllocatedIPs(ippool *IPPool, session *PFCPSession) error {
+func releaseAllocatedIPs(ippools *ipPools, session *PFCPSession) error {
 	log.Info("release allocated IP")
 
-	// Check if we allocated an UE IP for this session and delete it.
-	for _, pdr := range session.Pdrs {
-		if (pdr.AllocIPFlag) && (pdr.SrcIface == core) {
-			var ueIP net.IP = int2ip(pdr.UeAddress)
-
-			log.Debugf("Releasing IP %v of session %v", ueIP, session.localSEID)
+	return ippools.release(session.localSEID, session.Pdrs)
+}
 
-			return ippool.DeallocIP(session.localSEID)
-		}
-	}
+// newUEAllocations returns the PDRs of pdrs that allocated a UE address which none of the
+// PDRs of session uses yet, with only the flags of these new allocations set.
+func newUEAllocations(pdrs []Pdr, session *PFCPSession) []Pdr {
+	inUse := ueAllocations(session.Pdrs)
 
-	return nil
-}
+	allocs := make([]Pdr, 0, len(pdrs))
 
-// Release allocated teids.
-func releaseAllocatedTEIDs(generator *IDAllocator, session *PFCPSession) {
-	log.Infof("Release allocate TEIDs for session: %v", session.localSEID)
+	for _, pdr := range pdrs {
+		families := inUse[pdr.UePool]
+		pdr.AllocIPFlag = pdr.AllocIPFlag && !families[0]
+		pdr.AllocIP6Flag = pdr.AllocIP6Flag && !families[1]
 
-	for _, pdr := range session.Pdrs {
-		log.Debugf("Releasing TEID %v of session %v", pdr.TunnelTEID, session.localSEID)
-		generator.Free(pdr.TunnelTEID)
+		if pdr.AllocIPFlag || pdr.AllocIP6Flag {
+			allocs = append(allocs, pdr)
+		}
 	}
+
+	return allocs
 }
 
 func findChoosedPdr(session *PFCPSession, chooseID uint8) *Pdr {
@@ -69,28 +68,50 @@ func addPdrInfo(msg *message.SessionEstablishmentResponse,
 		createdPDR.Add(ie.NewPDRID(uint16(pdr.PdrID)))
 		needAlloc := false
 
-		if (pdr.AllocIPFlag) && (pdr.SrcIface == core) {
+		if (pdr.AllocIPFlag || pdr.AllocIP6Flag) && (pdr.SrcIface == core) {
 			needAlloc = true
 
 			var (
-				flags uint8  = 0x02
-				ueIP  net.IP = int2ip(pdr.UeAddress)
+				flags  uint8
+				ueIP   string
+				ueIPv6 string
 			)
 
-			log.Info("pdrID: %v Adding ueIP : %v", pdr.PdrID, ueIP.String())
-			createdPDR.Add(ie.NewUEIPAddress(flags, ueIP.String(), "", 0, 0))
+			if pdr.AllocIPFlag {
+				flags |= 0x02 // IPv4 flag is present
+				ueIP = int2ip(pdr.UeAddress).String()
+			}
+
+			if pdr.AllocIP6Flag {
+				flags |= 0x01 | 0x40 // IPv6 and IPv6 prefix length flags are present
+				ueIPv6 = pdr.UeAddress6.String()
+			}
+
+			log.Infof("pdrID: %v Adding ueIP : %v %v", pdr.PdrID, ueIP, ueIPv6)
+			createdPDR.Add(ie.NewUEIPAddress(flags, ueIP, ueIPv6, 0, pdr.UeAddress6PrefixLen))
 		}
 
 		if pdr.AllocTEIDFlag {
 			needAlloc = true
 
 			var (
-				flags  uint8  = 0x01 // IPv4 flag is present
-				teidIP net.IP = int2ip(pdr.TunnelIP4Dst)
+				flags    uint8
+				teidIP   net.IP
+				teidIPv6 net.IP
 			)
 
+			if pdr.TunnelIP4Dst != 0 {
+				flags |= 0x01 // IPv4 flag is present
+				teidIP = int2ip(pdr.TunnelIP4Dst)
+			}
+
+			if pdr.TunnelIP6Dst != nil {
+				flags |= 0x02 // IPv6 flag is present
+				teidIPv6 = pdr.TunnelIP6Dst
+			}
+
 			log.Info("pdrID: %v Adding TEID : %v", pdr.PdrID, pdr.TunnelTEID)
-			createdPDR.Add(ie.NewFTEID(flags, pdr.TunnelTEID, teidIP, nil, pdr.ChooseID))
+			createdPDR.Add(ie.NewFTEID(flags, pdr.TunnelTEID, teidIP, teidIPv6, pdr.ChooseID))
 		}
 
 		if needAlloc {
@@ -99,20 +120,31 @@ func addPdrInfo(msg *message.SessionEstablishmentResponse,
 	}
 }
 
-// CreatePDR appends pdr to existing list of PDRs in the session.
-func (s *PFCPSession) CreatePDR(p Pdr) {
-	if p.IsDownlink() && p.UeAddress != 0 {
+// setUeAddress records the UE addresses of the downlink PDR p in the session.
+func (s *PFCPSession) setUeAddress(p Pdr) {
+	if !p.IsDownlink() {
+		return
+	}
+
+	if p.UeAddress != 0 {
 		s.UeAddress = p.UeAddress
 	}
 
+	if p.UeAddress6 != nil {
+		s.UeAddress6 = p.UeAddress6
+	}
+}
+
+// CreatePDR appends pdr to existing list of PDRs in the session.
+func (s *PFCPSession) CreatePDR(p Pdr) {
+	s.setUeAddress(p)
+
 	s.Pdrs = append(s.Pdrs, p)
 }
 
 // UpdatePDR updates existing pdr in the session.
 func (s *PFCPSession) UpdatePDR(p Pdr) error {
-	if p.IsDownlink() && p.UeAddress != 0 {
-		s.UeAddress = p.UeAddress
-	}
+	s.setUeAddress(p)
 
 	for idx, v := range s.Pdrs {
 		if v.PdrID == p.PdrID {
@@ -124,6 +156,17 @@ func (s *PFCPSession) UpdatePDR(p Pdr) error {
 	return ErrNotFound("PDR")
 }
 
+// findPDR returns the PDR with the given ID, or nil if not found.
+func (s *PFCPSession) findPDR(id uint32) *Pdr {
+	for idx := range s.Pdrs {
+		if s.Pdrs[idx].PdrID == id {
+			return &s.Pdrs[idx]
+		}
+	}
+
+	return nil
+}
+
 // RemovePDR removes pdr from existing list of PDRs in the session.
 func (s *PFCPSession) RemovePDR(id uint32) (*Pdr, error) {
 	for idx, v := range s.Pdrs {
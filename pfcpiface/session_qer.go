@@ -27,6 +27,17 @@ func (s *PFCPSession) UpdateQER(q Qer) error {
 	return ErrNotFound("QER")
 }
 
+// findQER returns the QER with the given ID, or nil if not found.
+func (s *PFCPSession) findQER(id uint32) *Qer {
+	for idx := range s.Qers {
+		if s.Qers[idx].QerID == id {
+			return &s.Qers[idx]
+		}
+	}
+
+	return nil
+}
+
 // Int version of code present at https://github.com/juliangruber/go-intersect
 func Intersect(a []uint32, b []uint32) []uint32 {
 	set := make([]uint32, 0)
@@ -62,6 +73,10 @@ func findItemIndex(slice []uint32, val uint32) int {
 
 // MarkSessionQer : identify and Mark session QER with flag.
 func (s *PFCPSession) MarkSessionQer(qers []Qer) {
+	if len(s.Pdrs) == 0 {
+		return
+	}
+
 	sessQerIDList := make([]uint32, 0)
 	lastPdrIndex := len(s.Pdrs) - 1
 	// create search list with first pdr's qerlist */
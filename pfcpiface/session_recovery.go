@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/wmnsk/go-pfcp/ie"
+)
+
+var (
+	// recoveryTimeout bounds the wait for the datapath, and the retries of transient failures,
+	// while recovering sessions.
+	recoveryTimeout = 30 * time.Second
+	// recoveryRetryInterval is the period of the datapath checks and retries during recovery.
+	recoveryRetryInterval = time.Second
+)
+
+// persistentSessions reports whether sessions are persisted across restarts of the agent.
+func (u *Upf) persistentSessions() bool {
+	return u.sessionStoreType == sessionStoreFile
+}
+
+// newSessionsStore returns the partition of the node-wide store sessions holding the sessions of
+// the PFCP peer with the given address. With a persistent store, it holds the sessions
+// recovered from a previous run.
+func (u *Upf) newSessionsStore(sessions *InMemoryStore, peer string) (SessionsStore, error) {
+	if !u.persistentSessions() {
+		return newPeerSessions(sessions, nil), nil
+	}
+
+	persist, err := NewFileStore(fileStorePath(u.sessionStorePath, peer))
+	if err != nil {
+		return nil, err
+	}
+
+	return newPeerSessions(sessions, persist), nil
+}
+
+// closeSessionsStore releases the resources held by store, if any.
+func closeSessionsStore(store SessionsStore) {
+	var err error
+
+	switch s := store.(type) {
+	case *peerSessions:
+		err = s.Close()
+	case *FileStore:
+		err = s.Close()
+	}
+
+	if err != nil {
+		log.Errorf("Failed to close session store: %v", err)
+	}
+}
+
+// recoverSessions restores the sessions persisted by a previous run of the agent: the TEIDs and
+// UE IPs allocated to them are reserved again and their rules are pushed to the datapath, once
+// it is connected. Sessions that can't be restored are removed from the store, so that the SMF
+// gets a "session context not found" for them rather than a session the datapath knows nothing
+// about. Transient datapath failures are retried until recoveryTimeout: past it, recovery fails
+// and the remaining sessions are kept for the next run.
+func (u *Upf) recoverSessions() error {
+	if !u.persistentSessions() {
+		return nil
+	}
+
+	peers, err := fileStorePeers(u.sessionStorePath)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(recoveryTimeout)
+	recovered, failed := 0, 0
+
+	for _, peer := range peers {
+		store, err := NewFileStore(fileStorePath(u.sessionStorePath, peer))
+		if err != nil {
+			return err
+		}
+
+		for _, session := range store.GetAllSessions() {
+			err := u.recoverSessionUntil(session, deadline)
+			if isTransientDatapathError(err) {
+				closeSessionsStore(store)
+				return err
+			}
+
+			if err != nil {
+				log.Errorf("Failed to recover session %v of peer %v: %v", session.localSEID, peer, err)
+
+				if err := store.DeleteSession(session.localSEID); err != nil {
+					log.Errorf("Failed to delete PFCP session from store: %v", err)
+				}
+
+				failed++
+
+				continue
+			}
+
+			recovered++
+		}
+
+		closeSessionsStore(store)
+	}
+
+	log.Infof("Recovered %v PFCP sessions, %v could not be recovered", recovered, failed)
+
+	return nil
+}
+
+// isTransientDatapathError checks if err is a datapath failure that may not happen again, e.g.
+// because the datapath is not up yet.
+func isTransientDatapathError(err error) bool {
+	var dpErr *DatapathError
+
+	return errors.As(err, &dpErr) &&
+		(dpErr.Cause == ie.CauseSystemFailure || dpErr.Cause == ie.CausePFCPEntityInCongestion)
+}
+
+// waitConnected waits until the datapath is connected, or deadline.
+func (u *Upf) waitConnected(deadline time.Time) error {
+	for !u.isConnected() {
+		if time.Now().After(deadline) {
+			return &DatapathError{Cause: ie.CauseSystemFailure, Err: errDatapathDown}
+		}
+
+		time.Sleep(recoveryRetryInterval)
+	}
+
+	return nil
+}
+
+// recoverSessionUntil recovers session once the datapath is connected, retrying transient
+// failures until deadline.
+func (u *Upf) recoverSessionUntil(session PFCPSession, deadline time.Time) error {
+	for {
+		if err := u.waitConnected(deadline); err != nil {
+			return err
+		}
+
+		err := u.recoverSession(session)
+		if !isTransientDatapathError(err) || time.Now().After(deadline) {
+			return err
+		}
+
+		log.Warnf("Failed to recover session %v, retrying: %v", session.localSEID, err)
+		time.Sleep(recoveryRetryInterval)
+	}
+}
+
+func (u *Upf) recoverSession(session PFCPSession) error {
+	if err := u.reserveAllocations(session); err != nil {
+		return err
+	}
+
+	tx := NewTransaction(u.Datapath)
+	tx.CreateRules(session.PacketForwardingRules)
+
+	err := u.commitTransaction(tx)
+
+	var dpErr *DatapathError
+	if errors.As(err, &dpErr) && dpErr.Cause == ie.CauseRuleCreationModificationFailure {
+		// The datapath may have kept the rules while the agent was down: update them instead.
+		tx = NewTransaction(u.Datapath)
+
+		for _, p := range session.Pdrs {
+			tx.ModifyPDR(p, p)
+		}
+
+		for _, f := range session.Fars {
+			tx.ModifyFAR(f, f)
+		}
+
+		for _, q := range session.Qers {
+			tx.ModifyQER(q, q)
+		}
+
+		for _, r := range session.Urrs {
+			tx.ModifyURR(r, r)
+		}
+
+		err = u.commitTransaction(tx)
+	}
+
+	if err != nil {
+		u.releaseAllocations(session)
+		return err
+	}
+
+	return nil
+}
+
+// reserveAllocations marks the TEIDs and the UE IP that the UPF allocated to session as in use.
+func (u *Upf) reserveAllocations(session PFCPSession) error {
+	if err := u.teidPools.reserve(session.Pdrs); err != nil {
+		return err
+	}
+
+	if len(ueAllocations(session.Pdrs)) > 0 {
+		var err error
+		if u.ippools == nil {
+			err = ErrInvalidOperation("UE IP allocation is disabled")
+		} else {
+			err = u.ippools.reserve(session.localSEID, session.Pdrs)
+		}
+
+		if err != nil {
+			u.teidPools.releaseUnused(session.Pdrs, nil)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseAllocations releases the TEIDs and the UE IP that the UPF allocated to session.
+func (u *Upf) releaseAllocations(session PFCPSession) {
+	u.teidPools.releaseUnused(session.Pdrs, nil)
+
+	if u.ippools != nil {
+		if err := u.ippools.release(session.localSEID, session.Pdrs); err != nil {
+			log.Errorf("Failed to release UE IP of session %v: %v", session.localSEID, err)
+		}
+	}
+}
+
+// peerFromAddr returns the host part of the address of a PFCP peer.
+func peerFromAddr(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
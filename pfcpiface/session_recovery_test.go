@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+	"github.com/wmnsk/go-pfcp/message"
+)
+
+// newPersistentTestUpf returns a UPF that persists its sessions to dir and allocates UE IPs,
+// as the agent would after (re)starting.
+func newPersistentTestUpf(t *testing.T, dp Datapath, dir string) *Upf {
+	upf := newTestUpf(dp)
+	upf.sessionStoreType = sessionStoreFile
+	upf.sessionStorePath = dir
+
+	var err error
+
+	upf.ippools, err = newUEIPPools(CPIfaceInfo{UEIPPool: "10.250.0.0/24"})
+	require.NoError(t, err)
+
+	return upf
+}
+
+// setRecoveryTiming shortens the waits of session recovery for the duration of the test.
+func setRecoveryTiming(t *testing.T, timeout, retryInterval time.Duration) {
+	prevTimeout, prevRetryInterval := recoveryTimeout, recoveryRetryInterval
+	recoveryTimeout, recoveryRetryInterval = timeout, retryInterval
+
+	t.Cleanup(func() {
+		recoveryTimeout, recoveryRetryInterval = prevTimeout, prevRetryInterval
+	})
+}
+
+func TestSessionRecovery(t *testing.T) {
+	// establish runs the agent until it is killed: nothing is shut down or closed.
+	establish := func(t *testing.T, dp Datapath, dir string) PFCPSession {
+		pConn := newTestPFCPConnWithUpf(t, newPersistentTestUpf(t, dp, dir))
+		seid := establishTestSession(t, pConn)
+
+		session, ok := pConn.store.GetSession(seid)
+		require.True(t, ok)
+
+		return session
+	}
+
+	requireAllocated := func(t *testing.T, upf *Upf, session PFCPSession) {
+		require.Error(t, upf.teidPools.byName[teidPoolAccess].ids.Reserve(session.Pdrs[0].TunnelTEID), "TEID must be reserved")
+		require.Error(t, upf.ippools.dflt.ip4.ReserveIP(session.localSEID+1, int2ip(session.UeAddress)),
+			"UE IP must be reserved")
+	}
+
+	t.Run("dataplane lost its rules", func(t *testing.T) {
+		dir := t.TempDir()
+		dp := NewRecordingDatapath()
+		session := establish(t, dp, dir)
+
+		restartedDp := NewRecordingDatapath()
+		restarted := newPersistentTestUpf(t, restartedDp, dir)
+		require.NoError(t, restarted.recoverSessions())
+
+		require.Equal(t, dp.Rules(), restartedDp.Rules())
+		requireAllocated(t, restarted, session)
+
+		// The SMF reconnects and finds its session
+		pConn := newTestPFCPConnWithUpf(t, restarted)
+
+		recovered, ok := pConn.store.GetSession(session.localSEID)
+		require.True(t, ok)
+		require.Equal(t, session.PacketForwardingRules, recovered.PacketForwardingRules)
+		require.Equal(t, session.remoteSEID, recovered.remoteSEID)
+
+		// New sessions don't reuse the allocations of the recovered one
+		seid := establishTestSession(t, pConn)
+		other, ok := pConn.store.GetSession(seid)
+		require.True(t, ok)
+		require.NotEqual(t, session.Pdrs[0].TunnelTEID, other.Pdrs[0].TunnelTEID)
+		require.NotEqual(t, session.UeAddress, other.UeAddress)
+
+		sdreq := message.NewSessionDeletionRequest(0, 0, session.localSEID, 3, 0)
+		reply, err := pConn.handleSessionDeletionRequest(sdreq)
+		require.NoError(t, err)
+
+		sdres, ok := reply.(*message.SessionDeletionResponse)
+		require.True(t, ok)
+		requireCause(t, ie.CauseRequestAccepted, sdres.Cause)
+
+		require.NoError(t, restarted.teidPools.byName[teidPoolAccess].ids.Reserve(session.Pdrs[0].TunnelTEID), "TEID must be released")
+		require.NoError(t, restarted.ippools.dflt.ip4.ReserveIP(session.localSEID, int2ip(session.UeAddress)),
+			"UE IP must be released")
+	})
+
+	t.Run("dataplane kept its rules", func(t *testing.T) {
+		dir := t.TempDir()
+		dp := NewRecordingDatapath()
+		session := establish(t, dp, dir)
+		installed := dp.Rules()
+
+		restarted := newPersistentTestUpf(t, dp, dir)
+		require.NoError(t, restarted.recoverSessions())
+
+		require.Equal(t, installed, dp.Rules())
+		requireAllocated(t, restarted, session)
+	})
+
+	t.Run("unrecoverable session is removed", func(t *testing.T) {
+		dir := t.TempDir()
+		session := establish(t, NewRecordingDatapath(), dir)
+
+		restartedDp := NewRecordingDatapath()
+		restartedDp.InjectError(UpfMsgTypeAdd, RuleTypeQER, ie.CauseNoResourcesAvailable)
+
+		restarted := newPersistentTestUpf(t, restartedDp, dir)
+		require.NoError(t, restarted.recoverSessions())
+
+		require.Equal(t, PacketForwardingRules{}, restartedDp.Rules())
+		require.Zero(t, restarted.teidPools.byName[teidPoolAccess].ids.Used())
+		require.NoError(t, restarted.ippools.dflt.ip4.ReserveIP(session.localSEID, int2ip(session.UeAddress)))
+
+		pConn := newTestPFCPConnWithUpf(t, restarted)
+		require.Empty(t, pConn.store.GetAllSessions())
+	})
+
+	t.Run("waits for the datapath", func(t *testing.T) {
+		setRecoveryTiming(t, 5*time.Second, 10*time.Millisecond)
+
+		dir := t.TempDir()
+		dp := NewRecordingDatapath()
+		establish(t, dp, dir)
+
+		restartedDp := NewRecordingDatapath()
+		restartedDp.SetConnected(false)
+
+		time.AfterFunc(100*time.Millisecond, func() { restartedDp.SetConnected(true) })
+
+		restarted := newPersistentTestUpf(t, restartedDp, dir)
+		require.NoError(t, restarted.recoverSessions())
+		require.Equal(t, dp.Rules(), restartedDp.Rules())
+	})
+
+	t.Run("transient failure is retried", func(t *testing.T) {
+		setRecoveryTiming(t, 5*time.Second, 10*time.Millisecond)
+
+		dir := t.TempDir()
+		dp := NewRecordingDatapath()
+		session := establish(t, dp, dir)
+
+		restartedDp := NewRecordingDatapath()
+		restartedDp.InjectError(UpfMsgTypeAdd, RuleTypeQER, ie.CauseSystemFailure)
+
+		restarted := newPersistentTestUpf(t, restartedDp, dir)
+		require.NoError(t, restarted.recoverSessions())
+		require.Equal(t, dp.Rules(), restartedDp.Rules())
+		requireAllocated(t, restarted, session)
+	})
+
+	t.Run("datapath down keeps sessions", func(t *testing.T) {
+		setRecoveryTiming(t, 50*time.Millisecond, 10*time.Millisecond)
+
+		dir := t.TempDir()
+		dp := NewRecordingDatapath()
+		session := establish(t, dp, dir)
+
+		downDp := NewRecordingDatapath()
+		downDp.SetConnected(false)
+
+		require.Error(t, newPersistentTestUpf(t, downDp, dir).recoverSessions())
+		require.Equal(t, PacketForwardingRules{}, downDp.Rules())
+
+		// The next run recovers the session
+		restartedDp := NewRecordingDatapath()
+		restarted := newPersistentTestUpf(t, restartedDp, dir)
+		require.NoError(t, restarted.recoverSessions())
+		require.Equal(t, dp.Rules(), restartedDp.Rules())
+
+		_, ok := newTestPFCPConnWithUpf(t, restarted).store.GetSession(session.localSEID)
+		require.True(t, ok)
+	})
+
+	t.Run("graceful stop keeps sessions", func(t *testing.T) {
+		dir := t.TempDir()
+		dp := NewRecordingDatapath()
+		pConn := newTestPFCPConnWithUpf(t, newPersistentTestUpf(t, dp, dir))
+		seid := establishTestSession(t, pConn)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		done := make(chan string, 1)
+		pConn.ctx, pConn.done, pConn.shutdown = ctx, done, make(chan struct{})
+		pConn.Shutdown()
+
+		require.NotEmpty(t, dp.Rules().Pdrs)
+
+		restarted := newPersistentTestUpf(t, NewRecordingDatapath(), dir)
+		require.NoError(t, restarted.recoverSessions())
+
+		_, ok := newTestPFCPConnWithUpf(t, restarted).store.GetSession(seid)
+		require.True(t, ok)
+	})
+}
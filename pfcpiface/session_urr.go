@@ -16,6 +16,17 @@ func (s *PFCPSession) UpdateURR(u Urr) error {
 	return ErrNotFound("URR")
 }
 
+// findURR returns the URR with the given ID, or nil if not found.
+func (s *PFCPSession) findURR(id uint32) *Urr {
+	for idx := range s.Urrs {
+		if s.Urrs[idx].UrrID == id {
+			return &s.Urrs[idx]
+		}
+	}
+
+	return nil
+}
+
 func (s *PFCPSession) RemoveURR(id uint32) (*Urr, error) {
 	for idx, v := range s.Urrs {
 		if v.UrrID == id {
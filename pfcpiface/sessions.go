@@ -5,8 +5,12 @@ package pfcpiface
 
 import (
 	"fmt"
+	"net"
+	"time"
 
-	"github.com/omec-project/upf-epc/pfcpiface/metrics"
+	"github.com/wmnsk/go-pfcp/ie"
+
+	"github.com/ardzoht/omec-upf/pfcpiface/metrics"
 )
 
 type PacketForwardingRules struct {
@@ -20,23 +24,105 @@ type PacketForwardingRules struct {
 type PFCPSession struct {
 	localSEID  uint64
 	remoteSEID uint64
-	metrics    *metrics.Session
+	// remoteIP is the IP address of the CP function in its F-SEID.
+	remoteIP net.IP
+	// cpFQCSID is the FQ-CSID of the CP function for the session, nil if it has none.
+	cpFQCSID *fqCSID
+	metrics  *metrics.Session
 	PacketForwardingRules
 	// used to store session <-> UE Address mapping
 	// which is needed to efficiently find UE address for UL PDRs in the PFCP messages.
 	UeAddress uint32
+	// UeAddress6 is the IPv6 prefix of the UE, nil if it has none
+	UeAddress6 net.IP
+	// ueKeys identify the UE for its UE IP reservations, from the User ID IE
+	ueKeys []string
+}
+
+// fqCSID is a Fully Qualified PDN Connection Set Identifier, see TS 29.244 8.2.46.
+type fqCSID struct {
+	NodeAddress string   `json:"node_address"`
+	CSIDs       []uint16 `json:"csids"`
+}
+
+func parseFQCSID(i *ie.IE) (*fqCSID, error) {
+	address, err := i.NodeAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	csids, err := i.CSIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	return &fqCSID{NodeAddress: net.IP(address).String(), CSIDs: csids}, nil
+}
+
+// matches checks if c and other are of the same node and share a CSID.
+func (c *fqCSID) matches(other *fqCSID) bool {
+	if c == nil || other == nil || c.NodeAddress != other.NodeAddress {
+		return false
+	}
+
+	for _, csid := range c.CSIDs {
+		for _, otherCSID := range other.CSIDs {
+			if csid == otherCSID {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 func (p PacketForwardingRules) String() string {
 	return fmt.Sprintf("PDRs=%v, FARs=%v, QERs=%v, URRs=%v", p.Pdrs, p.Fars, p.Qers, p.Urrs)
 }
 
+// DeepCopy returns a copy of the rules that shares no memory with p.
+func (p PacketForwardingRules) DeepCopy() PacketForwardingRules {
+	c := PacketForwardingRules{
+		Pdrs: make([]Pdr, len(p.Pdrs), cap(p.Pdrs)),
+		Fars: make([]Far, len(p.Fars), cap(p.Fars)),
+		Qers: make([]Qer, len(p.Qers), cap(p.Qers)),
+		Urrs: make([]Urr, len(p.Urrs), cap(p.Urrs)),
+	}
+
+	copy(c.Pdrs, p.Pdrs)
+	copy(c.Fars, p.Fars)
+	copy(c.Qers, p.Qers)
+	copy(c.Urrs, p.Urrs)
+
+	for i := range c.Pdrs {
+		if c.Pdrs[i].QerIDList != nil {
+			c.Pdrs[i].QerIDList = append(make([]uint32, 0, len(p.Pdrs[i].QerIDList)), p.Pdrs[i].QerIDList...)
+		}
+	}
+
+	return c
+}
+
+// DeepCopy returns a copy of the session whose rules can be modified without affecting s.
+// Session metrics are shared.
+func (s PFCPSession) DeepCopy() PFCPSession {
+	c := s
+	c.PacketForwardingRules = s.PacketForwardingRules.DeepCopy()
+
+	return c
+}
+
 // NewPFCPSession allocates an session with ID.
 func (pConn *PFCPConn) NewPFCPSession(rseid uint64) (PFCPSession, bool) {
 	for i := 0; i < pConn.maxRetries; i++ {
-		lseid := pConn.rng.Uint64()
-		// Check if it already exists
-		if _, ok := pConn.store.GetSession(lseid); ok {
+		// Sessions are established concurrently by the workers of pConn
+		pConn.rngMu.Lock()
+		lseid := pConn.seids.seid(pConn.rng.Uint64())
+		pConn.rngMu.Unlock()
+
+		// Check if it already exists, in this or in another PFCPConn: sessions recovered after
+		// a restart may belong to any range
+		if _, ok := pConn.store.GetSession(lseid); ok || !pConn.sessionOwners.claim(lseid, pConn) {
 			continue
 		}
 
@@ -63,11 +149,76 @@ func (pConn *PFCPConn) NewPFCPSession(rseid uint64) (PFCPSession, bool) {
 
 // RemoveSession removes session using lseid.
 func (pConn *PFCPConn) RemoveSession(session PFCPSession) {
-	// Metrics update
-	session.metrics.Delete()
-	pConn.SaveSessions(session.metrics)
+	// Metrics update. Sessions recovered after a restart have no metrics.
+	if session.metrics != nil {
+		session.metrics.Delete()
+		pConn.SaveSessions(session.metrics)
+	}
 
 	if err := pConn.store.DeleteSession(session.localSEID); err != nil {
 		log.Errorf("Failed to delete PFCP session from store: %v", err)
 	}
+
+	pConn.sessionOwners.release(session.localSEID, pConn)
+	pConn.upf.forgetDownlinkData(session.localSEID)
+	pConn.usage.forget(session.localSEID)
+}
+
+// purgeSession deletes session from the datapath and releases its UE IP and TEIDs, without its
+// peer requesting it, e.g. once the peer restarted.
+func (pConn *PFCPConn) purgeSession(session PFCPSession) {
+	tx := NewTransaction(pConn.upf.Datapath)
+	tx.DeleteRules(session.PacketForwardingRules)
+
+	if err := pConn.upf.commitTransaction(tx); err != nil {
+		log.Errorf("Failed to delete session %v from datapath: %v", session.localSEID, err)
+	}
+
+	if pConn.upf.ippools != nil {
+		if err := releaseAllocatedIPs(pConn.upf.ippools, &session); err != nil {
+			log.Errorf("Failed to release UE IP of session %v: %v", session.localSEID, err)
+		}
+	}
+
+	pConn.upf.teidPools.releaseUnused(session.Pdrs, nil)
+
+	pConn.RemoveSession(session)
+}
+
+// handlePeerRecovery checks the Recovery Time Stamp ts of the peer. A newer one than known means
+// that the peer restarted and lost its sessions: they are stale.
+func (pConn *PFCPConn) handlePeerRecovery(ts time.Time) {
+	if pConn.ts.remote.IsZero() {
+		pConn.ts.remote = ts
+		return
+	}
+
+	if !ts.After(pConn.ts.remote) {
+		return
+	}
+
+	old := pConn.ts.remote
+	pConn.ts.remote = ts
+
+	sessions := pConn.store.GetAllSessions()
+
+	log.Warnf("Peer %v restarted, recovery timestamp: %v older: %v, %v sessions are stale",
+		pConn.RemoteAddr(), ts, old, len(sessions))
+
+	pConn.handleStaleSessions(sessions)
+}
+
+// claimSessions claims the ownership of the sessions found in the store of pConn, i.e. of
+// sessions recovered after a restart. The measurement of their URRs restarts.
+func (pConn *PFCPConn) claimSessions() {
+	for _, session := range pConn.store.GetAllSessions() {
+		if !pConn.sessionOwners.claim(session.localSEID, pConn) {
+			log.Warnf("Session %v is owned by another PFCP connection, reports won't reach %v",
+				session.localSEID, pConn.RemoteAddr())
+
+			continue
+		}
+
+		pConn.usage.sync(session.localSEID, session.Urrs, time.Now())
+	}
 }
@@ -3,12 +3,29 @@
 
 package pfcpiface
 
+import "net"
+
+const (
+	// sessionStoreMemory keeps sessions in memory only, they are lost on restart.
+	sessionStoreMemory = "memory"
+	// sessionStoreFile persists sessions to disk, see FileStore.
+	sessionStoreFile = "file"
+)
+
 type SessionsStore interface {
 	// PutSession modifies the PFCP Session data indexed by a given F-SEID or
 	// inserts a new PFCP Session record, if it doesn't exist yet.
 	PutSession(session PFCPSession) error
 	// GetSession returns the PFCP Session data based on F-SEID.
 	GetSession(fseid uint64) (PFCPSession, bool)
+	// GetSessionByUEAddress returns the PFCP Session data of the UE with the given IP address.
+	GetSessionByUEAddress(ueAddress net.IP) (PFCPSession, bool)
+	// GetSessionByFTEID returns the PFCP Session data owning a PDR matching the given N3/N9
+	// F-TEID.
+	GetSessionByFTEID(teid uint32, ip net.IP) (PFCPSession, bool)
+	// GetSessionByRemoteFSEID returns the PFCP Session data based on the F-SEID allocated by the
+	// CP function, made of its SEID and IP address.
+	GetSessionByRemoteFSEID(seid uint64, ip net.IP) (PFCPSession, bool)
 	// GetAllSessions returns all the PFCP Session records that are currently stored.
 	GetAllSessions() []PFCPSession
 	// DeleteSession removes a PFCP Session record indexed by F-SEID.
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newIndexedTestSession(seid uint64, ueAddress string, teid uint32) PFCPSession {
+	session := newTestSession(seid)
+	session.remoteSEID = seid + 100
+	session.remoteIP = net.ParseIP("10.0.0.100")
+	session.UeAddress = ip2int(net.ParseIP(ueAddress))
+	session.Pdrs[0].TunnelTEID = teid
+	session.Pdrs[0].TunnelIP4Dst = ip2int(net.ParseIP("192.168.0.1"))
+	session.Pdrs[1].UeAddress = session.UeAddress
+
+	return session
+}
+
+func TestSessionsStore_Indexes(t *testing.T) {
+	accessIP := net.ParseIP("192.168.0.1")
+
+	stores := map[string]func(t *testing.T) SessionsStore{
+		"memory": func(t *testing.T) SessionsStore {
+			return NewInMemoryStore()
+		},
+		"file": func(t *testing.T) SessionsStore {
+			store, err := NewFileStore(filepath.Join(t.TempDir(), "sessions-test.log"))
+			require.NoError(t, err)
+
+			return store
+		},
+		"partition": func(t *testing.T) SessionsStore {
+			return newPeerSessions(NewInMemoryStore(), nil)
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			s1 := newIndexedTestSession(1, "10.250.0.1", 0x10)
+			s2 := newIndexedTestSession(2, "10.250.0.2", 0x20)
+
+			require.NoError(t, store.PutSession(s1))
+			require.NoError(t, store.PutSession(s2))
+
+			got, ok := store.GetSessionByUEAddress(net.ParseIP("10.250.0.1").To4())
+			require.True(t, ok, "4 and 16 bytes forms of an address must match")
+			require.Equal(t, s1, got)
+
+			got, ok = store.GetSessionByFTEID(0x20, accessIP)
+			require.True(t, ok)
+			require.Equal(t, s2, got)
+
+			_, ok = store.GetSessionByFTEID(0x20, net.ParseIP("192.168.0.2"))
+			require.False(t, ok, "F-TEID includes the IP address")
+
+			got, ok = store.GetSessionByRemoteFSEID(101, net.ParseIP("10.0.0.100"))
+			require.True(t, ok)
+			require.Equal(t, s1, got)
+
+			// Keys that changed are replaced
+			updated := s1.DeepCopy()
+			updated.Pdrs[0].TunnelTEID = 0x11
+			require.NoError(t, store.PutSession(updated))
+
+			_, ok = store.GetSessionByFTEID(0x10, accessIP)
+			require.False(t, ok)
+
+			got, ok = store.GetSessionByFTEID(0x11, accessIP)
+			require.True(t, ok)
+			require.Equal(t, updated, got)
+
+			// Keys taken over by another session are not removed along with the original one
+			s3 := newIndexedTestSession(3, "10.250.0.2", 0x30)
+			require.NoError(t, store.PutSession(s3))
+			require.NoError(t, store.DeleteSession(2))
+
+			got, ok = store.GetSessionByUEAddress(net.ParseIP("10.250.0.2"))
+			require.True(t, ok)
+			require.Equal(t, s3, got)
+
+			_, ok = store.GetSessionByFTEID(0x20, accessIP)
+			require.False(t, ok)
+
+			_, ok = store.GetSessionByRemoteFSEID(102, net.ParseIP("10.0.0.100"))
+			require.False(t, ok)
+
+			if fs, ok := store.(*FileStore); ok {
+				require.NoError(t, fs.Close())
+
+				reopened, err := NewFileStore(fs.path)
+				require.NoError(t, err)
+
+				store = reopened
+
+				got, ok = store.GetSessionByFTEID(0x11, accessIP)
+				require.True(t, ok, "indexes must be rebuilt when the store is opened")
+				require.Equal(t, updated, got)
+			}
+
+			require.True(t, store.DeleteAllSessions())
+
+			_, ok = store.GetSessionByUEAddress(net.ParseIP("10.250.0.1"))
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestSessionsStore_IPv6Indexes(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "sessions-test.log"))
+	require.NoError(t, err)
+
+	n3Address6 := net.ParseIP("2001:db8::1")
+
+	session := newTestSession(1)
+	session.UeAddress6 = net.ParseIP("2001:db8:1:2::")
+	session.Pdrs[0].TunnelTEID = 0x10
+	session.Pdrs[0].TunnelIP6Dst = n3Address6
+	session.Pdrs[1].UeAddress6 = session.UeAddress6
+	session.Pdrs[1].UeAddress6PrefixLen = 64
+
+	other := newTestSession(2)
+	other.Pdrs[1].UeAddress6 = net.ParseIP("2001:db8:1:2:3::")
+	other.Pdrs[1].UeAddress6PrefixLen = 80
+
+	require.NoError(t, store.PutSession(session))
+	require.NoError(t, store.PutSession(other))
+
+	// UE addresses are found in the longest prefix containing them
+	got, ok := store.GetSessionByUEAddress(net.ParseIP("2001:db8:1:2::5"))
+	require.True(t, ok)
+	require.Equal(t, session, got)
+
+	got, ok = store.GetSessionByUEAddress(net.ParseIP("2001:db8:1:2:3::5"))
+	require.True(t, ok)
+	require.Equal(t, other, got)
+
+	_, ok = store.GetSessionByUEAddress(net.ParseIP("2001:db8:1:3::5"))
+	require.False(t, ok)
+
+	got, ok = store.GetSessionByFTEID(0x10, n3Address6)
+	require.True(t, ok)
+	require.Equal(t, session, got)
+
+	require.NoError(t, store.Close())
+
+	store, err = NewFileStore(store.path)
+	require.NoError(t, err)
+
+	got, ok = store.GetSessionByUEAddress(net.ParseIP("2001:db8:1:2::5"))
+	require.True(t, ok, "IPv6 addresses must be persisted")
+	require.Equal(t, session, got)
+
+	require.NoError(t, store.DeleteSession(2))
+
+	_, ok = store.GetSessionByUEAddress(net.ParseIP("2001:db8:1:2:3::5"))
+	require.True(t, ok, "the address is in the prefix of the remaining session")
+	require.NoError(t, store.Close())
+}
@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+)
+
+// Names of the TEID pools of the access (N3) and core interfaces, with the addresses of the
+// interfaces. They share the TEIDs of teid_prefix.
+const (
+	teidPoolAccess = "access"
+	teidPoolCore   = "core"
+)
+
+// teidPool is a pool of the TEIDs allocated for F-TEIDs on an interface, with the local
+// addresses of the tunnel endpoint.
+type teidPool struct {
+	name string
+	// srcIface is the Source Interface of the PDRs served by the pool
+	srcIface uint8
+	// networkInstance is the Network Instance of the PDRs served by the pool, empty for any
+	networkInstance string
+	ip4             net.IP
+	ip6             net.IP
+	ids             *IDAllocator
+}
+
+type teidPoolKey struct {
+	srcIface        uint8
+	networkInstance string
+}
+
+// teidPools are the TEID pools of the UPF. The pool of an F-TEID is selected by the Source
+// Interface and the Network Instance of its PDR.
+type teidPools struct {
+	byName  map[string]*teidPool
+	byIface map[teidPoolKey]*teidPool
+}
+
+// newTEIDPools returns the default pools of the access and core interfaces, with the given
+// addresses, and the pools of conf.
+func newTEIDPools(conf CPIfaceInfo, accessIP, accessIP6, coreIP, coreIP6 net.IP) (*teidPools, error) {
+	ids, err := NewIDAllocatorWithPrefix(conf.TEIDPrefix, conf.TEIDPrefixLen)
+	if err != nil {
+		return nil, err
+	}
+
+	pools := &teidPools{
+		byName:  make(map[string]*teidPool),
+		byIface: make(map[teidPoolKey]*teidPool),
+	}
+
+	pools.add(&teidPool{name: teidPoolAccess, srcIface: access, ip4: accessIP, ip6: accessIP6, ids: ids})
+	pools.add(&teidPool{name: teidPoolCore, srcIface: core, ip4: coreIP, ip6: coreIP6, ids: ids})
+
+	for _, poolConf := range conf.TEIDPools {
+		pool, err := newTEIDPool(poolConf)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := pools.byName[pool.name]; ok {
+			return nil, ErrInvalidArgumentWithReason("TEID pool", pool.name, "duplicate name")
+		}
+
+		key := teidPoolKey{pool.srcIface, pool.networkInstance}
+		if other, ok := pools.byIface[key]; ok && other.name != teidPoolAccess && other.name != teidPoolCore {
+			return nil, ErrInvalidArgumentWithReason("TEID pool", pool.name,
+				"same interface and network instance as pool "+other.name)
+		}
+
+		if err := pools.checkOverlap(pool); err != nil {
+			return nil, err
+		}
+
+		pools.add(pool)
+	}
+
+	return pools, nil
+}
+
+func newTEIDPool(conf TEIDPoolInfo) (*teidPool, error) {
+	if conf.Name == "" {
+		return nil, ErrInvalidArgumentWithReason("TEID pool", conf, "missing name")
+	}
+
+	pool := &teidPool{name: conf.Name, networkInstance: conf.NetworkInstance}
+
+	switch conf.Interface {
+	case "access":
+		pool.srcIface = access
+	case "core":
+		pool.srcIface = core
+	default:
+		return nil, ErrInvalidArgumentWithReason("TEID pool interface", conf.Interface, "must be access or core")
+	}
+
+	if conf.IPv4 != "" {
+		if pool.ip4 = net.ParseIP(conf.IPv4).To4(); pool.ip4 == nil {
+			return nil, ErrInvalidArgumentWithReason("TEID pool IPv4", conf.IPv4, "invalid IPv4 address")
+		}
+	}
+
+	if conf.IPv6 != "" {
+		if pool.ip6 = net.ParseIP(conf.IPv6); pool.ip6 == nil || !isIPv6(pool.ip6) {
+			return nil, ErrInvalidArgumentWithReason("TEID pool IPv6", conf.IPv6, "invalid IPv6 address")
+		}
+	}
+
+	if pool.ip4 == nil && pool.ip6 == nil {
+		return nil, ErrInvalidArgumentWithReason("TEID pool", conf.Name, "no IPv4 nor IPv6 address")
+	}
+
+	var err error
+	if pool.ids, err = NewIDAllocatorWithPrefix(conf.TEIDPrefix, conf.TEIDPrefixLen); err != nil {
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+// addNetworkInstances adds the pools of the access and core interfaces of instances, with their
+// addresses, unless pools of the same interface and network instance are configured. They share
+// the TEIDs of the default pools.
+func (p *teidPools) addNetworkInstances(instances networkInstances) error {
+	ids := p.byName[teidPoolAccess].ids
+
+	for _, ni := range instances {
+		for _, pool := range []*teidPool{
+			{name: ni.name + "-" + teidPoolAccess, srcIface: access, ip4: ni.accessIP, ip6: ni.accessIP6},
+			{name: ni.name + "-" + teidPoolCore, srcIface: core, ip4: ni.coreIP, ip6: ni.coreIP6},
+		} {
+			if pool.ip4 == nil && pool.ip6 == nil {
+				continue
+			}
+
+			if _, ok := p.byIface[teidPoolKey{pool.srcIface, ni.name}]; ok {
+				continue
+			}
+
+			if _, ok := p.byName[pool.name]; ok {
+				return ErrInvalidArgumentWithReason("TEID pool", pool.name, "duplicate name")
+			}
+
+			pool.networkInstance = ni.name
+			pool.ids = ids
+
+			if err := p.checkOverlap(pool); err != nil {
+				return err
+			}
+
+			p.add(pool)
+		}
+	}
+
+	return nil
+}
+
+// add adds pool, which replaces any pool of the same interface and network instance for the
+// allocation of new TEIDs.
+func (p *teidPools) add(pool *teidPool) {
+	p.byName[pool.name] = pool
+	p.byIface[teidPoolKey{pool.srcIface, pool.networkInstance}] = pool
+}
+
+// checkOverlap checks that the TEIDs of pool are not allocated by another pool with one of
+// the same addresses, as F-TEIDs are told apart by TEID and address only.
+func (p *teidPools) checkOverlap(pool *teidPool) error {
+	for _, other := range p.byName {
+		if other.ids == pool.ids || !sharesAddress(pool, other) {
+			continue
+		}
+
+		if pool.ids.minValue <= other.ids.maxValue && other.ids.minValue <= pool.ids.maxValue {
+			return ErrInvalidArgumentWithReason("TEID pool", pool.name,
+				"TEIDs overlap with pool "+other.name+" on the same address")
+		}
+	}
+
+	return nil
+}
+
+func sharesAddress(a, b *teidPool) bool {
+	return (a.ip4 != nil && a.ip4.Equal(b.ip4)) || (a.ip6 != nil && a.ip6.Equal(b.ip6))
+}
+
+// lookup returns the pool of the F-TEIDs of the PDRs with the given Source Interface and
+// Network Instance: the pool of the network instance, or else the pool of the interface.
+func (p *teidPools) lookup(srcIface uint8, networkInstance string) (*teidPool, error) {
+	if pool, ok := p.byIface[teidPoolKey{srcIface, networkInstance}]; ok {
+		return pool, nil
+	}
+
+	if pool, ok := p.byIface[teidPoolKey{srcIface, ""}]; ok {
+		return pool, nil
+	}
+
+	return nil, ErrNotFoundWithParam("TEID pool", "source interface", srcIface)
+}
+
+// poolOf returns the pool of the TEID allocated for pdr. PDRs stored before TEID pools were
+// introduced have none: their TEIDs come from the pool of their interface.
+func (p *teidPools) poolOf(pdr Pdr) (*teidPool, error) {
+	name := pdr.TEIDPool
+	if name == "" {
+		name = teidPoolAccess
+		if pdr.SrcIface == core {
+			name = teidPoolCore
+		}
+	}
+
+	pool, ok := p.byName[name]
+	if !ok {
+		return nil, ErrNotFoundWithParam("TEID pool", "name", name)
+	}
+
+	return pool, nil
+}
+
+// reserve marks the TEIDs allocated for pdrs as in use, e.g. to restore sessions after a
+// restart. Nothing is reserved if one of them is already in use.
+func (p *teidPools) reserve(pdrs []Pdr) error {
+	for i, pdr := range pdrs {
+		if !pdr.AllocTEIDFlag || p.usesTEID(pdrs[:i], pdr) {
+			continue
+		}
+
+		pool, err := p.poolOf(pdr)
+		if err == nil {
+			err = pool.ids.Reserve(pdr.TunnelTEID)
+		}
+
+		if err != nil {
+			p.releaseUnused(pdrs[:i], nil)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseUnused releases the TEIDs allocated for pdrs, unless they are still used by one of
+// the PDRs of inUse.
+func (p *teidPools) releaseUnused(pdrs []Pdr, inUse []Pdr) {
+	for i, pdr := range pdrs {
+		if !pdr.AllocTEIDFlag || p.usesTEID(inUse, pdr) || p.usesTEID(pdrs[:i], pdr) {
+			continue
+		}
+
+		pool, err := p.poolOf(pdr)
+		if err != nil {
+			log.Errorf("Failed to release TEID %v of PDR %v: %v", pdr.TunnelTEID, pdr.PdrID, err)
+			continue
+		}
+
+		log.Debugf("Releasing TEID %v of pool %v", pdr.TunnelTEID, pool.name)
+		pool.ids.Free(pdr.TunnelTEID)
+	}
+}
+
+// usesTEID checks if one of pdrs uses the TEID allocated for pdr.
+func (p *teidPools) usesTEID(pdrs []Pdr, pdr Pdr) bool {
+	pool, err := p.poolOf(pdr)
+	if err != nil {
+		return false
+	}
+
+	for _, other := range pdrs {
+		if !other.AllocTEIDFlag || other.TunnelTEID != pdr.TunnelTEID {
+			continue
+		}
+
+		// Pools sharing their TEIDs allocate them once for all
+		if otherPool, err := p.poolOf(other); err == nil && otherPool.ids == pool.ids {
+			return true
+		}
+	}
+
+	return false
+}
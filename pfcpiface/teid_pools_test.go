@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testN3Address = net.ParseIP("192.168.0.1")
+	testN6Address = net.ParseIP("192.168.1.1")
+)
+
+func TestNewTEIDPools(t *testing.T) {
+	tests := []struct {
+		name    string
+		pools   []TEIDPoolInfo
+		wantErr bool
+	}{
+		{name: "default pools only"},
+		{
+			name: "N9 pools",
+			pools: []TEIDPoolInfo{
+				{Name: "n9-ul", Interface: "access", NetworkInstance: "n9", IPv4: "192.168.9.1"},
+				{Name: "n9-dl", Interface: "core", NetworkInstance: "n9", IPv6: "2001:db8:9::1"},
+			},
+		},
+		{
+			name:  "pool replacing the one of an interface",
+			pools: []TEIDPoolInfo{{Name: "n3", Interface: "access", IPv4: "192.168.3.1"}},
+		},
+		{
+			name:    "missing name",
+			pools:   []TEIDPoolInfo{{Interface: "access", IPv4: "192.168.9.1"}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate name",
+			pools:   []TEIDPoolInfo{{Name: teidPoolCore, Interface: "core", IPv4: "192.168.9.1"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid interface",
+			pools:   []TEIDPoolInfo{{Name: "n9", Interface: "n9", IPv4: "192.168.9.1"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing address",
+			pools:   []TEIDPoolInfo{{Name: "n9", Interface: "access"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid IPv6 address",
+			pools:   []TEIDPoolInfo{{Name: "n9", Interface: "access", IPv6: "192.168.9.1"}},
+			wantErr: true,
+		},
+		{
+			name: "same interface and network instance",
+			pools: []TEIDPoolInfo{
+				{Name: "n9", Interface: "access", NetworkInstance: "n9", IPv4: "192.168.9.1"},
+				{Name: "n9-2", Interface: "access", NetworkInstance: "n9", IPv4: "192.168.9.2"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "TEIDs overlapping on the same address",
+			pools:   []TEIDPoolInfo{{Name: "n9", Interface: "core", IPv4: testN3Address.String()}},
+			wantErr: true,
+		},
+		{
+			name: "TEIDs split on the same address",
+			pools: []TEIDPoolInfo{
+				{Name: "n9", Interface: "core", IPv4: "192.168.9.1", TEIDPrefix: 0, TEIDPrefixLen: 1},
+				{Name: "n9-2", Interface: "access", IPv4: "192.168.9.1", TEIDPrefix: 1, TEIDPrefixLen: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTEIDPools(CPIfaceInfo{TEIDPools: tt.pools}, testN3Address, nil, testN6Address, nil)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTEIDPools_lookup(t *testing.T) {
+	pools, err := newTEIDPools(CPIfaceInfo{TEIDPools: []TEIDPoolInfo{
+		{Name: "n9", Interface: "access", NetworkInstance: "n9", IPv4: "192.168.9.1"},
+	}}, testN3Address, nil, testN6Address, nil)
+	require.NoError(t, err)
+
+	lookup := func(srcIface uint8, networkInstance string) string {
+		pool, err := pools.lookup(srcIface, networkInstance)
+		require.NoError(t, err)
+
+		return pool.name
+	}
+
+	require.Equal(t, "n9", lookup(access, "n9"))
+	require.Equal(t, teidPoolAccess, lookup(access, "internet"), "other network instances use the interface pool")
+	require.Equal(t, teidPoolCore, lookup(core, "n9"))
+
+	_, err = pools.lookup(0, "")
+	require.Error(t, err)
+}
+
+func TestTEIDPools_reserveAndRelease(t *testing.T) {
+	pools, err := newTEIDPools(CPIfaceInfo{TEIDPools: []TEIDPoolInfo{
+		{Name: "n9", Interface: "access", NetworkInstance: "n9", IPv4: "192.168.9.1"},
+	}}, testN3Address, nil, testN6Address, nil)
+	require.NoError(t, err)
+
+	pdrs := []Pdr{
+		{PdrID: 1, SrcIface: access, AllocTEIDFlag: true, TEIDPool: teidPoolAccess, TunnelTEID: 7},
+		// Same F-TEID, chosen with CHOOSE ID
+		{PdrID: 2, SrcIface: access, AllocTEIDFlag: true, TEIDPool: teidPoolAccess, TunnelTEID: 7},
+		// Same TEID from another pool
+		{PdrID: 3, SrcIface: access, AllocTEIDFlag: true, TEIDPool: "n9", TunnelTEID: 7},
+		// Stored before TEID pools, from the pool of the core interface
+		{PdrID: 4, SrcIface: core, AllocTEIDFlag: true, TunnelTEID: 8},
+		// TEID given by the CP function
+		{PdrID: 5, SrcIface: access, TunnelTEID: 9},
+	}
+
+	n3 := pools.byName[teidPoolAccess].ids
+	n9 := pools.byName["n9"].ids
+
+	require.NoError(t, pools.reserve(pdrs))
+	require.Equal(t, uint64(2), n3.Used(), "the access and core pools share their TEIDs")
+	require.Equal(t, uint64(1), n9.Used())
+	require.False(t, n3.InUse(9))
+
+	require.Error(t, pools.reserve(pdrs[2:3]), "TEID in use")
+	require.Error(t, pools.reserve([]Pdr{{AllocTEIDFlag: true, TEIDPool: "unknown"}}))
+
+	pools.releaseUnused(pdrs, pdrs[1:2])
+	require.True(t, n3.InUse(7), "TEID still used")
+	require.False(t, n3.InUse(8))
+	require.Zero(t, n9.Used())
+
+	pools.releaseUnused(pdrs[:2], nil)
+	require.Zero(t, n3.Used())
+}
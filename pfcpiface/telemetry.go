@@ -32,6 +32,13 @@ type UpfCollector struct {
 	latency *prometheus.Desc
 	jitter  *prometheus.Desc
 
+	datapathUp *prometheus.Desc
+
+	ddnNotifications *prometheus.Desc
+
+	uePoolAddresses *prometheus.Desc
+	uePoolExhausted *prometheus.Desc
+
 	upf *Upf
 }
 
@@ -57,6 +64,22 @@ func newUpfCollector(upf *Upf) *UpfCollector {
 			"Shows the packet processing jitter percentiles in UPF",
 			[]string{"iface"}, nil,
 		),
+		datapathUp: prometheus.NewDesc(prometheus.BuildFQName("upf", "datapath", "up"),
+			"Shows whether the UPF datapath is reachable (1) or not (0)",
+			nil, nil,
+		),
+		ddnNotifications: prometheus.NewDesc(prometheus.BuildFQName("upf", "ddn", "notifications_total"),
+			"Shows the number of downlink data notifications emitted or suppressed by rate limiting",
+			[]string{"result"}, nil,
+		),
+		uePoolAddresses: prometheus.NewDesc(prometheus.BuildFQName("upf", "ue_pool", "addresses"),
+			"Shows the number of UE addresses (IPv6 prefixes) used, free or reserved in the UE IP pools",
+			[]string{"pool", "family", "state"}, nil,
+		),
+		uePoolExhausted: prometheus.NewDesc(prometheus.BuildFQName("upf", "ue_pool", "exhausted_total"),
+			"Shows the number of UE address allocations that failed because the UE IP pool was exhausted",
+			[]string{"pool", "family"}, nil,
+		),
 		upf: upf,
 	}
 }
@@ -69,12 +92,47 @@ func (uc *UpfCollector) Describe(ch chan<- *prometheus.Desc) {
 
 	ch <- uc.latency
 	ch <- uc.jitter
+
+	ch <- uc.datapathUp
+
+	ch <- uc.ddnNotifications
+
+	ch <- uc.uePoolAddresses
+	ch <- uc.uePoolExhausted
 }
 
 // Collect writes all metrics to prometheus metric channel.
 func (uc *UpfCollector) Collect(ch chan<- prometheus.Metric) {
-	// Not implemented
-	return
+	if uc.upf == nil || uc.upf.Datapath == nil {
+		return
+	}
+
+	up := 0.0
+	if uc.upf.isConnected() {
+		up = 1.0
+	}
+
+	ch <- prometheus.MustNewConstMetric(uc.datapathUp, prometheus.GaugeValue, up)
+
+	if uc.upf.ddnNotifier != nil {
+		emitted, suppressed := uc.upf.ddnNotifier.counters()
+		ch <- prometheus.MustNewConstMetric(uc.ddnNotifications, prometheus.CounterValue, float64(emitted), "emitted")
+		ch <- prometheus.MustNewConstMetric(uc.ddnNotifications, prometheus.CounterValue, float64(suppressed),
+			"suppressed")
+	}
+
+	if uc.upf.ippools != nil {
+		for _, s := range uc.upf.ippools.stats() {
+			ch <- prometheus.MustNewConstMetric(uc.uePoolAddresses, prometheus.GaugeValue, float64(s.used),
+				s.pool, s.family, "used")
+			ch <- prometheus.MustNewConstMetric(uc.uePoolAddresses, prometheus.GaugeValue, float64(s.free),
+				s.pool, s.family, "free")
+			ch <- prometheus.MustNewConstMetric(uc.uePoolAddresses, prometheus.GaugeValue, float64(s.reserved),
+				s.pool, s.family, "reserved")
+			ch <- prometheus.MustNewConstMetric(uc.uePoolExhausted, prometheus.CounterValue, float64(s.exhausted),
+				s.pool, s.family)
+		}
+	}
 }
 
 func (uc *UpfCollector) portStats(ch chan<- prometheus.Metric) {
@@ -94,6 +152,7 @@ type PfcpNodeCollector struct {
 	sessionRxPackets      *prometheus.Desc
 	sessionDroppedPackets *prometheus.Desc
 	sessionTxBytes        *prometheus.Desc
+	outboundQueueDepth    *prometheus.Desc
 }
 
 func NewPFCPNodeCollector(node *PFCPNode) *PfcpNodeCollector {
@@ -123,6 +182,10 @@ func NewPFCPNodeCollector(node *PFCPNode) *PfcpNodeCollector {
 			"Shows the total number of bytes for a given session in UPF",
 			[]string{"fseid", "pdr", "ue_ip"}, nil,
 		),
+		outboundQueueDepth: prometheus.NewDesc(prometheus.BuildFQName("upf", "pfcp", "outbound_queue_depth"),
+			"Shows the number of PFCP messages waiting to be sent to a PFCP peer",
+			[]string{"node_id", "peer"}, nil,
+		),
 	}
 }
 
@@ -131,8 +194,17 @@ func (col PfcpNodeCollector) Describe(ch chan<- *prometheus.Desc) {
 }
 
 func (col PfcpNodeCollector) Collect(ch chan<- prometheus.Metric) {
-	// Not implemented
-	return
+	col.node.pConns.Range(func(key, value interface{}) bool {
+		pConn := value.(*PFCPConn)
+		if pConn.outbound == nil {
+			return true
+		}
+
+		ch <- prometheus.MustNewConstMetric(col.outboundQueueDepth, prometheus.GaugeValue,
+			float64(pConn.outbound.depth()), pConn.nodeID.remote, key.(string))
+
+		return true
+	})
 }
 
 func setupProm(mux *http.ServeMux, upf *Upf, node *PFCPNode) (*UpfCollector, *PfcpNodeCollector, error) {
@@ -0,0 +1,455 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/wmnsk/go-pfcp/ie"
+)
+
+// RuleType is the type of a forwarding rule pushed to the datapath.
+type RuleType int
+
+const (
+	RuleTypePDR RuleType = iota
+	RuleTypeFAR
+	RuleTypeQER
+	RuleTypeURR
+)
+
+func (t RuleType) String() string {
+	switch t {
+	case RuleTypePDR:
+		return "PDR"
+	case RuleTypeFAR:
+		return "FAR"
+	case RuleTypeQER:
+		return "QER"
+	case RuleTypeURR:
+		return "URR"
+	default:
+		return "unknown"
+	}
+}
+
+// DatapathError is returned when the datapath fails to apply a rule. Datapath implementations
+// only need to fill Cause and Err; the rule is identified by the Transaction.
+type DatapathError struct {
+	Op       UpfMsgType
+	RuleType RuleType
+	RuleID   uint32
+	// Cause is the PFCP cause reported to the CP function.
+	Cause uint8
+	Err   error
+}
+
+func (e *DatapathError) Error() string {
+	return fmt.Sprintf("%s %s %d: %v: %v", e.Op, e.RuleType, e.RuleID, ErrWriteToDatapath, e.Err)
+}
+
+func (e *DatapathError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes every DatapathError match ErrWriteToDatapath.
+func (e *DatapathError) Is(target error) bool {
+	return target == ErrWriteToDatapath
+}
+
+// OffendingIE returns an Offending IE with the type of the IE that carried the failed rule,
+// e.g. Create PDR for a PDR that could not be created.
+func (e *DatapathError) OffendingIE() *ie.IE {
+	ieTypes := map[UpfMsgType][]uint16{
+		UpfMsgTypeAdd: {ie.CreatePDR, ie.CreateFAR, ie.CreateQER, ie.CreateURR},
+		UpfMsgTypeMod: {ie.UpdatePDR, ie.UpdateFAR, ie.UpdateQER, ie.UpdateURR},
+		UpfMsgTypeDel: {ie.RemovePDR, ie.RemoveFAR, ie.RemoveQER, ie.RemoveURR},
+	}
+
+	types, ok := ieTypes[e.Op]
+	if !ok || e.RuleType < RuleTypePDR || e.RuleType > RuleTypeURR {
+		return nil
+	}
+
+	return ie.NewOffendingIE(types[e.RuleType])
+}
+
+// FailedRuleID returns a Failed Rule ID IE identifying the rule that could not be applied.
+func (e *DatapathError) FailedRuleID() *ie.IE {
+	switch e.RuleType {
+	case RuleTypePDR:
+		return ie.NewFailedRuleID(ie.RuleIDTypePDR, e.RuleID)
+	case RuleTypeFAR:
+		return ie.NewFailedRuleID(ie.RuleIDTypeFAR, e.RuleID)
+	case RuleTypeQER:
+		return ie.NewFailedRuleID(ie.RuleIDTypeQER, e.RuleID)
+	case RuleTypeURR:
+		return ie.NewFailedRuleID(ie.RuleIDTypeURR, e.RuleID)
+	default:
+		return nil
+	}
+}
+
+// datapathErrorReply returns the PFCP cause and the IEs identifying the failed rule, to be added
+// to the response of the PFCP message whose rules could not be applied.
+func datapathErrorReply(err error) (uint8, []*ie.IE) {
+	var dpErr *DatapathError
+	if !errors.As(err, &dpErr) {
+		return ie.CauseRequestRejected, nil
+	}
+
+	var ies []*ie.IE
+
+	if offendingIE := dpErr.OffendingIE(); offendingIE != nil {
+		ies = append(ies, offendingIE)
+	}
+
+	if dpErr.Cause == ie.CauseRuleCreationModificationFailure {
+		if failedRuleID := dpErr.FailedRuleID(); failedRuleID != nil {
+			ies = append(ies, failedRuleID)
+		}
+	}
+
+	return dpErr.Cause, ies
+}
+
+// Order in which the operations of a transaction are applied, so that rules are never
+// referenced by a PDR before being installed, nor removed while still referenced.
+const (
+	phaseDeletePDRs = iota
+	phaseWriteRules
+	phaseWritePDRs
+	phaseDeleteRules
+)
+
+type transactionOp struct {
+	method   UpfMsgType
+	ruleType RuleType
+	ruleID   uint32
+	rule     fmt.Stringer
+	apply    func(ctx context.Context) error
+	revert   func(ctx context.Context) error
+}
+
+func (op transactionOp) phase() int {
+	switch {
+	case op.ruleType == RuleTypePDR && op.method == UpfMsgTypeDel:
+		return phaseDeletePDRs
+	case op.ruleType == RuleTypePDR:
+		return phaseWritePDRs
+	case op.method == UpfMsgTypeDel:
+		return phaseDeleteRules
+	default:
+		return phaseWriteRules
+	}
+}
+
+// Transaction is a batch of rule operations applied to the datapath as a unit. If any operation
+// fails, the operations already applied are reverted in reverse order.
+type Transaction struct {
+	dp  Datapath
+	ops []transactionOp
+}
+
+// NewTransaction creates an empty transaction on the given datapath.
+func NewTransaction(dp Datapath) *Transaction {
+	return &Transaction{dp: dp}
+}
+
+// Len returns the number of operations in the transaction.
+func (t *Transaction) Len() int {
+	return len(t.ops)
+}
+
+func (t *Transaction) add(op transactionOp) {
+	t.ops = append(t.ops, op)
+}
+
+func (t *Transaction) CreatePDR(p Pdr) {
+	t.add(transactionOp{
+		method: UpfMsgTypeAdd, ruleType: RuleTypePDR, ruleID: p.PdrID, rule: p,
+		apply:  func(ctx context.Context) error { return t.dp.CreatePDR(ctx, p) },
+		revert: func(ctx context.Context) error { return t.dp.DeletePDR(ctx, p) },
+	})
+}
+
+// ModifyPDR replaces old with updated. old is restored on rollback.
+func (t *Transaction) ModifyPDR(old, updated Pdr) {
+	t.add(transactionOp{
+		method: UpfMsgTypeMod, ruleType: RuleTypePDR, ruleID: updated.PdrID, rule: updated,
+		apply:  func(ctx context.Context) error { return t.dp.ModifyPDR(ctx, updated) },
+		revert: func(ctx context.Context) error { return t.dp.ModifyPDR(ctx, old) },
+	})
+}
+
+func (t *Transaction) DeletePDR(p Pdr) {
+	t.add(transactionOp{
+		method: UpfMsgTypeDel, ruleType: RuleTypePDR, ruleID: p.PdrID, rule: p,
+		apply:  func(ctx context.Context) error { return t.dp.DeletePDR(ctx, p) },
+		revert: func(ctx context.Context) error { return t.dp.CreatePDR(ctx, p) },
+	})
+}
+
+func (t *Transaction) CreateFAR(f Far) {
+	t.add(transactionOp{
+		method: UpfMsgTypeAdd, ruleType: RuleTypeFAR, ruleID: f.FarID, rule: f,
+		apply:  func(ctx context.Context) error { return t.dp.CreateFAR(ctx, f) },
+		revert: func(ctx context.Context) error { return t.dp.DeleteFAR(ctx, f) },
+	})
+}
+
+// ModifyFAR replaces old with updated. old is restored on rollback.
+func (t *Transaction) ModifyFAR(old, updated Far) {
+	t.add(transactionOp{
+		method: UpfMsgTypeMod, ruleType: RuleTypeFAR, ruleID: updated.FarID, rule: updated,
+		apply:  func(ctx context.Context) error { return t.dp.ModifyFAR(ctx, updated) },
+		revert: func(ctx context.Context) error { return t.dp.ModifyFAR(ctx, old) },
+	})
+}
+
+func (t *Transaction) DeleteFAR(f Far) {
+	t.add(transactionOp{
+		method: UpfMsgTypeDel, ruleType: RuleTypeFAR, ruleID: f.FarID, rule: f,
+		apply:  func(ctx context.Context) error { return t.dp.DeleteFAR(ctx, f) },
+		revert: func(ctx context.Context) error { return t.dp.CreateFAR(ctx, f) },
+	})
+}
+
+func (t *Transaction) CreateQER(q Qer) {
+	t.add(transactionOp{
+		method: UpfMsgTypeAdd, ruleType: RuleTypeQER, ruleID: q.QerID, rule: q,
+		apply:  func(ctx context.Context) error { return t.dp.CreateQER(ctx, q) },
+		revert: func(ctx context.Context) error { return t.dp.DeleteQER(ctx, q) },
+	})
+}
+
+// ModifyQER replaces old with updated. old is restored on rollback.
+func (t *Transaction) ModifyQER(old, updated Qer) {
+	t.add(transactionOp{
+		method: UpfMsgTypeMod, ruleType: RuleTypeQER, ruleID: updated.QerID, rule: updated,
+		apply:  func(ctx context.Context) error { return t.dp.ModifyQER(ctx, updated) },
+		revert: func(ctx context.Context) error { return t.dp.ModifyQER(ctx, old) },
+	})
+}
+
+func (t *Transaction) DeleteQER(q Qer) {
+	t.add(transactionOp{
+		method: UpfMsgTypeDel, ruleType: RuleTypeQER, ruleID: q.QerID, rule: q,
+		apply:  func(ctx context.Context) error { return t.dp.DeleteQER(ctx, q) },
+		revert: func(ctx context.Context) error { return t.dp.CreateQER(ctx, q) },
+	})
+}
+
+func (t *Transaction) CreateURR(u Urr) {
+	t.add(transactionOp{
+		method: UpfMsgTypeAdd, ruleType: RuleTypeURR, ruleID: u.UrrID, rule: &u,
+		apply:  func(ctx context.Context) error { return t.dp.CreateURR(ctx, u) },
+		revert: func(ctx context.Context) error { return t.dp.DeleteURR(ctx, u) },
+	})
+}
+
+// ModifyURR replaces old with updated. old is restored on rollback.
+func (t *Transaction) ModifyURR(old, updated Urr) {
+	t.add(transactionOp{
+		method: UpfMsgTypeMod, ruleType: RuleTypeURR, ruleID: updated.UrrID, rule: &updated,
+		apply:  func(ctx context.Context) error { return t.dp.ModifyURR(ctx, updated) },
+		revert: func(ctx context.Context) error { return t.dp.ModifyURR(ctx, old) },
+	})
+}
+
+func (t *Transaction) DeleteURR(u Urr) {
+	t.add(transactionOp{
+		method: UpfMsgTypeDel, ruleType: RuleTypeURR, ruleID: u.UrrID, rule: &u,
+		apply:  func(ctx context.Context) error { return t.dp.DeleteURR(ctx, u) },
+		revert: func(ctx context.Context) error { return t.dp.CreateURR(ctx, u) },
+	})
+}
+
+// CreateRules adds the creation of all given rules to the transaction.
+func (t *Transaction) CreateRules(rules PacketForwardingRules) {
+	for _, p := range rules.Pdrs {
+		t.CreatePDR(p)
+	}
+
+	for _, f := range rules.Fars {
+		t.CreateFAR(f)
+	}
+
+	for _, q := range rules.Qers {
+		t.CreateQER(q)
+	}
+
+	for _, u := range rules.Urrs {
+		t.CreateURR(u)
+	}
+}
+
+// DeleteRules adds the deletion of all given rules to the transaction.
+func (t *Transaction) DeleteRules(rules PacketForwardingRules) {
+	for _, p := range rules.Pdrs {
+		t.DeletePDR(p)
+	}
+
+	for _, f := range rules.Fars {
+		t.DeleteFAR(f)
+	}
+
+	for _, q := range rules.Qers {
+		t.DeleteQER(q)
+	}
+
+	for _, u := range rules.Urrs {
+		t.DeleteURR(u)
+	}
+}
+
+// ApplyDiff adds the operations turning the old rules into the updated ones: rules only in
+// updated are created, rules only in old are deleted and rules that changed are modified.
+func (t *Transaction) ApplyDiff(old, updated PacketForwardingRules) {
+	oldPdrs := make(map[uint32]Pdr, len(old.Pdrs))
+	for _, p := range old.Pdrs {
+		oldPdrs[p.PdrID] = p
+	}
+
+	for _, p := range updated.Pdrs {
+		if o, ok := oldPdrs[p.PdrID]; !ok {
+			t.CreatePDR(p)
+		} else if !reflect.DeepEqual(o, p) {
+			t.ModifyPDR(o, p)
+		}
+
+		delete(oldPdrs, p.PdrID)
+	}
+
+	for _, p := range old.Pdrs {
+		if _, ok := oldPdrs[p.PdrID]; ok {
+			t.DeletePDR(p)
+		}
+	}
+
+	oldFars := make(map[uint32]Far, len(old.Fars))
+	for _, f := range old.Fars {
+		oldFars[f.FarID] = f
+	}
+
+	for _, f := range updated.Fars {
+		if o, ok := oldFars[f.FarID]; !ok {
+			t.CreateFAR(f)
+		} else if !reflect.DeepEqual(o, f) {
+			t.ModifyFAR(o, f)
+		}
+
+		delete(oldFars, f.FarID)
+	}
+
+	for _, f := range old.Fars {
+		if _, ok := oldFars[f.FarID]; ok {
+			t.DeleteFAR(f)
+		}
+	}
+
+	oldQers := make(map[uint32]Qer, len(old.Qers))
+	for _, q := range old.Qers {
+		oldQers[q.QerID] = q
+	}
+
+	for _, q := range updated.Qers {
+		if o, ok := oldQers[q.QerID]; !ok {
+			t.CreateQER(q)
+		} else if o != q {
+			t.ModifyQER(o, q)
+		}
+
+		delete(oldQers, q.QerID)
+	}
+
+	for _, q := range old.Qers {
+		if _, ok := oldQers[q.QerID]; ok {
+			t.DeleteQER(q)
+		}
+	}
+
+	oldUrrs := make(map[uint32]Urr, len(old.Urrs))
+	for _, u := range old.Urrs {
+		oldUrrs[u.UrrID] = u
+	}
+
+	for _, u := range updated.Urrs {
+		if o, ok := oldUrrs[u.UrrID]; !ok {
+			t.CreateURR(u)
+		} else if o != u {
+			t.ModifyURR(o, u)
+		}
+
+		delete(oldUrrs, u.UrrID)
+	}
+
+	for _, u := range old.Urrs {
+		if _, ok := oldUrrs[u.UrrID]; ok {
+			t.DeleteURR(u)
+		}
+	}
+}
+
+// Commit applies all operations to the datapath. On failure, the operations already applied are
+// reverted and a *DatapathError identifying the failed rule is returned. The rollback is not
+// bound to ctx, so that it still runs if ctx expired.
+func (t *Transaction) Commit(ctx context.Context) error {
+	ops := make([]transactionOp, len(t.ops))
+	copy(ops, t.ops)
+
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].phase() < ops[j].phase()
+	})
+
+	for i, op := range ops {
+		log.Debugf("%s %s", op.method, op.rule)
+
+		if err := op.apply(ctx); err != nil {
+			t.rollback(ops[:i])
+			return newDatapathError(op, err)
+		}
+	}
+
+	return nil
+}
+
+func (t *Transaction) rollback(applied []transactionOp) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	for i := len(applied) - 1; i >= 0; i-- {
+		op := applied[i]
+
+		if err := op.revert(ctx); err != nil {
+			// Nothing else we can do, datapath and control plane state have diverged.
+			log.Errorf("Failed to roll back %s %s %d: %v", op.method, op.ruleType, op.ruleID, err)
+		}
+	}
+}
+
+func newDatapathError(op transactionOp, err error) *DatapathError {
+	dpErr := &DatapathError{
+		Op:       op.method,
+		RuleType: op.ruleType,
+		RuleID:   op.ruleID,
+		Cause:    ie.CauseRuleCreationModificationFailure,
+		Err:      err,
+	}
+
+	var cause *DatapathError
+	if errors.As(err, &cause) {
+		if cause.Cause != 0 {
+			dpErr.Cause = cause.Cause
+		}
+
+		dpErr.Err = cause.Err
+	}
+
+	return dpErr
+}
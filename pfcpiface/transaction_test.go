@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+)
+
+func causeOf(t *testing.T, err error) uint8 {
+	var dpErr *DatapathError
+
+	require.True(t, errors.As(err, &dpErr), "expected a DatapathError, got %v", err)
+
+	return dpErr.Cause
+}
+
+func callSummary(calls []RecordedCall) []string {
+	summary := make([]string, 0, len(calls))
+	for _, c := range calls {
+		summary = append(summary, c.Method.String()+" "+c.RuleType.String())
+	}
+
+	return summary
+}
+
+func TestTransaction_Order(t *testing.T) {
+	dp := NewRecordingDatapath()
+	session := newTestSession(1)
+
+	tx := NewTransaction(dp)
+	tx.CreateRules(session.PacketForwardingRules)
+	require.Equal(t, 6, tx.Len())
+	require.NoError(t, tx.Commit(context.Background()))
+
+	// Rules referenced by PDRs are installed first
+	require.Equal(t, []string{
+		"add FAR", "add FAR", "add QER", "add URR", "add PDR", "add PDR",
+	}, callSummary(dp.Calls()))
+
+	dp.Reset()
+
+	tx = NewTransaction(dp)
+	tx.DeleteRules(session.PacketForwardingRules)
+	require.NoError(t, tx.Commit(context.Background()))
+
+	// and removed last
+	require.Equal(t, []string{
+		"delete PDR", "delete PDR", "delete FAR", "delete FAR", "delete QER", "delete URR",
+	}, callSummary(dp.Calls()))
+	require.Equal(t, PacketForwardingRules{}, dp.Rules())
+}
+
+func TestTransaction_Rollback(t *testing.T) {
+	t.Run("failed create", func(t *testing.T) {
+		dp := NewRecordingDatapath()
+		session := newTestSession(1)
+
+		dp.InjectError(UpfMsgTypeAdd, RuleTypePDR, ie.CauseNoResourcesAvailable)
+
+		tx := NewTransaction(dp)
+		tx.CreateRules(session.PacketForwardingRules)
+
+		err := tx.Commit(context.Background())
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrWriteToDatapath))
+		require.Equal(t, PacketForwardingRules{}, dp.Rules(), "applied rules must be rolled back")
+	})
+
+	t.Run("failed modify", func(t *testing.T) {
+		dp := NewRecordingDatapath()
+		session := newTestSession(1)
+
+		tx := NewTransaction(dp)
+		tx.CreateRules(session.PacketForwardingRules)
+		require.NoError(t, tx.Commit(context.Background()))
+
+		updatedFar := session.Fars[1]
+		updatedFar.TunnelTEID = 0x30
+		updatedQer := session.Qers[0]
+		updatedQer.UlMbr = 5000
+
+		dp.InjectError(UpfMsgTypeMod, RuleTypeQER, ie.CauseRuleCreationModificationFailure)
+
+		tx = NewTransaction(dp)
+		tx.ModifyFAR(session.Fars[1], updatedFar)
+		tx.ModifyQER(session.Qers[0], updatedQer)
+		require.Error(t, tx.Commit(context.Background()))
+
+		require.Equal(t, session.Fars, dp.Rules().Fars, "modified FAR must be restored")
+		require.Equal(t, session.Qers, dp.Rules().Qers)
+	})
+
+	t.Run("failed delete", func(t *testing.T) {
+		dp := NewRecordingDatapath()
+		session := newTestSession(1)
+
+		tx := NewTransaction(dp)
+		tx.CreateRules(session.PacketForwardingRules)
+		require.NoError(t, tx.Commit(context.Background()))
+
+		dp.InjectError(UpfMsgTypeDel, RuleTypeURR, ie.CauseSystemFailure)
+
+		tx = NewTransaction(dp)
+		tx.DeleteRules(session.PacketForwardingRules)
+		require.Error(t, tx.Commit(context.Background()))
+
+		rules := dp.Rules()
+		require.ElementsMatch(t, session.Pdrs, rules.Pdrs, "deleted PDRs must be restored")
+		require.ElementsMatch(t, session.Fars, rules.Fars)
+		require.ElementsMatch(t, session.Qers, rules.Qers)
+		require.ElementsMatch(t, session.Urrs, rules.Urrs)
+	})
+}
+
+func TestTransaction_Error(t *testing.T) {
+	dp := NewRecordingDatapath()
+	session := newTestSession(1)
+
+	dp.InjectError(UpfMsgTypeAdd, RuleTypeFAR, ie.CauseRuleCreationModificationFailure)
+
+	tx := NewTransaction(dp)
+	tx.CreateFAR(session.Fars[1])
+
+	err := tx.Commit(context.Background())
+
+	var dpErr *DatapathError
+
+	require.True(t, errors.As(err, &dpErr))
+	require.Equal(t, UpfMsgTypeAdd, dpErr.Op)
+	require.Equal(t, RuleTypeFAR, dpErr.RuleType)
+	require.Equal(t, uint32(2), dpErr.RuleID)
+
+	cause, ies := datapathErrorReply(err)
+	require.Equal(t, ie.CauseRuleCreationModificationFailure, cause)
+	require.Len(t, ies, 2)
+
+	offendingIE, err := ies[0].OffendingIE()
+	require.NoError(t, err)
+	require.Equal(t, ie.CreateFAR, offendingIE)
+
+	ruleType, err := ies[1].RuleIDType()
+	require.NoError(t, err)
+	require.Equal(t, ie.RuleIDTypeFAR, ruleType)
+
+	ruleID, err := ies[1].FailedRuleID()
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), ruleID)
+}
+
+func Test_datapathErrorReply(t *testing.T) {
+	cause, ies := datapathErrorReply(errors.New("not a datapath error"))
+	require.Equal(t, ie.CauseRequestRejected, cause)
+	require.Empty(t, ies)
+
+	cause, ies = datapathErrorReply(&DatapathError{
+		Op: UpfMsgTypeDel, RuleType: RuleTypeURR, RuleID: 3, Cause: ie.CauseSystemFailure,
+	})
+	require.Equal(t, ie.CauseSystemFailure, cause)
+	require.Len(t, ies, 1, "Failed Rule ID is only added for rule creation/modification failures")
+
+	offendingIE, err := ies[0].OffendingIE()
+	require.NoError(t, err)
+	require.Equal(t, ie.RemoveURR, offendingIE)
+}
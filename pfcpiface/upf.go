@@ -4,8 +4,9 @@
 package pfcpiface
 
 import (
-	"math"
+	"context"
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/Showmax/go-fqdn"
@@ -40,25 +41,45 @@ type Upf struct {
 	enableFlowMeasure bool
 	accessIface       string
 	coreIface         string
-	ippoolCidr        string
 	AccessIP          net.IP
 	CoreIP            net.IP
 	NodeID            string
+	// AccessIP6 and CoreIP6 are the IPv6 addresses of the N3 and N9 interfaces, nil if none
+	AccessIP6 net.IP
+	CoreIP6   net.IP
 
-	ippool        *IPPool
-	teidAllocator *IDAllocator
+	// networkInstances are the named network instances, with their own addresses
+	networkInstances networkInstances
+
+	ippools   *ipPools
+	teidPools *teidPools
+
+	sessionStoreType string
+	sessionStorePath string
 
 	peers            []string
 	dnn              string
 	ReportNotifyChan chan uint64
-	sliceInfo        *SliceInfo
-	readTimeout      time.Duration
+	// UsageReportChan receives the URR counters reported by the datapath.
+	UsageReportChan chan UsageEvent
+	// ddnNotifier rate limits the downlink data notifications raised by the datapath
+	ddnNotifier *downlinkDataNotifier
+	// DatapathStateChan receives datapath liveness transitions, if the datapath reports them.
+	DatapathStateChan chan DatapathState
+	sliceInfo         *SliceInfo
+	readTimeout       time.Duration
 
 	Datapath
 	maxReqRetries uint8
 	respTimeout   time.Duration
 	enableHBTimer bool
 	hbInterval    time.Duration
+	// draining is set while the UPF is drained, it is accessed atomically
+	draining uint32
+	// staleSessionGrace is how long the sessions of the previous incarnation of a peer are kept
+	staleSessionGrace time.Duration
+	// sessionWorkers is the number of workers handling the session messages of each peer
+	sessionWorkers int
 }
 
 // to be replaced with go-pfcp structs
@@ -80,6 +101,28 @@ func (u *Upf) isConnected() bool {
 	return u.Datapath.IsConnected(&u.AccessIP)
 }
 
+// isDraining checks if the UPF is drained, i.e. rejects new associations and sessions.
+func (u *Upf) isDraining() bool {
+	return atomic.LoadUint32(&u.draining) == 1
+}
+
+func (u *Upf) setDraining(draining bool) {
+	var v uint32
+	if draining {
+		v = 1
+	}
+
+	atomic.StoreUint32(&u.draining, v)
+}
+
+// commitTransaction applies the rules of tx to the datapath, bounded by Timeout.
+func (u *Upf) commitTransaction(tx *Transaction) error {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	return tx.Commit(ctx)
+}
+
 func (u *Upf) addSliceInfo(sliceInfo *SliceInfo) error {
 	if sliceInfo == nil {
 		return ErrInvalidArgument("sliceInfo", sliceInfo)
@@ -87,6 +130,10 @@ func (u *Upf) addSliceInfo(sliceInfo *SliceInfo) error {
 
 	u.sliceInfo = sliceInfo
 
+	if u.ippools != nil {
+		u.ippools.setSliceResources(sliceInfo.ueResList)
+	}
+
 	return u.Datapath.AddSliceInfo(sliceInfo)
 }
 
@@ -120,15 +167,19 @@ func NewUPF(conf *Conf, fp Datapath) *Upf {
 		enableFlowMeasure: conf.EnableFlowMeasure,
 		accessIface:       conf.AccessIface.IfName,
 		coreIface:         conf.CoreIface.IfName,
-		ippoolCidr:        conf.CPIface.UEIPPool,
 		NodeID:            nodeID,
 		Datapath:          fp,
 		dnn:               conf.CPIface.Dnn,
 		peers:             conf.CPIface.Peers,
 		ReportNotifyChan:  make(chan uint64, 1024),
+		UsageReportChan:   make(chan UsageEvent, 1024),
+		DatapathStateChan: make(chan DatapathState, 16),
 		maxReqRetries:     conf.MaxReqRetries,
 		enableHBTimer:     conf.EnableHBTimer,
 		readTimeout:       time.Second * time.Duration(conf.ReadTimeout),
+		sessionWorkers:    int(conf.SessionWorkers),
+		sessionStoreType:  conf.SessionStore.Type,
+		sessionStorePath:  conf.SessionStore.Path,
 	}
 
 	if len(conf.CPIface.Peers) > 0 {
@@ -152,6 +203,24 @@ func NewUPF(conf *Conf, fp Datapath) *Upf {
 			log.Error(err)
 			return nil
 		}
+
+		u.AccessIP6, err = GetUnicastIPv6AddressFromInterface(conf.AccessIface.IfName)
+		if err != nil {
+			log.Error(err)
+			return nil
+		}
+
+		u.CoreIP6, err = GetUnicastIPv6AddressFromInterface(conf.CoreIface.IfName)
+		if err != nil {
+			log.Error(err)
+			return nil
+		}
+	}
+
+	u.networkInstances, err = newNetworkInstances(conf.NetworkInstances, !conf.EnableP4rt)
+	if err != nil {
+		log.Error(err)
+		return nil
 	}
 
 	u.respTimeout, err = time.ParseDuration(conf.RespTimeout)
@@ -169,13 +238,37 @@ func NewUPF(conf *Conf, fp Datapath) *Upf {
 	}
 
 	if u.enableUeIPAlloc {
-		u.ippool, err = NewIPPool(u.ippoolCidr)
+		u.ippools, err = newUEIPPools(conf.CPIface)
 		if err != nil {
 			log.Fatal("ip pool init failed", err)
 		}
 	}
 
-	u.teidAllocator = NewIDAllocator(1, math.MaxUint32)
+	u.teidPools, err = newTEIDPools(conf.CPIface, u.AccessIP, u.AccessIP6, u.CoreIP, u.CoreIP6)
+	if err != nil {
+		log.Fatal("TEID pools init failed", err)
+	}
+
+	if err = u.teidPools.addNetworkInstances(u.networkInstances); err != nil {
+		log.Fatal("TEID pools init failed", err)
+	}
+
+	ddnInterval := ddnIntervalDefault
+	if conf.DDNInterval != "" {
+		ddnInterval, err = time.ParseDuration(conf.DDNInterval)
+		if err != nil {
+			log.Fatal("Unable to parse ddn_notification_interval")
+		}
+	}
+
+	u.ddnNotifier = NewDownlinkDataNotifier(u.ReportNotifyChan, ddnInterval)
+
+	if conf.StaleSessionGrace != "" {
+		u.staleSessionGrace, err = time.ParseDuration(conf.StaleSessionGrace)
+		if err != nil {
+			log.Fatal("Unable to parse stale_session_grace_period")
+		}
+	}
 
 	u.Datapath.SetUpfInfo(u, conf)
 
@@ -0,0 +1,754 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-pfcp/ie"
+	"github.com/wmnsk/go-pfcp/message"
+	"go.uber.org/zap"
+)
+
+// Usage Report Trigger flags, see TS 29.244 8.2.41. Like ReportTrigger.Flags, octet 5 is the
+// most significant byte.
+const (
+	usageReportTriggerIMMER uint16 = 0x8000
+	usageReportTriggerQUHTI uint16 = 0x0800
+	usageReportTriggerTIMTH uint16 = 0x0400
+	usageReportTriggerVOLTH uint16 = 0x0200
+	usageReportTriggerPERIO uint16 = 0x0100
+	usageReportTriggerMONIT uint16 = 0x0010
+	usageReportTriggerTERMR uint16 = 0x0008
+	usageReportTriggerTIMQU uint16 = 0x0002
+	usageReportTriggerVOLQU uint16 = 0x0001
+)
+
+// Usage Information flags, see TS 29.244 8.2.79.
+const (
+	usageInformationBEF = 0x1
+	usageInformationAFT = 0x2
+)
+
+// Volume flags of VolumeData, see TS 29.244 8.2.13.
+const (
+	volumeFlagTOVOL = 0x1
+	volumeFlagULVOL = 0x2
+	volumeFlagDLVOL = 0x4
+)
+
+// volumeMeasurementFlags sets all volumes and packet counts of a Volume Measurement IE.
+const volumeMeasurementFlags = 0x3f
+
+// UsageCounters are the traffic counters of a URR, accumulated by the datapath since the URR was
+// created.
+type UsageCounters struct {
+	UplinkPackets   uint64
+	DownlinkPackets uint64
+	UplinkBytes     uint64
+	DownlinkBytes   uint64
+}
+
+// UsageReader is implemented by datapaths able to read the counters of a URR on demand. The
+// counters of other datapaths are the last ones they reported with Upf.ReportUsage.
+type UsageReader interface {
+	ReadUsage(ctx context.Context, urr Urr) (UsageCounters, error)
+}
+
+// UsageEvent carries the counters of a URR reported by the datapath.
+type UsageEvent struct {
+	// FseID is the local SEID of the session of the URR
+	FseID    uint64
+	UrrID    uint32
+	Counters UsageCounters
+}
+
+func (c UsageCounters) TotalBytes() uint64 {
+	return c.UplinkBytes + c.DownlinkBytes
+}
+
+func (c UsageCounters) TotalPackets() uint64 {
+	return c.UplinkPackets + c.DownlinkPackets
+}
+
+// sub returns the counters accumulated since base. Counters that went backwards, e.g. because
+// the datapath restarted, count as zero.
+func (c UsageCounters) sub(base UsageCounters) UsageCounters {
+	sub := func(x, y uint64) uint64 {
+		if x < y {
+			return 0
+		}
+
+		return x - y
+	}
+
+	return UsageCounters{
+		UplinkPackets:   sub(c.UplinkPackets, base.UplinkPackets),
+		DownlinkPackets: sub(c.DownlinkPackets, base.DownlinkPackets),
+		UplinkBytes:     sub(c.UplinkBytes, base.UplinkBytes),
+		DownlinkBytes:   sub(c.DownlinkBytes, base.DownlinkBytes),
+	}
+}
+
+// reached checks if the volumes used reach any of the volumes set in limit.
+func (limit VolumeData) reached(used UsageCounters) bool {
+	return (limit.Flags&volumeFlagTOVOL != 0 && limit.TotalVol != 0 && used.TotalBytes() >= limit.TotalVol) ||
+		(limit.Flags&volumeFlagULVOL != 0 && limit.UplinkVol != 0 && used.UplinkBytes >= limit.UplinkVol) ||
+		(limit.Flags&volumeFlagDLVOL != 0 && limit.DownlinkVol != 0 && used.DownlinkBytes >= limit.DownlinkVol)
+}
+
+// usageReport is a usage report of a URR, independent of the message it is sent in.
+type usageReport struct {
+	urrID   uint32
+	seqN    uint32
+	trigger uint16
+	// usage and active time measured between startTime and endTime
+	usage     UsageCounters
+	duration  time.Duration
+	startTime time.Time
+	endTime   time.Time
+	// measuresDuration is set if the URR measures duration, i.e. duration is reported
+	measuresDuration bool
+	// usageInformation flags the measurements before and after the monitoring time
+	usageInformation uint8
+	// queryURRReference is set if the report answers a Query URR with a reference
+	queryURRReference *uint32
+}
+
+func newUsageReportTrigger(trigger uint16) *ie.IE {
+	return ie.NewUsageReportTrigger(uint8(trigger>>8), uint8(trigger), 0)
+}
+
+// IEs returns the IEs of the usage report, to be grouped in the Usage Report IE of a message.
+func (r *usageReport) IEs() []*ie.IE {
+	ies := []*ie.IE{
+		ie.NewURRID(r.urrID),
+		ie.NewURSEQN(r.seqN),
+		newUsageReportTrigger(r.trigger),
+		ie.NewStartTime(r.startTime),
+		ie.NewEndTime(r.endTime),
+		ie.NewVolumeMeasurement(volumeMeasurementFlags,
+			r.usage.TotalBytes(), r.usage.UplinkBytes, r.usage.DownlinkBytes,
+			r.usage.TotalPackets(), r.usage.UplinkPackets, r.usage.DownlinkPackets),
+	}
+
+	if r.measuresDuration {
+		ies = append(ies, ie.NewDurationMeasurement(r.duration))
+	}
+
+	if r.usageInformation != 0 {
+		ies = append(ies, ie.NewUsageInformation(int(r.usageInformation&usageInformationBEF),
+			int(r.usageInformation&usageInformationAFT)>>1, 0, 0))
+	}
+
+	if r.queryURRReference != nil {
+		ies = append(ies, ie.NewQueryURRReference(*r.queryURRReference))
+	}
+
+	return ies
+}
+
+// urrMeasurement is the state of the measurement of a URR.
+type urrMeasurement struct {
+	// urr is the URR as last provisioned
+	urr Urr
+	// latest are the last counters reported by the datapath
+	latest UsageCounters
+	// reported are the counters at the last usage report, the measurement restarts from them
+	reported UsageCounters
+	// startTime is the start of the current measurement
+	startTime time.Time
+	// quotaStart are the counters when the volume quota was provisioned
+	quotaStart    UsageCounters
+	quotaReported bool
+	// activeTime is the time traffic was active during the current measurement, accrued until
+	// accruedAt. quotaActiveTime is the active time since the time quota was provisioned.
+	activeTime        time.Duration
+	quotaActiveTime   time.Duration
+	accruedAt         time.Time
+	timeQuotaReported bool
+	// lastTraffic is the last time the counters increased, zero until they do
+	lastTraffic time.Time
+	// idleSince is the last time the counters increased, or the time the URR was created
+	idleSince    time.Time
+	idleReported bool
+	// periodStart is the start of the current measurement period
+	periodStart time.Time
+	// monitoringTime is the monitoring time not reached yet, if any. Once it is, the measurement
+	// before it is kept in before, until it is reported along with the one after it.
+	monitoringTime time.Time
+	before         *usageReport
+	// seqN is the UR-SEQN of the next usage report
+	seqN uint32
+}
+
+func newURRMeasurement(urr Urr, now time.Time) *urrMeasurement {
+	m := &urrMeasurement{
+		startTime:   now,
+		accruedAt:   now,
+		idleSince:   now,
+		periodStart: now,
+	}
+
+	m.provision(urr, now)
+
+	return m
+}
+
+// provision updates the URR being measured, e.g. when the SMF modifies it.
+func (m *urrMeasurement) provision(urr Urr, now time.Time) {
+	m.accrue(now)
+
+	// New quotas are enforced from the usage known when they are provisioned
+	if urr.VolQuota != m.urr.VolQuota {
+		m.quotaStart = m.latest
+		m.quotaReported = false
+	}
+
+	if urr.TimeQuota != m.urr.TimeQuota {
+		m.quotaActiveTime = 0
+		m.timeQuotaReported = false
+	}
+
+	if urr.MeasurementPeriod != m.urr.MeasurementPeriod {
+		m.periodStart = now
+	}
+
+	if !urr.MonitoringTime.Equal(m.urr.MonitoringTime) {
+		m.monitoringTime = time.Time{}
+
+		if urr.MonitoringTime.After(now) {
+			m.monitoringTime = urr.MonitoringTime
+		}
+	}
+
+	m.urr = urr
+}
+
+// accruing checks if the active time is accruing at now. Without an inactivity detection time,
+// it is accruing from the start of the measurement. Otherwise it is accruing from the first
+// traffic, until no traffic is seen for the inactivity detection time.
+func (m *urrMeasurement) accruing(now time.Time) bool {
+	inactivityTime := m.urr.InactivityDetectionTime
+
+	return inactivityTime == 0 || (!m.lastTraffic.IsZero() && now.Before(m.lastTraffic.Add(inactivityTime)))
+}
+
+// accrue adds the active time until now.
+func (m *urrMeasurement) accrue(now time.Time) {
+	if !now.After(m.accruedAt) {
+		return
+	}
+
+	end := now
+
+	if inactivityTime := m.urr.InactivityDetectionTime; inactivityTime > 0 {
+		end = m.accruedAt
+
+		if inactive := m.lastTraffic.Add(inactivityTime); !m.lastTraffic.IsZero() && inactive.After(end) {
+			end = inactive
+		}
+
+		if end.After(now) {
+			end = now
+		}
+	}
+
+	active := end.Sub(m.accruedAt)
+	m.activeTime += active
+	m.quotaActiveTime += active
+	m.accruedAt = now
+}
+
+// record records the counters reported by the datapath.
+func (m *urrMeasurement) record(counters UsageCounters, now time.Time) {
+	m.accrue(now)
+
+	if counters.TotalPackets() > m.latest.TotalPackets() || counters.TotalBytes() > m.latest.TotalBytes() {
+		m.lastTraffic = now
+		m.idleSince = now
+		m.idleReported = false
+	}
+
+	m.latest = counters
+}
+
+// volumeTriggers returns the volume based triggers reached by the latest counters.
+func (m *urrMeasurement) volumeTriggers() uint16 {
+	var trigger uint16
+
+	if m.urr.Trigger.isVOLTHSet() && m.urr.VolThreshold.reached(m.latest.sub(m.reported)) {
+		trigger |= usageReportTriggerVOLTH
+	}
+
+	if m.urr.Trigger.isVOLQUSet() && !m.quotaReported && m.urr.VolQuota.reached(m.latest.sub(m.quotaStart)) {
+		trigger |= usageReportTriggerVOLQU
+		m.quotaReported = true
+	}
+
+	return trigger
+}
+
+// timeTriggers accrues the active time until now, splits the measurement if the monitoring time
+// is reached, and returns the time based triggers reached.
+func (m *urrMeasurement) timeTriggers(now time.Time) uint16 {
+	m.accrue(now)
+
+	// The measurement is split once at a time, a later monitoring time waits for the report
+	if !m.monitoringTime.IsZero() && !now.Before(m.monitoringTime) && m.before == nil {
+		m.before = m.restart(m.monitoringTime)
+		m.before.usageInformation = usageInformationBEF
+		m.monitoringTime = time.Time{}
+	}
+
+	var trigger uint16
+
+	urr := &m.urr
+
+	if period := urr.MeasurementPeriod; urr.Trigger.isPERIOSet() && period > 0 &&
+		!now.Before(m.periodStart.Add(period)) {
+		trigger |= usageReportTriggerPERIO
+		// Periods stay aligned on the first one, even if reports are late
+		m.periodStart = m.periodStart.Add(now.Sub(m.periodStart) / period * period)
+	}
+
+	if urr.Trigger.isTIMTHSet() && urr.TimeThreshold > 0 && m.activeTime >= urr.TimeThreshold {
+		trigger |= usageReportTriggerTIMTH
+	}
+
+	if urr.Trigger.isTIMQUSet() && urr.TimeQuota > 0 && !m.timeQuotaReported &&
+		m.quotaActiveTime >= urr.TimeQuota {
+		trigger |= usageReportTriggerTIMQU
+		m.timeQuotaReported = true
+	}
+
+	if urr.Trigger.isQUHTISet() && urr.QuotaHoldingTime > 0 && !m.idleReported &&
+		!now.Before(m.idleSince.Add(urr.QuotaHoldingTime)) {
+		trigger |= usageReportTriggerQUHTI
+		m.idleReported = true
+	}
+
+	return trigger
+}
+
+// deadline returns the next time a time based trigger of the URR may be reached, zero if none
+// can be.
+func (m *urrMeasurement) deadline(now time.Time) time.Time {
+	var next time.Time
+
+	earliest := func(t time.Time) {
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+
+	urr := &m.urr
+
+	if !m.monitoringTime.IsZero() && m.before == nil {
+		earliest(m.monitoringTime)
+	}
+
+	if urr.Trigger.isPERIOSet() && urr.MeasurementPeriod > 0 {
+		earliest(m.periodStart.Add(urr.MeasurementPeriod))
+	}
+
+	// Active time thresholds are reached at the earliest if traffic stays active
+	if m.accruing(now) {
+		if urr.Trigger.isTIMTHSet() && urr.TimeThreshold > 0 {
+			earliest(now.Add(urr.TimeThreshold - m.activeTime))
+		}
+
+		if urr.Trigger.isTIMQUSet() && urr.TimeQuota > 0 && !m.timeQuotaReported {
+			earliest(now.Add(urr.TimeQuota - m.quotaActiveTime))
+		}
+	}
+
+	if urr.Trigger.isQUHTISet() && urr.QuotaHoldingTime > 0 && !m.idleReported {
+		earliest(m.idleSince.Add(urr.QuotaHoldingTime))
+	}
+
+	return next
+}
+
+// restart returns the usage of the current measurement, without UR-SEQN nor trigger, and starts
+// a new one at now.
+func (m *urrMeasurement) restart(now time.Time) *usageReport {
+	r := &usageReport{
+		urrID:            m.urr.UrrID,
+		usage:            m.latest.sub(m.reported),
+		duration:         m.activeTime,
+		startTime:        m.startTime,
+		endTime:          now,
+		measuresDuration: m.urr.measuresDuration(),
+	}
+
+	m.reported = m.latest
+	m.startTime = now
+	m.activeTime = 0
+
+	return r
+}
+
+// report returns the usage reports of the current measurement, and starts a new one. The
+// measurement is reported in two usage reports if it was split at the monitoring time.
+func (m *urrMeasurement) report(trigger uint16, now time.Time) []*usageReport {
+	reports := make([]*usageReport, 0, 2)
+
+	if m.before != nil {
+		reports = append(reports, m.before)
+		m.before = nil
+	}
+
+	r := m.restart(now)
+	if len(reports) > 0 {
+		r.usageInformation = usageInformationAFT
+	}
+
+	reports = append(reports, r)
+
+	for _, r := range reports {
+		r.seqN = m.seqN
+		r.trigger = trigger
+		m.seqN++
+	}
+
+	return reports
+}
+
+// sessionUsage is the state of the measurements of the URRs of a session.
+type sessionUsage struct {
+	urrs map[uint32]*urrMeasurement
+	// timer fires at the next deadline of the time based triggers of the URRs
+	timer *time.Timer
+}
+
+// usageTracker tracks the measurements of the URRs of the sessions of a PFCPConn. It is safe for
+// concurrent use, as counters are reported by the datapath while PFCP messages are handled.
+type usageTracker struct {
+	mu       sync.Mutex
+	sessions map[uint64]*sessionUsage
+	// onTimer is passed the usage reports of session seid triggered by its timer. Timers are
+	// not started if it is nil.
+	onTimer func(seid uint64, reports []*usageReport)
+}
+
+func newUsageTracker(onTimer func(seid uint64, reports []*usageReport)) *usageTracker {
+	return &usageTracker{
+		sessions: make(map[uint64]*sessionUsage),
+		onTimer:  onTimer,
+	}
+}
+
+// sync starts measuring the URRs of session seid that are not measured yet, updates the ones
+// that are, and stops measuring the URRs it no longer has.
+func (t *usageTracker) sync(seid uint64, urrs []Urr, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.sessions[seid]
+	if !ok && len(urrs) == 0 {
+		return
+	}
+
+	if !ok {
+		s = &sessionUsage{urrs: make(map[uint32]*urrMeasurement)}
+		t.sessions[seid] = s
+	}
+
+	current := make(map[uint32]bool, len(urrs))
+
+	for _, urr := range urrs {
+		current[urr.UrrID] = true
+
+		if m, ok := s.urrs[urr.UrrID]; ok {
+			m.provision(urr, now)
+		} else {
+			s.urrs[urr.UrrID] = newURRMeasurement(urr, now)
+		}
+	}
+
+	for urrID := range s.urrs {
+		if !current[urrID] {
+			delete(s.urrs, urrID)
+		}
+	}
+
+	if len(s.urrs) == 0 {
+		s.stopTimer()
+		delete(t.sessions, seid)
+
+		return
+	}
+
+	t.schedule(seid, s, now)
+}
+
+// forget stops measuring the URRs of session seid.
+func (t *usageTracker) forget(seid uint64) {
+	t.sync(seid, nil, time.Time{})
+}
+
+// stop stops the timers of all sessions, e.g. when the PFCPConn shuts down.
+func (t *usageTracker) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range t.sessions {
+		s.stopTimer()
+	}
+}
+
+// measurement returns the measurement of urr, started if needed. It must be called with the lock
+// held.
+func (t *usageTracker) measurement(seid uint64, urr Urr, now time.Time) (*sessionUsage, *urrMeasurement) {
+	s, ok := t.sessions[seid]
+	if !ok {
+		s = &sessionUsage{urrs: make(map[uint32]*urrMeasurement)}
+		t.sessions[seid] = s
+	}
+
+	m, ok := s.urrs[urr.UrrID]
+	if !ok {
+		// URRs of sessions recovered after a restart are measured from their first counters
+		m = newURRMeasurement(urr, now)
+		s.urrs[urr.UrrID] = m
+	}
+
+	m.provision(urr, now)
+
+	return s, m
+}
+
+// latest returns the last counters recorded for urr.
+func (t *usageTracker) latest(seid uint64, urrID uint32) UsageCounters {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if s, ok := t.sessions[seid]; ok {
+		if m, ok := s.urrs[urrID]; ok {
+			return m.latest
+		}
+	}
+
+	return UsageCounters{}
+}
+
+// update records the counters of urr, and returns the usage reports they trigger, if any.
+func (t *usageTracker) update(seid uint64, urr Urr, counters UsageCounters, now time.Time) []*usageReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, m := t.measurement(seid, urr, now)
+	m.record(counters, now)
+
+	var reports []*usageReport
+
+	if trigger := m.volumeTriggers() | m.timeTriggers(now); trigger != 0 {
+		reports = m.report(trigger, now)
+	}
+
+	t.schedule(seid, s, now)
+
+	return reports
+}
+
+// reportNow records the counters of urr, and returns its usage reports with trigger, e.g. for a
+// query of the SMF or the removal of the URR.
+func (t *usageTracker) reportNow(seid uint64, urr Urr, counters UsageCounters, trigger uint16,
+	now time.Time) []*usageReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, m := t.measurement(seid, urr, now)
+	m.record(counters, now)
+
+	reports := m.report(trigger|m.timeTriggers(now), now)
+
+	t.schedule(seid, s, now)
+
+	return reports
+}
+
+// expire returns the usage reports of session seid triggered by time at now.
+func (t *usageTracker) expire(seid uint64, now time.Time) []*usageReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.sessions[seid]
+	if !ok {
+		return nil
+	}
+
+	var reports []*usageReport
+
+	for _, m := range s.urrs {
+		if trigger := m.timeTriggers(now); trigger != 0 {
+			reports = append(reports, m.report(trigger, now)...)
+		}
+	}
+
+	t.schedule(seid, s, now)
+
+	return reports
+}
+
+// schedule sets the timer of session s to its next deadline. It must be called with the lock
+// held.
+func (t *usageTracker) schedule(seid uint64, s *sessionUsage, now time.Time) {
+	if t.onTimer == nil {
+		return
+	}
+
+	var next time.Time
+
+	for _, m := range s.urrs {
+		if deadline := m.deadline(now); !deadline.IsZero() && (next.IsZero() || deadline.Before(next)) {
+			next = deadline
+		}
+	}
+
+	s.stopTimer()
+
+	if next.IsZero() {
+		return
+	}
+
+	s.timer = time.AfterFunc(next.Sub(now), func() {
+		if reports := t.expire(seid, time.Now()); len(reports) > 0 {
+			t.onTimer(seid, reports)
+		}
+	})
+}
+
+func (s *sessionUsage) stopTimer() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+// ReportUsage reports the counters of URR urrID of the session with local SEID fseid. Datapaths
+// call it whenever they refresh the counters of a URR: usage reports are sent to the PFCP peer
+// owning the session when the thresholds or quotas of the URR are reached.
+func (u *Upf) ReportUsage(fseid uint64, urrID uint32, counters UsageCounters) {
+	// non-blocking write to channel, the datapath must not wait for the PFCP agent
+	select {
+	case u.UsageReportChan <- UsageEvent{FseID: fseid, UrrID: urrID, Counters: counters}:
+	default:
+		log.Warnw("Usage report channel full, dropping counters", zap.Uint64("F-SEID", fseid),
+			zap.Uint32("URR ID", urrID))
+	}
+}
+
+// readUsage returns the current counters of the URRs of session, indexed by URR ID.
+func (pConn *PFCPConn) readUsage(seid uint64, urrs []Urr) map[uint32]UsageCounters {
+	counters := make(map[uint32]UsageCounters, len(urrs))
+	reader, canRead := pConn.upf.Datapath.(UsageReader)
+
+	for _, urr := range urrs {
+		if canRead {
+			ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+			c, err := reader.ReadUsage(ctx, urr)
+
+			cancel()
+
+			if err == nil {
+				counters[urr.UrrID] = c
+				continue
+			}
+
+			log.Warnw("Failed to read URR counters, reporting the last known ones",
+				zap.Uint64("F-SEID", seid), zap.Uint32("URR ID", urr.UrrID), zap.Error(err))
+		}
+
+		counters[urr.UrrID] = pConn.usage.latest(seid, urr.UrrID)
+	}
+
+	return counters
+}
+
+// usageReportsNow returns the usage reports with trigger of urrs, from counters returned by
+// readUsage.
+func (pConn *PFCPConn) usageReportsNow(seid uint64, urrs []Urr, counters map[uint32]UsageCounters,
+	trigger uint16) []*usageReport {
+	now := time.Now()
+	reports := make([]*usageReport, 0, len(urrs))
+
+	for _, urr := range urrs {
+		reports = append(reports, pConn.usage.reportNow(seid, urr, counters[urr.UrrID], trigger, now)...)
+	}
+
+	return reports
+}
+
+// handleUsageEvent checks the counters of a URR against its triggers, and sends a Session Report
+// Request if they are reached.
+func (pConn *PFCPConn) handleUsageEvent(event UsageEvent) {
+	session, ok := pConn.store.GetSession(event.FseID)
+	if !ok {
+		log.Warn("No session found for fseid : ", event.FseID)
+		return
+	}
+
+	urr := session.findURR(event.UrrID)
+	if urr == nil {
+		log.Warnw("No URR found for usage event", zap.Uint64("F-SEID", event.FseID),
+			zap.Uint32("URR ID", event.UrrID))
+
+		return
+	}
+
+	reports := pConn.usage.update(event.FseID, *urr, event.Counters, time.Now())
+	if len(reports) == 0 {
+		return
+	}
+
+	pConn.sendUsageReports(session, reports)
+}
+
+// handleUsageTimer sends the usage reports of session seid triggered by its timer.
+func (pConn *PFCPConn) handleUsageTimer(seid uint64, reports []*usageReport) {
+	session, ok := pConn.store.GetSession(seid)
+	if !ok {
+		log.Warn("No session found for fseid : ", seid)
+		return
+	}
+
+	pConn.sendUsageReports(session, reports)
+}
+
+// sendUsageReports sends reports of session in a Session Report Request.
+func (pConn *PFCPConn) sendUsageReports(session PFCPSession, reports []*usageReport) {
+	srreq := message.NewSessionReportRequest(0, /* MO?? <-- what's this */
+		0,                            /* FO <-- what's this? */
+		session.remoteSEID,           /* seid */
+		pConn.getSeqNum(),            /* seq # */
+		0,                            /* priority */
+		ie.NewReportType(0, 0, 1, 0), /*upir, erir, usar, dldr int*/
+	)
+
+	for _, report := range reports {
+		srreq.UsageReport = append(srreq.UsageReport, ie.NewUsageReportWithinSessionReportRequest(report.IEs()...))
+
+		log.Debugw(
+			"Sending Usage Report",
+			zap.Uint64("F-SEID", session.localSEID),
+			zap.Uint32("URR ID", report.urrID),
+			zap.Uint32("UR-SEQN", report.seqN),
+			zap.Uint16("trigger", report.trigger),
+		)
+	}
+
+	go pConn.sendSessionReportRequest(srreq)
+}
+
+// sendSessionReportRequest sends srreq, retransmitting it until it is answered.
+func (pConn *PFCPConn) sendSessionReportRequest(srreq *message.SessionReportRequest) {
+	if _, timeout := pConn.sendPFCPRequestMessage(newRequest(srreq)); timeout {
+		log.Warnw("Session Report Request was not answered", zap.Uint64("SEID", srreq.SEID()),
+			zap.Uint32("seq", srreq.Sequence()))
+	}
+}
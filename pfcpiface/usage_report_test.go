@@ -0,0 +1,390 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wmnsk/go-pfcp/ie"
+	"github.com/wmnsk/go-pfcp/message"
+)
+
+func newTestUrr(threshold, quota uint64) Urr {
+	return Urr{
+		UrrID:        1,
+		Trigger:      ReportTrigger{Flags: usageReportTriggerVOLTH | usageReportTriggerVOLQU},
+		VolThreshold: VolumeData{Flags: volumeFlagTOVOL, TotalVol: threshold},
+		VolQuota:     VolumeData{Flags: volumeFlagTOVOL, TotalVol: quota},
+	}
+}
+
+func downlinkBytes(n uint64) UsageCounters {
+	return UsageCounters{DownlinkPackets: n / 100, DownlinkBytes: n}
+}
+
+// addTestURR adds a URR with ID 1 and a total volume threshold to the session seid.
+func addTestURR(t *testing.T, pConn *PFCPConn, seid uint64, threshold uint64) {
+	smreq := message.NewSessionModificationRequest(0, 0, seid, 2, 0,
+		ie.NewCreateURR(
+			ie.NewURRID(1),
+			ie.NewMeasurementMethod(0, 1, 0),
+			ie.NewReportingTriggers(usageReportTriggerVOLTH),
+			ie.NewVolumeThreshold(volumeFlagTOVOL, threshold, 0, 0),
+		),
+	)
+	reply, err := pConn.handleSessionModificationRequest(smreq)
+	require.NoError(t, err)
+	requireCause(t, ie.CauseRequestAccepted, reply.(*message.SessionModificationResponse).Cause)
+}
+
+// requireUsageReport checks the URR ID, UR-SEQN, trigger and downlink volume of a usage report.
+func requireUsageReport(t *testing.T, ur *ie.IE, seqN uint32, trigger []byte, volume uint64) {
+	urrID, err := ur.URRID()
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), urrID)
+
+	gotSeqN, err := ur.URSEQN()
+	require.NoError(t, err)
+	require.Equal(t, seqN, gotSeqN)
+
+	gotTrigger, err := ur.UsageReportTrigger()
+	require.NoError(t, err)
+	require.Equal(t, trigger, gotTrigger)
+
+	measurement, err := ur.VolumeMeasurement()
+	require.NoError(t, err)
+	require.Equal(t, volume, measurement.TotalVolume)
+	require.Equal(t, volume, measurement.DownlinkVolume)
+}
+
+// requireOneReport returns the only report of reports.
+func requireOneReport(t *testing.T, reports []*usageReport) *usageReport {
+	require.Len(t, reports, 1)
+	return reports[0]
+}
+
+func TestUsageTracker(t *testing.T) {
+	tracker := newUsageTracker(nil)
+	urr := newTestUrr(1000, 2500)
+	start := time.Unix(1000, 0)
+
+	tracker.sync(1, []Urr{urr}, start)
+
+	require.Empty(t, tracker.update(1, urr, downlinkBytes(900), start.Add(time.Second)))
+
+	r := requireOneReport(t, tracker.update(1, urr, downlinkBytes(1200), start.Add(2*time.Second)))
+	require.Equal(t, uint32(0), r.seqN)
+	require.Equal(t, usageReportTriggerVOLTH, r.trigger)
+	require.Equal(t, downlinkBytes(1200), r.usage)
+	require.Equal(t, start, r.startTime)
+	require.Equal(t, start.Add(2*time.Second), r.endTime)
+
+	// Measurement restarts after a report
+	require.Empty(t, tracker.update(1, urr, downlinkBytes(2000), start.Add(3*time.Second)))
+
+	r = requireOneReport(t, tracker.update(1, urr, downlinkBytes(2600), start.Add(4*time.Second)))
+	require.Equal(t, uint32(1), r.seqN)
+	require.Equal(t, usageReportTriggerVOLTH|usageReportTriggerVOLQU, r.trigger)
+	require.Equal(t, downlinkBytes(2600).sub(downlinkBytes(1200)), r.usage)
+	require.Equal(t, start.Add(2*time.Second), r.startTime)
+
+	// An exhausted quota is reported once, until a new one is provisioned
+	r = requireOneReport(t, tracker.update(1, urr, downlinkBytes(3700), start.Add(5*time.Second)))
+	require.Equal(t, usageReportTriggerVOLTH, r.trigger)
+
+	urr.VolQuota.TotalVol = 500
+	require.Empty(t, tracker.update(1, urr, downlinkBytes(3800), start.Add(6*time.Second)))
+
+	r = requireOneReport(t, tracker.update(1, urr, downlinkBytes(4300), start.Add(7*time.Second)))
+	require.Equal(t, usageReportTriggerVOLQU, r.trigger)
+	require.Equal(t, uint32(3), r.seqN)
+
+	// Removed URRs are no longer measured
+	tracker.sync(1, nil, start)
+	require.Empty(t, tracker.sessions)
+}
+
+func TestUsageTracker_TimeTriggers(t *testing.T) {
+	start := time.Unix(1000, 0)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	t.Run("periodic reports", func(t *testing.T) {
+		tracker := newUsageTracker(nil)
+		urr := Urr{
+			UrrID:             1,
+			Trigger:           ReportTrigger{Flags: usageReportTriggerPERIO},
+			MeasurementPeriod: 10 * time.Second,
+		}
+
+		tracker.sync(1, []Urr{urr}, start)
+		require.Empty(t, tracker.expire(1, at(9)))
+
+		r := requireOneReport(t, tracker.expire(1, at(10)))
+		require.Equal(t, usageReportTriggerPERIO, r.trigger)
+		require.Equal(t, at(10), r.endTime)
+
+		// Periods stay aligned, even if a report is late
+		r = requireOneReport(t, tracker.expire(1, at(25)))
+		require.Equal(t, at(10), r.startTime)
+		require.Empty(t, tracker.expire(1, at(29)))
+		require.NotEmpty(t, tracker.expire(1, at(30)))
+	})
+
+	t.Run("time threshold and quota measure active time", func(t *testing.T) {
+		tracker := newUsageTracker(nil)
+		urr := Urr{
+			UrrID:                   1,
+			MeasureMethod:           0x1, // DURAT
+			Trigger:                 ReportTrigger{Flags: usageReportTriggerTIMTH | usageReportTriggerTIMQU},
+			TimeThreshold:           10 * time.Second,
+			TimeQuota:               13 * time.Second,
+			InactivityDetectionTime: 5 * time.Second,
+		}
+
+		tracker.sync(1, []Urr{urr}, start)
+
+		// Time is not active until traffic is seen
+		require.Empty(t, tracker.expire(1, at(20)))
+		require.Empty(t, tracker.update(1, urr, downlinkBytes(100), at(20)))
+
+		// Active for 5 seconds after the last traffic, then inactive
+		require.Empty(t, tracker.update(1, urr, downlinkBytes(200), at(23)))
+		require.Empty(t, tracker.expire(1, at(40)))
+
+		require.Empty(t, tracker.update(1, urr, downlinkBytes(300), at(42)))
+
+		r := requireOneReport(t, tracker.expire(1, at(44)))
+		require.Equal(t, usageReportTriggerTIMTH, r.trigger)
+		require.Equal(t, 10*time.Second, r.duration)
+
+		ies := ie.NewUsageReportWithinSessionReportRequest(r.IEs()...)
+		duration, err := ies.DurationMeasurement()
+		require.NoError(t, err)
+		require.Equal(t, 10*time.Second, duration)
+
+		r = requireOneReport(t, tracker.expire(1, at(47)))
+		require.Equal(t, usageReportTriggerTIMQU, r.trigger)
+		require.Equal(t, 3*time.Second, r.duration)
+
+		// An exhausted quota is reported once
+		require.Empty(t, tracker.update(1, urr, downlinkBytes(400), at(50)))
+		require.Empty(t, tracker.expire(1, at(54)))
+	})
+
+	t.Run("quota holding time reports idle URRs", func(t *testing.T) {
+		tracker := newUsageTracker(nil)
+		urr := Urr{
+			UrrID:            1,
+			Trigger:          ReportTrigger{Flags: usageReportTriggerQUHTI},
+			QuotaHoldingTime: 10 * time.Second,
+		}
+
+		tracker.sync(1, []Urr{urr}, start)
+		require.Empty(t, tracker.update(1, urr, downlinkBytes(100), at(5)))
+		require.Empty(t, tracker.expire(1, at(14)))
+
+		r := requireOneReport(t, tracker.expire(1, at(15)))
+		require.Equal(t, usageReportTriggerQUHTI, r.trigger)
+
+		// Idle URRs are reported once, until traffic resumes
+		require.Empty(t, tracker.expire(1, at(30)))
+		require.Empty(t, tracker.update(1, urr, downlinkBytes(200), at(31)))
+		require.NotEmpty(t, tracker.expire(1, at(41)))
+	})
+
+	t.Run("measurement is split at the monitoring time", func(t *testing.T) {
+		tracker := newUsageTracker(nil)
+		urr := newTestUrr(1000, 0)
+		urr.MonitoringTime = at(10)
+
+		tracker.sync(1, []Urr{urr}, start)
+		require.Empty(t, tracker.update(1, urr, downlinkBytes(400), at(5)))
+		require.Empty(t, tracker.expire(1, at(10)))
+
+		reports := tracker.update(1, urr, downlinkBytes(1500), at(20))
+		require.Len(t, reports, 2)
+
+		before, after := reports[0], reports[1]
+		require.Equal(t, uint32(0), before.seqN)
+		require.Equal(t, downlinkBytes(400), before.usage)
+		require.Equal(t, start, before.startTime)
+		require.Equal(t, at(10), before.endTime)
+		require.Equal(t, uint8(usageInformationBEF), before.usageInformation)
+
+		require.Equal(t, uint32(1), after.seqN)
+		require.Equal(t, usageReportTriggerVOLTH, after.trigger)
+		require.Equal(t, downlinkBytes(1500).sub(downlinkBytes(400)), after.usage)
+		require.Equal(t, at(10), after.startTime)
+		require.Equal(t, uint8(usageInformationAFT), after.usageInformation)
+
+		info, err := ie.NewUsageReportWithinSessionReportRequest(after.IEs()...).UsageInformation()
+		require.NoError(t, err)
+		require.Equal(t, uint8(usageInformationAFT), info)
+	})
+}
+
+func TestUsageTracker_Deadline(t *testing.T) {
+	start := time.Unix(1000, 0)
+	urr := Urr{
+		UrrID:            1,
+		Trigger:          ReportTrigger{Flags: usageReportTriggerPERIO | usageReportTriggerTIMTH | usageReportTriggerQUHTI},
+		TimeThreshold:    20 * time.Second,
+		QuotaHoldingTime: 15 * time.Second,
+	}
+
+	m := newURRMeasurement(urr, start)
+	require.Equal(t, start.Add(15*time.Second), m.deadline(start))
+
+	urr.MeasurementPeriod = 5 * time.Second
+	m.provision(urr, start)
+	require.Equal(t, start.Add(5*time.Second), m.deadline(start))
+
+	urr.Trigger.Flags = usageReportTriggerVOLTH
+	m.provision(urr, start)
+	require.True(t, m.deadline(start).IsZero())
+}
+
+func TestPFCPConn_UsageReport(t *testing.T) {
+	node := &PFCPNode{sessionOwners: newSessionOwners(), metrics: &countingInstrumentPFCP{}}
+	pConn, smf := newTestPeer(t, node)
+	pConn.upf.respTimeout = 50 * time.Millisecond
+	pConn.upf.maxReqRetries = 2
+
+	seid := establishTestSession(t, pConn)
+	addTestURR(t, pConn, seid, 1000)
+
+	node.handleUsageEvent(UsageEvent{FseID: seid, UrrID: 1, Counters: downlinkBytes(500)})
+	require.Nil(t, readSessionReport(t, smf), "threshold is not reached")
+
+	node.handleUsageEvent(UsageEvent{FseID: seid, UrrID: 1, Counters: downlinkBytes(1500)})
+
+	srreq := readSessionReport(t, smf)
+	require.NotNil(t, srreq)
+	require.Equal(t, testRemoteSEID, srreq.SEID())
+	require.Len(t, srreq.UsageReport, 1)
+	requireUsageReport(t, srreq.UsageReport[0], 0, []byte{0x02, 0x00, 0x00}, 1500)
+
+	// Unanswered reports are retransmitted, until the SMF replies
+	retransmitted := readSessionReport(t, smf)
+	require.NotNil(t, retransmitted)
+	require.Equal(t, srreq.Sequence(), retransmitted.Sequence())
+
+	srres := message.NewSessionReportResponse(0, 0, seid, srreq.Sequence(), 0, ie.NewCause(ie.CauseRequestAccepted))
+	buf := make([]byte, srres.MarshalLen())
+	require.NoError(t, srres.MarshalTo(buf))
+	pConn.HandlePFCPMsg(buf)
+
+	require.Nil(t, readSessionReport(t, smf))
+
+	// Time based triggers are reported by the timer of the session
+	session, ok := pConn.store.GetSession(seid)
+	require.True(t, ok)
+
+	urr := *session.findURR(1)
+	urr.Trigger.Flags |= usageReportTriggerPERIO
+	urr.MeasurementPeriod = 50 * time.Millisecond
+	pConn.usage.sync(seid, []Urr{urr}, time.Now())
+
+	srreq = readSessionReport(t, smf)
+	require.NotNil(t, srreq)
+	require.Len(t, srreq.UsageReport, 1)
+	requireUsageReport(t, srreq.UsageReport[0], 1, []byte{0x01, 0x00, 0x00}, 0)
+
+	pConn.usage.forget(seid)
+
+	// Counters of unknown sessions are dropped
+	node.handleUsageEvent(UsageEvent{FseID: 0xdead, UrrID: 1, Counters: downlinkBytes(1500)})
+	require.Equal(t, 1, node.metrics.(*countingInstrumentPFCP).unknownSEIDReports)
+}
+
+func TestSessionModification_QueryURR(t *testing.T) {
+	dp := NewRecordingDatapath()
+	pConn := newTestPFCPConn(t, dp)
+	seid := establishTestSession(t, pConn)
+	addTestURR(t, pConn, seid, 1000)
+
+	modify := func(t *testing.T, ies ...*ie.IE) *message.SessionModificationResponse {
+		reply, err := pConn.handleSessionModificationRequest(message.NewSessionModificationRequest(0, 0, seid, 3, 0, ies...))
+		require.NoError(t, err)
+
+		smres, ok := reply.(*message.SessionModificationResponse)
+		require.True(t, ok)
+		requireCause(t, ie.CauseRequestAccepted, smres.Cause)
+
+		return smres
+	}
+
+	dp.SetUsage(seid, 1, downlinkBytes(700))
+
+	smres := modify(t, ie.NewQueryURR(ie.NewURRID(1)), ie.NewQueryURRReference(42))
+	require.Len(t, smres.UsageReport, 1)
+	requireUsageReport(t, smres.UsageReport[0], 0, []byte{0x80, 0x00, 0x00}, 700)
+
+	ref, err := smres.UsageReport[0].QueryURRReference()
+	require.NoError(t, err)
+	require.Equal(t, uint32(42), ref)
+
+	dp.SetUsage(seid, 1, downlinkBytes(1000))
+
+	smres = modify(t, ie.NewPFCPSMReqFlags(0x04)) // QAURR
+	require.Len(t, smres.UsageReport, 1)
+	requireUsageReport(t, smres.UsageReport[0], 1, []byte{0x80, 0x00, 0x00}, 300)
+
+	dp.SetUsage(seid, 1, downlinkBytes(1200))
+
+	smres = modify(t, ie.NewRemoveURR(ie.NewURRID(1)))
+	require.Len(t, smres.UsageReport, 1)
+	requireUsageReport(t, smres.UsageReport[0], 2, []byte{0x00, 0x08, 0x00}, 200)
+	require.Empty(t, dp.Rules().Urrs)
+
+	reply, err := pConn.handleSessionModificationRequest(
+		message.NewSessionModificationRequest(0, 0, seid, 4, 0, ie.NewQueryURR(ie.NewURRID(1))))
+	require.Error(t, err, "unknown URRs can't be queried")
+	requireCause(t, ie.CauseRequestRejected, reply.(*message.SessionModificationResponse).Cause)
+}
+
+func TestSessionDeletion_UsageReport(t *testing.T) {
+	deleteSession := func(t *testing.T, pConn *PFCPConn, seid uint64) *message.SessionDeletionResponse {
+		reply, err := pConn.handleSessionDeletionRequest(message.NewSessionDeletionRequest(0, 0, seid, 3, 0))
+		require.NoError(t, err)
+
+		sdres, ok := reply.(*message.SessionDeletionResponse)
+		require.True(t, ok)
+		requireCause(t, ie.CauseRequestAccepted, sdres.Cause)
+
+		return sdres
+	}
+
+	t.Run("final counters are read from the datapath", func(t *testing.T) {
+		dp := NewRecordingDatapath()
+		pConn := newTestPFCPConn(t, dp)
+		seid := establishTestSession(t, pConn)
+		addTestURR(t, pConn, seid, 1000)
+
+		dp.SetUsage(seid, 1, downlinkBytes(900))
+
+		sdres := deleteSession(t, pConn, seid)
+		require.Len(t, sdres.UsageReport, 1)
+		requireUsageReport(t, sdres.UsageReport[0], 0, []byte{0x00, 0x08, 0x00}, 900)
+		require.Empty(t, pConn.usage.sessions)
+	})
+
+	t.Run("last reported counters are used if the datapath can't be read", func(t *testing.T) {
+		// Hides ReadUsage of RecordingDatapath
+		dp := struct{ Datapath }{NewRecordingDatapath()}
+		pConn := newTestPFCPConn(t, dp)
+		seid := establishTestSession(t, pConn)
+		addTestURR(t, pConn, seid, 1000)
+
+		pConn.handleUsageEvent(UsageEvent{FseID: seid, UrrID: 1, Counters: downlinkBytes(600)})
+
+		sdres := deleteSession(t, pConn, seid)
+		require.Len(t, sdres.UsageReport, 1)
+		requireUsageReport(t, sdres.UsageReport[0], 0, []byte{0x00, 0x08, 0x00}, 600)
+	})
+}
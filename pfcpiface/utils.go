@@ -44,10 +44,22 @@ func has2ndBit(f uint8) bool {
 	return (f&0x02)>>1 == 1
 }
 
+func has4thBit(f uint8) bool {
+	return (f&0x08)>>3 == 1
+}
+
 func has5thBit(f uint8) bool {
 	return (f&0x010)>>4 == 1
 }
 
+func has6thBit(f uint8) bool {
+	return (f&0x20)>>5 == 1
+}
+
+func has7thBit(f uint8) bool {
+	return (f&0x40)>>6 == 1
+}
+
 func inc(ip net.IP) {
 	for j := len(ip) - 1; j >= 0; j-- {
 		ip[j]++
@@ -57,12 +69,19 @@ func inc(ip net.IP) {
 	}
 }
 
+// ip2int returns the IPv4 address ip as an integer, 0 if ip is not an IPv4 address.
 func ip2int(ip net.IP) uint32 {
-	if len(ip) == 16 {
-		return binary.BigEndian.Uint32(ip[12:16])
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return 0
 	}
 
-	return binary.BigEndian.Uint32(ip)
+	return binary.BigEndian.Uint32(ip4)
+}
+
+// isIPv6 checks if ip is an IPv6 address, IPv4-mapped IPv6 addresses excluded.
+func isIPv6(ip net.IP) bool {
+	return len(ip) == net.IPv6len && ip.To4() == nil
 }
 
 func ipMask2int(ip net.IPMask) uint32 {
@@ -135,6 +154,33 @@ func GetUnicastAddressFromInterface(interfaceName string) (net.IP, error) {
 	return ip, nil
 }
 
+// GetUnicastIPv6AddressFromInterface returns the first global unicast IPv6 address of an
+// interface, nil if it has none.
+func GetUnicastIPv6AddressFromInterface(interfaceName string) (net.IP, error) {
+	iface, err := net.InterfaceByName(interfaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, address := range addresses {
+		ip, _, err := net.ParseCIDR(address.String())
+		if err != nil {
+			return nil, err
+		}
+
+		if isIPv6(ip) && ip.IsGlobalUnicast() {
+			return ip, nil
+		}
+	}
+
+	return nil, nil
+}
+
 func GetSliceTCMeterIndex(sliceID uint8, TC uint8) (int64, error) {
 	if sliceID >= (1 << BitwidthMfSliceId) {
 		return 0, ErrInvalidArgumentWithReason("SliceID", sliceID, "Slice ID higher than max supported slice ID")
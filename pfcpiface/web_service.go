@@ -5,9 +5,14 @@ package pfcpiface
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"math"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -79,6 +84,90 @@ func (c *ConfigHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// DrainRequest ... Request to drain the UPF.
+type DrainRequest struct {
+	GracefulReleasePeriod string `json:"gracefulReleasePeriod"`
+}
+
+// DrainStatus ... Drain state of the UPF and number of sessions left.
+type DrainStatus struct {
+	Draining bool `json:"draining"`
+	Sessions int  `json:"sessions"`
+}
+
+type DrainHandler struct {
+	node *PFCPNode
+}
+
+func setupDrainHandler(mux *http.ServeMux, node *PFCPNode) {
+	drainHandler := DrainHandler{node: node}
+	mux.Handle("/v1/drain", &drainHandler)
+}
+
+// ServeHTTP drains the UPF on POST, resumes it on DELETE, and returns the drain status.
+func (d *DrainHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Info("handle http request for /v1/drain")
+
+	switch r.Method {
+	case http.MethodGet:
+		// status only
+	case http.MethodPost:
+		var req DrainRequest
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			log.Error("http req read body failed.")
+			sendHTTPResp(http.StatusBadRequest, w)
+
+			return
+		}
+
+		if len(body) != 0 {
+			if err := json.Unmarshal(body, &req); err != nil {
+				log.Error("Json unmarshal failed for http request")
+				sendHTTPResp(http.StatusBadRequest, w)
+
+				return
+			}
+		}
+
+		var period time.Duration
+
+		if req.GracefulReleasePeriod != "" {
+			period, err = time.ParseDuration(req.GracefulReleasePeriod)
+			if err != nil || period < 0 {
+				log.Error("Invalid graceful release period: ", req.GracefulReleasePeriod)
+				sendHTTPResp(http.StatusBadRequest, w)
+
+				return
+			}
+		}
+
+		d.node.Drain(period)
+	case http.MethodDelete:
+		d.node.Resume()
+	default:
+		log.Info(w, " Sorry, only GET, POST and DELETE methods are supported.")
+		sendHTTPResp(http.StatusMethodNotAllowed, w)
+
+		return
+	}
+
+	var status DrainStatus
+	status.Draining, status.Sessions = d.node.drainStatus()
+
+	jsonResp, err := json.Marshal(status)
+	if err != nil {
+		log.Error("Error happened in JSON marshal. Err: ", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, err = w.Write(jsonResp); err != nil {
+		log.Error("http response write failed : ", err)
+	}
+}
+
 func sendHTTPResp(status int, w http.ResponseWriter) {
 	w.WriteHeader(status)
 	w.Header().Set("Content-Type", "application/json")
@@ -158,3 +247,268 @@ func handleSliceConfig(nwSlice *NetworkSlice, upf *Upf) {
 		log.Error("adding slice info to datapath failed : ", err)
 	}
 }
+
+// UEPoolStatus ... Usage of a UE IP pool.
+type UEPoolStatus struct {
+	Name string       `json:"name"`
+	Dnn  string       `json:"dnn,omitempty"`
+	IPv4 *UEPoolUsage `json:"ipv4,omitempty"`
+	IPv6 *UEPoolUsage `json:"ipv6,omitempty"`
+}
+
+// UEPoolUsage ... Number of addresses (IPv6 prefixes) of a family in a UE IP pool.
+type UEPoolUsage struct {
+	Used      uint64 `json:"used"`
+	Free      uint64 `json:"free"`
+	Reserved  uint64 `json:"reserved"`
+	Exhausted uint64 `json:"exhausted"`
+}
+
+// UEAllocation ... UE addresses allocated to a session.
+type UEAllocation struct {
+	SEID uint64 `json:"seid"`
+	IPv4 string `json:"ipv4,omitempty"`
+	IPv6 string `json:"ipv6,omitempty"`
+}
+
+// UEReservation ... UE addresses reserved for a subscriber.
+type UEReservation struct {
+	Key  string `json:"key"`
+	IPv4 string `json:"ipv4,omitempty"`
+	IPv6 string `json:"ipv6,omitempty"`
+}
+
+type UEPoolHandler struct {
+	upf  *Upf
+	node *PFCPNode
+}
+
+func setupUEPoolHandler(mux *http.ServeMux, upf *Upf, node *PFCPNode) {
+	uePoolHandler := UEPoolHandler{upf: upf, node: node}
+	mux.Handle("/v1/ue-pools", &uePoolHandler)
+	mux.Handle("/v1/ue-pools/", &uePoolHandler)
+}
+
+// ServeHTTP serves the UE IP pools:
+//
+//	GET    /v1/ue-pools                            usage of all pools
+//	GET    /v1/ue-pools/{pool}                     usage of a pool
+//	GET    /v1/ue-pools/{pool}/allocations         addresses allocated to sessions
+//	DELETE /v1/ue-pools/{pool}/allocations/{seid}  release the addresses leaked by a session
+//	GET    /v1/ue-pools/{pool}/reservations        addresses reserved for subscribers
+//	PUT    /v1/ue-pools/{pool}/reservations/{key}  reserve addresses for a subscriber
+//	DELETE /v1/ue-pools/{pool}/reservations/{key}  delete a reservation
+//
+// Reservations made here are not persisted: those of the configuration are restored on restart.
+func (h *UEPoolHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Info("handle http request for ", r.URL.Path)
+
+	ippools := h.upf.ippools
+	if ippools == nil {
+		sendHTTPError(http.StatusNotFound, ErrInvalidOperation("UE IP allocation is disabled"), w)
+		return
+	}
+
+	var path []string
+	if p := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/ue-pools"), "/"); p != "" {
+		path = strings.Split(p, "/")
+	}
+
+	if len(path) == 0 {
+		if r.Method != http.MethodGet {
+			sendHTTPError(http.StatusMethodNotAllowed, ErrInvalidOperation(r.Method), w)
+			return
+		}
+
+		sendJSONResp(uePoolStatuses(ippools.stats()), w)
+
+		return
+	}
+
+	pool, err := ippools.get(path[0])
+	if err != nil {
+		sendHTTPError(http.StatusNotFound, err, w)
+		return
+	}
+
+	switch {
+	case len(path) == 1 && r.Method == http.MethodGet:
+		for _, status := range uePoolStatuses(ippools.stats()) {
+			if status.Name == pool.name {
+				sendJSONResp(status, w)
+			}
+		}
+	case len(path) == 2 && path[1] == "allocations" && r.Method == http.MethodGet:
+		sendJSONResp(uePoolAllocations(pool), w)
+	case len(path) == 3 && path[1] == "allocations" && r.Method == http.MethodDelete:
+		h.releaseAllocation(pool, path[2], w)
+	case len(path) == 2 && path[1] == "reservations" && r.Method == http.MethodGet:
+		sendJSONResp(uePoolReservations(pool), w)
+	case len(path) == 3 && path[1] == "reservations" && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		h.addReservation(pool, path[2], r, w)
+	case len(path) == 3 && path[1] == "reservations" && r.Method == http.MethodDelete:
+		if err := pool.unreserve(path[2]); err != nil {
+			sendHTTPError(http.StatusNotFound, err, w)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	case len(path) == 1 || (len(path) <= 3 && (path[1] == "allocations" || path[1] == "reservations")):
+		sendHTTPError(http.StatusMethodNotAllowed, ErrInvalidOperation(r.Method), w)
+	default:
+		sendHTTPError(http.StatusNotFound, ErrNotFound(r.URL.Path), w)
+	}
+}
+
+// releaseAllocation releases the addresses allocated to a session that no longer exists.
+func (h *UEPoolHandler) releaseAllocation(pool *uePool, seidParam string, w http.ResponseWriter) {
+	seid, err := strconv.ParseUint(seidParam, 10, 64)
+	if err != nil {
+		sendHTTPError(http.StatusBadRequest, ErrInvalidArgument("seid", seidParam), w)
+		return
+	}
+
+	if h.node != nil {
+		if _, ok := h.node.sessions.GetSession(seid); ok {
+			sendHTTPError(http.StatusConflict,
+				ErrInvalidArgumentWithReason("seid", seid, "session exists, its addresses are not leaked"), w)
+
+			return
+		}
+	}
+
+	if err := h.upf.ippools.forceRelease(pool.name, seid); err != nil {
+		sendHTTPError(http.StatusNotFound, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addReservation reserves the addresses in the body of r for the subscriber key.
+func (h *UEPoolHandler) addReservation(pool *uePool, key string, r *http.Request, w http.ResponseWriter) {
+	var req UEReservation
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil || json.Unmarshal(body, &req) != nil {
+		log.Error("Json unmarshal failed for http request")
+		sendHTTPError(http.StatusBadRequest, ErrInvalidArgument("body", string(body)), w)
+
+		return
+	}
+
+	err = h.upf.ippools.addReservation(UEIPReservationInfo{Key: key, Pool: pool.name, IPv4: req.IPv4, IPv6: req.IPv6})
+	if err != nil {
+		sendHTTPError(http.StatusConflict, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// uePoolStatuses returns the usage of the pools in stats, sorted by name.
+func uePoolStatuses(stats []uePoolStats) []UEPoolStatus {
+	statuses := make([]UEPoolStatus, 0, len(stats))
+
+	for _, s := range stats {
+		if len(statuses) == 0 || statuses[len(statuses)-1].Name != s.pool {
+			statuses = append(statuses, UEPoolStatus{Name: s.pool, Dnn: s.dnn})
+		}
+
+		usage := &UEPoolUsage{Used: s.used, Free: s.free, Reserved: s.reserved, Exhausted: s.exhausted}
+
+		if s.family == familyIPv4 {
+			statuses[len(statuses)-1].IPv4 = usage
+		} else {
+			statuses[len(statuses)-1].IPv6 = usage
+		}
+	}
+
+	return statuses
+}
+
+// uePoolAllocations returns the addresses allocated to sessions in pool, sorted by SEID.
+func uePoolAllocations(pool *uePool) []UEAllocation {
+	allocs := make(map[uint64]*UEAllocation)
+
+	allocation := func(seid uint64) *UEAllocation {
+		if allocs[seid] == nil {
+			allocs[seid] = &UEAllocation{SEID: seid}
+		}
+
+		return allocs[seid]
+	}
+
+	if pool.ip4 != nil {
+		for seid, ip := range pool.ip4.allocations() {
+			allocation(seid).IPv4 = ip.String()
+		}
+	}
+
+	if pool.ip6 != nil {
+		for seid, prefix := range pool.ip6.allocations() {
+			allocation(seid).IPv6 = fmt.Sprintf("%v/%v", prefix, uePrefixLen)
+		}
+	}
+
+	sorted := make([]UEAllocation, 0, len(allocs))
+	for _, alloc := range allocs {
+		sorted = append(sorted, *alloc)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].SEID < sorted[j].SEID })
+
+	return sorted
+}
+
+// uePoolReservations returns the reservations of pool, sorted by key.
+func uePoolReservations(pool *uePool) []UEReservation {
+	reservations := make([]UEReservation, 0)
+
+	for key, r := range pool.allReservations() {
+		reservation := UEReservation{Key: key}
+
+		if r.ip4 != nil {
+			reservation.IPv4 = r.ip4.String()
+		}
+
+		if r.ip6 != nil {
+			reservation.IPv6 = r.ip6.String()
+		}
+
+		reservations = append(reservations, reservation)
+	}
+
+	sort.Slice(reservations, func(i, j int) bool { return reservations[i].Key < reservations[j].Key })
+
+	return reservations
+}
+
+func sendJSONResp(v interface{}, w http.ResponseWriter) {
+	jsonResp, err := json.Marshal(v)
+	if err != nil {
+		log.Error("Error happened in JSON marshal. Err: ", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, err = w.Write(jsonResp); err != nil {
+		log.Error("http response write failed : ", err)
+	}
+}
+
+func sendHTTPError(status int, err error, w http.ResponseWriter) {
+	log.Error(err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	jsonResp, jsonErr := json.Marshal(map[string]string{"message": err.Error()})
+	if jsonErr != nil {
+		log.Error("Error happened in JSON marshal. Err: ", jsonErr)
+	}
+
+	if _, err = w.Write(jsonResp); err != nil {
+		log.Error("http response write failed : ", err)
+	}
+}
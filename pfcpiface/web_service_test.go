@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2022-present Open Networking Foundation
+
+package pfcpiface
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUEPoolHandler(t *testing.T) {
+	upf := newTestUpf(NewRecordingDatapath())
+
+	var err error
+
+	upf.ippools, err = newUEIPPools(CPIfaceInfo{
+		UEIPPool: "10.250.0.0/29",
+		UEIPPools: []UEIPPoolInfo{
+			{Name: "ims", Dnn: "ims", IPv4Subnet: "10.251.0.0/29", IPv6Subnet: "2001:db8:100::/62"},
+		},
+		UEIPReservations: []UEIPReservationInfo{{Key: "imsi-001010000000001", Pool: "ims", IPv4: "10.251.0.5"}},
+	})
+	require.NoError(t, err)
+
+	node := &PFCPNode{sessions: NewInMemoryStore()}
+
+	mux := http.NewServeMux()
+	setupUEPoolHandler(mux, upf, node)
+
+	serve := func(t *testing.T, method, path, body string, wantStatus int, resp interface{}) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+		require.Equal(t, wantStatus, w.Code, w.Body.String())
+
+		if resp != nil {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+		}
+	}
+
+	ims, err := upf.ippools.get("ims")
+	require.NoError(t, err)
+
+	_, err = ims.allocIPv4(1, nil)
+	require.NoError(t, err)
+
+	_, err = ims.allocIPv6(1, nil)
+	require.NoError(t, err)
+
+	_, err = ims.allocIPv4(2, []string{"imsi-001010000000001"})
+	require.NoError(t, err)
+
+	t.Run("pool usage", func(t *testing.T) {
+		var statuses []UEPoolStatus
+
+		serve(t, http.MethodGet, "/v1/ue-pools", "", http.StatusOK, &statuses)
+		require.Equal(t, []UEPoolStatus{
+			{Name: defaultPoolName, IPv4: &UEPoolUsage{Free: 6}},
+			{
+				Name: "ims",
+				Dnn:  "ims",
+				IPv4: &UEPoolUsage{Used: 2, Free: 4},
+				IPv6: &UEPoolUsage{Used: 1, Free: 3},
+			},
+		}, statuses)
+
+		var status UEPoolStatus
+
+		serve(t, http.MethodGet, "/v1/ue-pools/ims", "", http.StatusOK, &status)
+		require.Equal(t, statuses[1], status)
+
+		serve(t, http.MethodGet, "/v1/ue-pools/unknown", "", http.StatusNotFound, nil)
+		serve(t, http.MethodPost, "/v1/ue-pools", "", http.StatusMethodNotAllowed, nil)
+		serve(t, http.MethodGet, "/v1/ue-pools/ims/unknown", "", http.StatusNotFound, nil)
+	})
+
+	t.Run("allocations", func(t *testing.T) {
+		var allocs []UEAllocation
+
+		serve(t, http.MethodGet, "/v1/ue-pools/ims/allocations", "", http.StatusOK, &allocs)
+		require.Equal(t, []UEAllocation{
+			{SEID: 1, IPv4: "10.251.0.1", IPv6: "2001:db8:100::/64"},
+			{SEID: 2, IPv4: "10.251.0.5"},
+		}, allocs)
+
+		require.NoError(t, node.sessions.PutSession(PFCPSession{localSEID: 2}))
+		serve(t, http.MethodDelete, "/v1/ue-pools/ims/allocations/2", "", http.StatusConflict, nil)
+
+		serve(t, http.MethodDelete, "/v1/ue-pools/ims/allocations/1", "", http.StatusNoContent, nil)
+		serve(t, http.MethodDelete, "/v1/ue-pools/ims/allocations/1", "", http.StatusNotFound, nil)
+		serve(t, http.MethodDelete, "/v1/ue-pools/ims/allocations/foo", "", http.StatusBadRequest, nil)
+
+		var remaining []UEAllocation
+
+		serve(t, http.MethodGet, "/v1/ue-pools/ims/allocations", "", http.StatusOK, &remaining)
+		require.Equal(t, []UEAllocation{{SEID: 2, IPv4: "10.251.0.5"}}, remaining)
+	})
+
+	t.Run("reservations", func(t *testing.T) {
+		serve(t, http.MethodPut, "/v1/ue-pools/ims/reservations/msisdn-15551234567",
+			`{"ipv4": "10.251.0.3", "ipv6": "2001:db8:100:3::"}`, http.StatusCreated, nil)
+		serve(t, http.MethodPut, "/v1/ue-pools/ims/reservations/msisdn-15551234568",
+			`{"ipv4": "10.251.0.3"}`, http.StatusConflict, nil)
+		serve(t, http.MethodPut, "/v1/ue-pools/ims/reservations/msisdn-15551234568",
+			`{"ipv4": `, http.StatusBadRequest, nil)
+
+		var reservations []UEReservation
+
+		serve(t, http.MethodGet, "/v1/ue-pools/ims/reservations", "", http.StatusOK, &reservations)
+		require.Equal(t, []UEReservation{
+			{Key: "imsi-001010000000001", IPv4: "10.251.0.5"},
+			{Key: "msisdn-15551234567", IPv4: "10.251.0.3", IPv6: "2001:db8:100:3::"},
+		}, reservations)
+
+		serve(t, http.MethodDelete, "/v1/ue-pools/ims/reservations/msisdn-15551234567", "", http.StatusNoContent, nil)
+		serve(t, http.MethodDelete, "/v1/ue-pools/ims/reservations/msisdn-15551234567", "", http.StatusNotFound, nil)
+		serve(t, http.MethodPatch, "/v1/ue-pools/ims/reservations/msisdn-15551234567", "",
+			http.StatusMethodNotAllowed, nil)
+
+		var remaining []UEReservation
+
+		serve(t, http.MethodGet, "/v1/ue-pools/ims/reservations", "", http.StatusOK, &remaining)
+		require.Equal(t, []UEReservation{{Key: "imsi-001010000000001", IPv4: "10.251.0.5"}}, remaining)
+	})
+
+	t.Run("allocation disabled", func(t *testing.T) {
+		mux := http.NewServeMux()
+		setupUEPoolHandler(mux, newTestUpf(NewRecordingDatapath()), node)
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/ue-pools", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}